@@ -0,0 +1,64 @@
+package aggregation
+
+import (
+	"sort"
+)
+
+// Function Borda returns the Borda count ranking: each ranking awards a
+// choice one point for every choice placed below it, and choices are ordered
+// by total points, breaking ties by index.
+func Borda(rankings [][]int) ([]int, error) {
+	if _, err := precedence(rankings); err != nil {
+		return nil, err
+	}
+	return byPoints(bordaPoints(rankings)), nil
+}
+
+// Function Copeland returns the Copeland ranking: each choice scores one
+// point for every other choice it beats by a majority of the rankings and
+// half a point for every tie, and choices are ordered by score, breaking ties
+// by index.
+func Copeland(rankings [][]int) ([]int, error) {
+	wins, err := precedence(rankings)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]float64, len(wins))
+	for a := range wins {
+		for b := range wins {
+			switch {
+			case a == b:
+			case wins[a][b] > wins[b][a]:
+				points[a]++
+			case wins[a][b] == wins[b][a]:
+				points[a] += 0.5
+			}
+		}
+	}
+	return byPoints(points), nil
+}
+
+// bordaPoints returns the Borda count of each choice.
+func bordaPoints(rankings [][]int) []float64 {
+	n := len(rankings[0])
+	points := make([]float64, n)
+	for _, ranking := range rankings {
+		for i, c := range ranking {
+			points[c] += float64(n - 1 - i)
+		}
+	}
+	return points
+}
+
+// byPoints orders the choices from most to fewest points, breaking ties by
+// index.
+func byPoints(points []float64) []int {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return points[order[i]] > points[order[j]]
+	})
+	return order
+}
@@ -0,0 +1,38 @@
+package aggregation
+
+import (
+	"testing"
+)
+
+func TestBordaAndCopeland(t *testing.T) {
+	// Choice 0 beats every other choice head to head, but 1 is never ranked
+	// below second and wins on Borda points.
+	rankings := [][]int{
+		{0, 1, 2, 3},
+		{0, 1, 2, 3},
+		{1, 2, 3, 0},
+	}
+
+	borda, err := Borda(rankings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if borda[0] != 1 {
+		t.Fatalf("expected 1 to win the Borda count, got %v", borda)
+	}
+
+	copeland, err := Copeland(rankings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copeland[0] != 0 || copeland[3] != 3 {
+		t.Fatalf("expected 0 to win and 3 to lose on Copeland, got %v", copeland)
+	}
+
+	if _, err := Borda(nil); err == nil {
+		t.Fatalf("expected an error for no rankings")
+	}
+	if _, err := Copeland([][]int{{0, 2}}); err == nil {
+		t.Fatalf("expected an error for a non-permutation")
+	}
+}
@@ -0,0 +1,173 @@
+// Package aggregation combines per-user rankings into a single consensus
+// ranking, as needed when one defensible ordering must be published from the
+// predictions of a collaborativepermute engine.
+//
+// Each function takes a list of rankings, each a permutation of the choices
+// 0..n-1 from most to least preferred, and returns a consensus in the same
+// form.
+package aggregation
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+)
+
+// ExactLimit is the largest number of choices for which Kemeny computes the
+// optimal ranking exactly; above it, the result is approximated.
+const ExactLimit = 12
+
+// Function Collect returns the rankings predicted by the learner for users
+// 0..users-1.
+func Collect(l collaborativepermute.Learner, users int) ([][]int, error) {
+	rankings := make([][]int, users)
+	for u := range rankings {
+		ranking, err := l.Rank(u)
+		if err != nil {
+			return nil, err
+		}
+		rankings[u] = ranking
+	}
+	return rankings, nil
+}
+
+// Function Kemeny returns the Kemeny consensus: the ranking with the fewest
+// total pairwise disagreements (Kendall tau distance) with the given
+// rankings. It is the unique aggregation that is neutral, consistent, and
+// satisfies the Condorcet criterion, which makes it easy to defend.
+//
+// Finding it is NP-hard, so for more than ExactLimit choices the result is
+// approximated by starting from the Borda ranking and moving single choices
+// to better positions until no move reduces the disagreement.
+func Kemeny(rankings [][]int) ([]int, error) {
+	wins, err := precedence(rankings)
+	if err != nil {
+		return nil, err
+	}
+	if len(wins) <= ExactLimit {
+		return exactKemeny(wins), nil
+	}
+	return improveKemeny(wins, byPoints(bordaPoints(rankings))), nil
+}
+
+// Function Disagreements returns the total number of pairwise disagreements
+// between the given ranking and each of the rankings.
+func Disagreements(ranking []int, rankings [][]int) (int, error) {
+	wins, err := precedence(append([][]int{ranking}, rankings...))
+	if err != nil {
+		return 0, err
+	}
+	for i, a := range ranking {
+		for _, b := range ranking[i+1:] {
+			wins[a][b]--
+		}
+	}
+	return cost(wins, ranking), nil
+}
+
+// precedence validates the rankings and returns the matrix whose (a, b) entry
+// counts the rankings that place a before b.
+func precedence(rankings [][]int) ([][]int, error) {
+	if len(rankings) == 0 {
+		return nil, fmt.Errorf("must have at least one ranking")
+	}
+	n := len(rankings[0])
+	wins := make([][]int, n)
+	for a := range wins {
+		wins[a] = make([]int, n)
+	}
+	for r, ranking := range rankings {
+		if len(ranking) != n {
+			return nil, fmt.Errorf("ranking %d has %d choices, expected %d",
+				r, len(ranking), n)
+		}
+		seen := make([]bool, n)
+		for _, c := range ranking {
+			if c < 0 || c >= n || seen[c] {
+				return nil, fmt.Errorf("ranking %d is not a permutation", r)
+			}
+			seen[c] = true
+		}
+		for i, a := range ranking {
+			for _, b := range ranking[i+1:] {
+				wins[a][b]++
+			}
+		}
+	}
+	return wins, nil
+}
+
+// cost returns the number of pairwise disagreements of the order with the
+// precedence matrix.
+func cost(wins [][]int, order []int) int {
+	sum := 0
+	for i, a := range order {
+		for _, b := range order[i+1:] {
+			sum += wins[b][a]
+		}
+	}
+	return sum
+}
+
+// exactKemeny finds the optimal order by dynamic programming over the set of
+// choices placed so far, in O(2^n n²) time.
+func exactKemeny(wins [][]int) []int {
+	n := len(wins)
+	best := make([]int, 1<<uint(n))
+	last := make([]int, 1<<uint(n))
+	for mask := 1; mask < len(best); mask++ {
+		best[mask] = -1
+		for c := 0; c < n; c++ {
+			if mask&(1<<uint(c)) == 0 {
+				continue
+			}
+			// Placing c after the rest of mask disagrees with every ranking
+			// that puts c before one of them.
+			rest := mask &^ (1 << uint(c))
+			total := best[rest]
+			for x := 0; x < n; x++ {
+				if rest&(1<<uint(x)) != 0 {
+					total += wins[c][x]
+				}
+			}
+			if best[mask] < 0 || total < best[mask] {
+				best[mask], last[mask] = total, c
+			}
+		}
+	}
+
+	order := make([]int, n)
+	for mask, i := len(best)-1, n-1; i >= 0; i-- {
+		order[i] = last[mask]
+		mask &^= 1 << uint(last[mask])
+	}
+	return order
+}
+
+// improveKemeny repeatedly moves single choices to the position that most
+// reduces the disagreement, until no move helps.
+func improveKemeny(wins [][]int, order []int) []int {
+	order = append([]int(nil), order...)
+	for improved := true; improved; {
+		improved = false
+		for i := range order {
+			c := order[i]
+			rest := append(append([]int(nil), order[:i]...), order[i+1:]...)
+			bestPos, bestCost := i, cost(wins, order)
+			for pos := 0; pos <= len(rest); pos++ {
+				candidate := make([]int, 0, len(order))
+				candidate = append(candidate, rest[:pos]...)
+				candidate = append(candidate, c)
+				candidate = append(candidate, rest[pos:]...)
+				if k := cost(wins, candidate); k < bestCost {
+					bestPos, bestCost = pos, k
+				}
+			}
+			if bestPos != i {
+				order = append(rest[:bestPos:bestPos],
+					append([]int{c}, rest[bestPos:]...)...)
+				improved = true
+			}
+		}
+	}
+	return order
+}
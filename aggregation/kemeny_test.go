@@ -0,0 +1,73 @@
+package aggregation
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"testing"
+)
+
+func TestKemenyExact(t *testing.T) {
+	rankings := [][]int{
+		{0, 1, 2, 3},
+		{0, 1, 2, 3},
+		{1, 0, 3, 2},
+		{2, 0, 1, 3},
+	}
+	consensus, err := Kemeny(rankings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := Disagreements(consensus, rankings)
+	if want, _ := Disagreements([]int{0, 1, 2, 3}, rankings); got != want {
+		t.Fatalf("expected %d disagreements, got %d for %v", want, got, consensus)
+	}
+
+	if _, err := Kemeny([][]int{{0, 1}, {0, 0}}); err == nil {
+		t.Fatalf("expected an error for a non-permutation")
+	}
+	if _, err := Kemeny([][]int{{0, 1}, {0, 1, 2}}); err == nil {
+		t.Fatalf("expected an error for mismatched lengths")
+	}
+}
+
+func TestKemenyApproximate(t *testing.T) {
+	rand.Seed(23)
+	n := ExactLimit + 8
+	truth := make([]int, n)
+	for i := range truth {
+		truth[i] = i
+	}
+
+	// Each ranking is the truth with a few random adjacent swaps.
+	rankings := make([][]int, 15)
+	for r := range rankings {
+		rankings[r] = append([]int(nil), truth...)
+		for k := 0; k < 5; k++ {
+			i := rand.Intn(n - 1)
+			rankings[r][i], rankings[r][i+1] = rankings[r][i+1], rankings[r][i]
+		}
+	}
+
+	consensus, err := Kemeny(rankings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := Disagreements(consensus, rankings)
+	if want, _ := Disagreements(truth, rankings); got > want {
+		t.Fatalf("expected at most %d disagreements, got %d", want, got)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	eng := collaborativepermute.NewEngine(2, 3)
+	rankings, err := Collect(eng, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rankings) != 2 || len(rankings[0]) != 3 {
+		t.Fatalf("expected 2 rankings of 3 choices, got %v", rankings)
+	}
+	if _, err := Collect(eng, 3); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+}
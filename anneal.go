@@ -0,0 +1,50 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Type LambdaSchedule varies the regularization strength as responses
+// accumulate.
+type LambdaSchedule interface {
+	// Lambda returns the regularization strength to use once n responses
+	// have been recorded, given the engine's base strength.
+	Lambda(lambda float64, n int) float64
+}
+
+// Struct Annealing starts with a regularization strength of Start and relaxes
+// it exponentially toward the engine's Lambda, halving the difference every
+// HalfLife responses. Strong early regularization keeps the first few
+// responses from being overfit; relaxing it later lets the engine fit the
+// detail that the accumulated data supports.
+type Annealing struct {
+	Start    float64
+	HalfLife float64
+}
+
+// Method Lambda returns the annealed regularization strength.
+func (a Annealing) Lambda(lambda float64, n int) float64 {
+	return lambda + (a.Start-lambda)*math.Pow(0.5, float64(n)/a.HalfLife)
+}
+
+// WithLambdaSchedule sets how the regularization strength varies as
+// responses accumulate.
+func WithLambdaSchedule(s LambdaSchedule) Option {
+	return func(p *Engine) error {
+		if a, ok := s.(Annealing); ok && !(a.HalfLife > 0 && a.Start >= 0) {
+			return fmt.Errorf("must have HalfLife [%v] > 0 and Start [%v] >= 0",
+				a.HalfLife, a.Start)
+		}
+		p.LambdaSchedule = s
+		return nil
+	}
+}
+
+// lambda returns the regularization strength once n responses are recorded.
+func (p *Engine) lambda(n int) float64 {
+	if p.LambdaSchedule == nil {
+		return p.Lambda
+	}
+	return p.LambdaSchedule.Lambda(p.Lambda, n)
+}
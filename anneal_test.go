@@ -0,0 +1,29 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAnnealing(t *testing.T) {
+	a := Annealing{Start: 1, HalfLife: 10}
+	if l := a.Lambda(0.04, 0); math.Abs(l-1) > 1e-12 {
+		t.Fatalf("expected to start at 1, got %v", l)
+	}
+	if l := a.Lambda(0.04, 10); math.Abs(l-0.52) > 1e-12 {
+		t.Fatalf("expected to halve the gap after 10 responses, got %v", l)
+	}
+	if l := a.Lambda(0.04, 1000); math.Abs(l-0.04) > 1e-12 {
+		t.Fatalf("expected to approach Lambda, got %v", l)
+	}
+
+	rand.Seed(23)
+	eng := NewEngine(2, 3, WithLambdaSchedule(a))
+	for i := 0; i < 40; i++ {
+		eng.Respond(Query{User: i % 2, Choices: []int{1, 2}})
+	}
+	if ranking, _ := eng.Rank(0); ranking[0] != 1 {
+		t.Fatalf("expected preference to survive annealing, got %v", ranking)
+	}
+}
@@ -0,0 +1,109 @@
+package collaborativepermute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type AuditKind distinguishes the events in an audit log.
+type AuditKind string
+
+const (
+	// Generated records a query returned by Generate.
+	Generated AuditKind = "query"
+
+	// Responded records a response accepted by Respond.
+	Responded AuditKind = "response"
+)
+
+// Struct AuditEvent records one query or response for an audit log.
+type AuditEvent struct {
+	Kind AuditKind
+	Time time.Time
+
+	// Query is the generated query or the response, as given.
+	Query Query
+
+	// Strategy names the strategy that chose a generated query: its String
+	// method if it has one, and otherwise its Go type.
+	Strategy string
+
+	// Version identifies the model that generated the query or received the
+	// response, as the number of responses it had learned from.
+	Version int
+}
+
+// Type AuditSink stores audit events, for instance in a file or database.
+type AuditSink interface {
+	Record(event AuditEvent) error
+}
+
+// Type AuditFunc adapts an ordinary function to the AuditSink interface.
+type AuditFunc func(event AuditEvent) error
+
+// Method Record calls f(event).
+func (f AuditFunc) Record(event AuditEvent) error {
+	return f(event)
+}
+
+// WithAudit records every generated query and every response to the sink.
+// Responses that cannot be recorded are rejected, so that the engine never
+// learns from a response missing from the audit log.
+func WithAudit(sink AuditSink) Option {
+	return func(p *Engine) error {
+		if sink == nil {
+			return fmt.Errorf("must have a non-nil audit sink")
+		}
+		p.Audit = sink
+		return nil
+	}
+}
+
+// Struct JSONAudit is an AuditSink that writes each event as a line of JSON.
+// It is safe for use by several engines at once.
+type JSONAudit struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAudit creates a JSONAudit writing to w.
+func NewJSONAudit(w io.Writer) *JSONAudit {
+	return &JSONAudit{enc: json.NewEncoder(w)}
+}
+
+// Method Record writes the event as a line of JSON.
+func (a *JSONAudit) Record(event AuditEvent) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(event)
+}
+
+// audit records the query to the engine's sink, if any.
+func (p *Engine) audit(kind AuditKind, q Query) error {
+	if p.Audit == nil {
+		return nil
+	}
+	event := AuditEvent{
+		Kind:    kind,
+		Time:    time.Now(),
+		Query:   q,
+		Version: len(p.History),
+	}
+	if kind == Generated {
+		event.Strategy = strategyName(p.strategy())
+	}
+	if err := p.Audit.Record(event); err != nil {
+		return fmt.Errorf("could not record %s: %v", kind, err)
+	}
+	return nil
+}
+
+func strategyName(s Strategy) string {
+	if named, ok := s.(fmt.Stringer); ok {
+		return named.String()
+	}
+	return fmt.Sprintf("%T", s)
+}
@@ -0,0 +1,49 @@
+package collaborativepermute
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	rand.Seed(23)
+	var buf bytes.Buffer
+	eng := NewEngine(2, 3, WithAudit(NewJSONAudit(&buf)))
+	q := eng.Generate(0)
+	eng.Respond(q)
+	eng.clone().Respond(q)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit events, got %d: %s", len(lines), buf.String())
+	}
+	var events [2]AuditEvent
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &events[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if events[0].Kind != Generated || events[0].Strategy != "uncertainty" ||
+		events[0].Version != 0 || events[1].Kind != Responded ||
+		events[1].Strategy != "" || events[1].Time.IsZero() {
+		t.Fatalf("unexpected audit events %+v", events)
+	}
+	if events[1].Query.Choices[0] != q.Choices[0] {
+		t.Fatalf("expected the response %v, got %v", q, events[1].Query)
+	}
+
+	failing := NewEngine(2, 3, WithAudit(AuditFunc(func(AuditEvent) error {
+		return fmt.Errorf("disk full")
+	})))
+	failing.Generate(0)
+	if failing.AuditErr == nil {
+		t.Fatalf("expected the failure to record a query to be kept")
+	}
+	if err := failing.Respond(q); err == nil || len(failing.History) != 0 {
+		t.Fatalf("expected a response that was not recorded to be rejected")
+	}
+}
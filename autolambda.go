@@ -0,0 +1,103 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Struct AutoLambda configures automatic tuning of the regularization
+// strength. Every Every responses, the engine holds out a Holdout fraction of
+// its History, refits a copy of itself on the rest with each of the candidate
+// Lambdas, and adopts whichever best predicts the held-out responses.
+type AutoLambda struct {
+	Candidates []float64
+	Holdout    float64
+	Every      int
+}
+
+// WithAutoLambda enables automatic tuning of Lambda; see AutoLambda.
+func WithAutoLambda(a AutoLambda) Option {
+	return func(p *Engine) error {
+		if len(a.Candidates) == 0 {
+			return fmt.Errorf("must have at least one candidate Lambda")
+		}
+		for _, lambda := range a.Candidates {
+			if !(lambda >= 0) {
+				return fmt.Errorf("must have Lambda [%v] >= 0", lambda)
+			}
+		}
+		if !(a.Holdout > 0 && a.Holdout < 1) {
+			return fmt.Errorf("must have 0 < Holdout [%v] < 1", a.Holdout)
+		}
+		if a.Every < 1 {
+			return fmt.Errorf("must have Every [%d] >= 1", a.Every)
+		}
+		p.AutoLambda = &a
+		return nil
+	}
+}
+
+// Method TuneLambda evaluates each of the AutoLambda candidates on held-out
+// responses, adopts the best as Lambda, and refits the engine. It returns the
+// chosen Lambda.
+func (p *Engine) TuneLambda() (float64, error) {
+	if p.AutoLambda == nil {
+		return 0, fmt.Errorf("engine was not configured WithAutoLambda")
+	}
+	train, holdout := splitHoldout(p.History, p.AutoLambda.Holdout)
+	if len(train) == 0 || len(holdout) == 0 {
+		return p.Lambda, fmt.Errorf("too few responses [%d] to hold out %v",
+			len(p.History), p.AutoLambda.Holdout)
+	}
+
+	best, bestAccuracy := p.Lambda, -1.0
+	for _, lambda := range p.AutoLambda.Candidates {
+		c := p.clone()
+		c.Lambda = lambda
+		c.History = train
+		c.Refit()
+		if accuracy := c.accuracy(holdout); accuracy > bestAccuracy {
+			best, bestAccuracy = lambda, accuracy
+		}
+	}
+
+	if best != p.Lambda {
+		p.Lambda = best
+		p.Refit()
+	}
+	return best, nil
+}
+
+// accuracy returns the fraction of the pairs implied by the given responses
+// whose preferred choice the engine currently scores strictly higher.
+func (p *Engine) accuracy(samps []Query) float64 {
+	correct, total := 0, 0
+	for _, q := range samps {
+		for _, pair := range p.preferences(q) {
+			if p.scoreIn(q.User, pair[0], q.Context) >
+				p.scoreIn(q.User, pair[1], q.Context) {
+				correct++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total)
+}
+
+// splitHoldout deterministically assigns an evenly spaced fraction of the
+// history to the holdout set, and the remainder to the training set.
+func splitHoldout(history []Query, fraction float64) (train, holdout []Query) {
+	for i, q := range history {
+		before := math.Floor(float64(i)*fraction + 1e-9)
+		after := math.Floor(float64(i+1)*fraction + 1e-9)
+		if after > before {
+			holdout = append(holdout, q)
+		} else {
+			train = append(train, q)
+		}
+	}
+	return train, holdout
+}
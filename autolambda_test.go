@@ -0,0 +1,41 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTuneLambda(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(4, 4, WithAutoLambda(AutoLambda{
+		Candidates: []float64{0.04, 50},
+		Holdout:    0.25,
+		Every:      8,
+	}))
+	eng.Lambda = 50
+	for i := 0; i < 16; i++ {
+		eng.Respond(Query{User: i % 4, Choices: []int{i % 3, 3}})
+	}
+
+	if eng.Lambda != 0.04 {
+		t.Fatalf("expected the weaker regularization to win, got %v",
+			eng.Lambda)
+	}
+	if _, err := NewEngine(1, 2).TuneLambda(); err == nil {
+		t.Fatalf("expected an error without AutoLambda")
+	}
+
+	c := eng.clone()
+	c.AutoLambda.Candidates[0] = 1
+	if eng.AutoLambda.Candidates[0] != 0.04 {
+		t.Fatalf("expected the clone not to share the candidates")
+	}
+}
+
+func TestSplitHoldout(t *testing.T) {
+	history := make([]Query, 10)
+	train, holdout := splitHoldout(history, 0.3)
+	if len(train) != 7 || len(holdout) != 3 {
+		t.Fatalf("expected a 7/3 split, got %d/%d", len(train), len(holdout))
+	}
+}
@@ -0,0 +1,64 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// Type Backend performs the one linear algebra routine the engine does not
+// implement itself: the singular value decomposition behind the nuclear norm.
+// Matrices are exchanged as gauss.Arrays, which are plain row-major
+// containers (Shape and Data), so a Backend can wrap any numeric library; the
+// gonum subpackage provides one.
+type Backend interface {
+	// SVD factors the m×n matrix a as U·diag(S)·Vᵀ, where U is m×k, V is
+	// n×k, and S holds the k singular values.
+	SVD(a gauss.Array) (u, s, v gauss.Array)
+}
+
+type gaussBackend struct{}
+
+func (gaussBackend) SVD(a gauss.Array) (u, s, v gauss.Array) {
+	return gauss.SVD(a)
+}
+
+// Gauss computes with github.com/fatlotus/gauss. This is the default.
+var Gauss Backend = gaussBackend{}
+
+// WithBackend sets the library that computes SVDs.
+func WithBackend(b Backend) Option {
+	return func(p *Engine) error {
+		if b == nil {
+			return fmt.Errorf("must have a non-nil backend")
+		}
+		p.Backend = b
+		return nil
+	}
+}
+
+func (p *Engine) backend() Backend {
+	return orGauss(p.Backend)
+}
+
+func orGauss(b Backend) Backend {
+	if b == nil {
+		return Gauss
+	}
+	return b
+}
+
+// recompose returns U·diag(S)·Vᵀ.
+func recompose(u, s, v gauss.Array) gauss.Array {
+	rows, cols, k := u.Shape[0], v.Shape[0], len(s.Data)
+	result := gauss.Zero(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			sum := 0.0
+			for l := 0; l < k; l++ {
+				sum += *u.I(i, l) * s.Data[l] * *v.I(j, l)
+			}
+			*result.I(i, j) = sum
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package collaborativepermute
+
+import (
+	"github.com/fatlotus/gauss"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+type countingBackend struct {
+	calls int
+}
+
+func (c *countingBackend) SVD(a gauss.Array) (u, s, v gauss.Array) {
+	c.calls++
+	return gauss.SVD(a)
+}
+
+func TestWithBackend(t *testing.T) {
+	for _, r := range []Regularizer{nil, NuclearNorm, ElasticNet{Ratio: 0.5}} {
+		rand.Seed(23)
+		backend := &countingBackend{}
+		eng := NewEngine(3, 4, WithBackend(backend))
+		ref := NewEngine(3, 4)
+		if r != nil {
+			eng.Regularizer, ref.Regularizer = r, r
+		}
+		for _, e := range []*Engine{eng, ref} {
+			e.Respond(Query{User: 0, Choices: []int{1, 0}})
+			e.Respond(Query{User: 2, Choices: []int{3, 1}})
+		}
+
+		if backend.calls == 0 {
+			t.Fatalf("%v: expected the engine to use its backend", r)
+		}
+		for i := range ref.X.Data {
+			if math.Abs(eng.X.Data[i]-ref.X.Data[i]) > 1e-9 {
+				t.Fatalf("%v: backend changed the fit at %d", r, i)
+			}
+		}
+	}
+	if _, err := NewEngineSafe(1, 2, WithBackend(nil)); err == nil {
+		t.Fatalf("expected an error for a nil backend")
+	}
+}
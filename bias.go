@@ -0,0 +1,31 @@
+package collaborativepermute
+
+import (
+	"github.com/fatlotus/gauss"
+)
+
+// WithItemBias learns a popularity offset for each choice that is shared by
+// every user. Universally popular choices are then learned once, rather than
+// independently for each user, and the low-rank matrix only needs to capture
+// how users differ from the consensus.
+func WithItemBias() Option {
+	return func(p *Engine) error {
+		p.Bias = make([]float64, p.X.Shape[1])
+		return nil
+	}
+}
+
+// updateBias takes a gradient step of size nu on Bias, given the gradient of
+// the loss with respect to the scores, with a ridge penalty of Lambda.
+func (p *Engine) updateBias(gradient gauss.Array, nu float64) {
+	if p.Bias == nil {
+		return
+	}
+	for j := range p.Bias {
+		step := p.Lambda * p.Bias[j]
+		for u := 0; u < gradient.Shape[0]; u++ {
+			step += *gradient.I(u, j)
+		}
+		p.Bias[j] -= nu * step
+	}
+}
@@ -0,0 +1,27 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestItemBias(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(4, 3, WithItemBias())
+	for i := 0; i < 10; i++ {
+		eng.Respond(Query{User: i % 3, Choices: []int{2, 0}})
+	}
+
+	if eng.Bias[2] <= eng.Bias[0] {
+		t.Fatalf("expected choice 2 to be more popular, got %v", eng.Bias)
+	}
+	ranking, _ := eng.Rank(3)
+	if ranking[0] != 2 {
+		t.Fatalf("expected user 3 to inherit the popular choice, got %v", ranking)
+	}
+
+	eng.AddItem()
+	if len(eng.Bias) != 4 {
+		t.Fatalf("expected a bias for the new choice")
+	}
+}
@@ -0,0 +1,85 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Struct Stability reports how stable each choice's position in each user's
+// ranking is under bootstrap resampling of the History. Positions count from
+// zero for the most preferred choice.
+type Stability struct {
+	// Rankings holds each user's ranking from the full History.
+	Rankings [][]int
+
+	// MeanPosition[u][c] and StdDevPosition[u][c] are the mean and standard
+	// deviation of choice c's position in user u's resampled rankings.
+	MeanPosition, StdDevPosition [][]float64
+
+	// Agreement[u][c] is the fraction of resamples that put choice c in the
+	// same position as Rankings[u].
+	Agreement [][]float64
+}
+
+// Method Settled reports whether at least the given fraction of resamples
+// agree on the position of the choice in the user's ranking. Choices that are
+// not settled are best displayed as provisional.
+func (s Stability) Settled(user, choice int, threshold float64) bool {
+	return s.Agreement[user][choice] >= threshold
+}
+
+// Method Stability refits the engine on the given number of bootstrap
+// resamples of its History, drawn with replacement, and reports how much
+// each choice's rank position moves between them. The engine itself is left
+// unchanged, although its random source is advanced.
+func (p *Engine) Stability(samples int) (Stability, error) {
+	if samples <= 0 {
+		return Stability{}, fmt.Errorf("must have samples [%d] > 0", samples)
+	}
+	users, choices := p.X.Shape[0], p.X.Shape[1]
+	s := Stability{
+		Rankings:       make([][]int, users),
+		MeanPosition:   make([][]float64, users),
+		StdDevPosition: make([][]float64, users),
+		Agreement:      make([][]float64, users),
+	}
+	squares := make([][]float64, users)
+	for u := 0; u < users; u++ {
+		s.Rankings[u], _ = p.Rank(u)
+		s.MeanPosition[u] = make([]float64, choices)
+		s.StdDevPosition[u] = make([]float64, choices)
+		s.Agreement[u] = make([]float64, choices)
+		squares[u] = make([]float64, choices)
+	}
+
+	for b := 0; b < samples; b++ {
+		resample := p.clone()
+		for i := range resample.History {
+			j := int(p.random() * float64(len(p.History)))
+			resample.History[i] = p.History[j]
+		}
+		resample.Refit()
+		for u := 0; u < users; u++ {
+			ranking, _ := resample.Rank(u)
+			for pos, c := range ranking {
+				s.MeanPosition[u][c] += float64(pos)
+				squares[u][c] += float64(pos * pos)
+				if s.Rankings[u][pos] == c {
+					s.Agreement[u][c]++
+				}
+			}
+		}
+	}
+
+	n := float64(samples)
+	for u := 0; u < users; u++ {
+		for c := 0; c < choices; c++ {
+			mean := s.MeanPosition[u][c] / n
+			variance := squares[u][c]/n - mean*mean
+			s.MeanPosition[u][c] = mean
+			s.StdDevPosition[u][c] = math.Sqrt(math.Max(variance, 0))
+			s.Agreement[u][c] /= n
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,47 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStability(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+
+	// User 0 has answered every pair consistently; user 1 has only said
+	// that 2 beats 3.
+	for i := 0; i < 3; i++ {
+		for a := 0; a < 4; a++ {
+			for b := a + 1; b < 4; b++ {
+				eng.Respond(Query{User: 0, Choices: []int{a, b}})
+			}
+		}
+	}
+	eng.Respond(Query{User: 1, Choices: []int{2, 3}})
+	before := append([]float64(nil), eng.X.Data...)
+
+	s, err := eng.Stability(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Settled(0, 0, 0.9) || s.StdDevPosition[0][0] != 0 {
+		t.Fatalf("expected user 0's favorite to be settled, got %v",
+			s.Agreement[0])
+	}
+	for c := 0; c < 4; c++ {
+		if s.Agreement[1][c] > s.Agreement[0][c] {
+			t.Fatalf("expected user 1 to be less settled, got %v and %v",
+				s.Agreement[1], s.Agreement[0])
+		}
+	}
+	for i, v := range eng.X.Data {
+		if v != before[i] {
+			t.Fatalf("expected the engine to be unchanged")
+		}
+	}
+
+	if _, err := eng.Stability(0); err == nil {
+		t.Fatalf("expected an error for no samples")
+	}
+}
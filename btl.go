@@ -0,0 +1,168 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Struct BTLEngine is an alternative learner based on the Bradley-Terry-Luce
+// model, in which a user prefers choice a over b with probability
+// σ(θ(a) - θ(b)), extended to rankings of more than two choices by the
+// Plackett-Luce model. Each user's strengths θ are coupled across users by a
+// low-rank factorization θ = U·V', fit by stochastic gradient descent on the
+// regularized log-likelihood of the History.
+type BTLEngine struct {
+	U, V            [][]float64
+	Rate, Lambda, T float64
+	Epochs          int
+	History         []Query
+}
+
+// NewBTLEngine allocates a Bradley-Terry-Luce learner for the given corpus
+// size, with user and choice factors of the given rank.
+func NewBTLEngine(users, choices, rank int) (*BTLEngine, error) {
+	if users < 0 || choices < 0 || rank < 1 {
+		return nil, fmt.Errorf("must have users [%d] >= 0, choices [%d] >= 0 "+
+			"and rank [%d] >= 1", users, choices, rank)
+	}
+
+	// The factors start out small and random, since gradients vanish when
+	// both are zero.
+	init := rand.New(rand.NewSource(1))
+	factors := func(n int) [][]float64 {
+		result := make([][]float64, n)
+		for i := range result {
+			result[i] = make([]float64, rank)
+			for k := range result[i] {
+				result[i][k] = 0.01 * init.NormFloat64()
+			}
+		}
+		return result
+	}
+	return &BTLEngine{
+		U:       factors(users),
+		V:       factors(choices),
+		Rate:    0.5,
+		Lambda:  0.01,
+		T:       1,
+		Epochs:  1,
+		History: make([]Query, 0),
+	}, nil
+}
+
+var _ Learner = (*BTLEngine)(nil)
+
+// Method Score returns the strength θ of the choice for the given user.
+func (p *BTLEngine) Score(user, choice int) float64 {
+	sum := 0.0
+	for k, u := range p.U[user] {
+		sum += u * p.V[choice][k]
+	}
+	return sum
+}
+
+// Method Probability returns the modeled probability that the user prefers
+// choice a over choice b.
+func (p *BTLEngine) Probability(user, a, b int) float64 {
+	return sigmoid(p.Score(user, a) - p.Score(user, b))
+}
+
+// Method Respond takes a completed Query and makes Epochs passes of
+// stochastic gradient descent over the History. The Query may rank any number
+// of distinct choices, from most to least preferred; see PlackettLuce.
+func (p *BTLEngine) Respond(prompt Query) error {
+	if len(prompt.Choices) < 2 {
+		return fmt.Errorf("must rank at least two choices")
+	}
+	if prompt.User < 0 || prompt.User >= len(p.U) {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			prompt.User, len(p.U))
+	}
+	seen := make(map[int]bool)
+	for _, choice := range prompt.Choices {
+		if choice < 0 || choice >= len(p.V) {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d",
+				choice, len(p.V))
+		}
+		if seen[choice] {
+			return fmt.Errorf("choice %d ranked more than once", choice)
+		}
+		seen[choice] = true
+	}
+	p.History = append(p.History, prompt)
+	for epoch := 0; epoch < p.Epochs; epoch++ {
+		for _, q := range p.History {
+			p.step(q)
+		}
+	}
+	return nil
+}
+
+// step takes one stochastic gradient step on the negative log-likelihood of
+// a single response.
+func (p *BTLEngine) step(q Query) {
+	scores := make([]float64, len(q.Choices))
+	for i, c := range q.Choices {
+		scores[i] = p.Score(q.User, c)
+	}
+	g := plackettLuceGradient(scores)
+
+	u := p.U[q.User]
+	du := make([]float64, len(u))
+	for k := range u {
+		du[k] = -p.Lambda * u[k]
+		for i, c := range q.Choices {
+			du[k] += g[i] * p.V[c][k]
+		}
+	}
+	for i, c := range q.Choices {
+		v := p.V[c]
+		for k := range v {
+			v[k] += p.Rate * (g[i]*u[k] - p.Lambda*v[k])
+		}
+	}
+	for k := range u {
+		u[k] += p.Rate * du[k]
+	}
+}
+
+// Method Generate creates a new Query to display to the user, preferring the
+// pairs whose outcome is least certain. If user is non-negative, only return
+// queries for that user. Generate panics if there is no question to ask;
+// GenerateSafe returns an error instead.
+func (p *BTLEngine) Generate(user int) Query {
+	q, err := p.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (p *BTLEngine) GenerateSafe(user int) (Query, error) {
+	if user >= len(p.U) {
+		return Query{}, fmt.Errorf("must have user [%d] < %d", user, len(p.U))
+	}
+	q, ok := sampleQuery(len(p.U), len(p.V), user, rand.Float64,
+		func(u, a, b int) float64 {
+			return math.Exp(-math.Abs(p.Score(u, a)-p.Score(u, b)) / p.T)
+		}, p.Score)
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	return q, nil
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user.
+func (p *BTLEngine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= len(p.U) {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, len(p.U))
+	}
+	return rankBy(len(p.V), func(choice int) float64 {
+		return p.Score(user, choice)
+	}), nil
+}
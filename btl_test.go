@@ -0,0 +1,31 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBTLConvergence(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewBTLEngine(10, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incorrect := 0
+
+	for i := 0; i < 300; i++ {
+		q := eng.Generate(-1)
+		if q.Choices[0] >= q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+			incorrect += 1
+		}
+		eng.Respond(q)
+	}
+
+	if incorrect > 40 {
+		t.Fatalf("needed %v mistakes for a 10x10 matrix", incorrect)
+	}
+	if _, err := NewBTLEngine(2, 2, 0); err == nil {
+		t.Fatalf("expected an error for rank 0")
+	}
+}
@@ -0,0 +1,140 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Raw scores are only meaningful relative to one another, and their scale
+// depends on Lambda, the Margin, and the loss. A Calibration learns, from the
+// responses the engine has observed, how a difference in score translates
+// into the probability that a user actually prefers one choice over another.
+
+// Type Calibration maps a difference in score between two choices to the
+// probability that the user prefers the first.
+type Calibration interface {
+	Probability(diff float64) float64
+}
+
+// Struct Platt is a Platt-scaling calibration, P = σ(Slope·diff).
+type Platt struct {
+	Slope float64
+}
+
+// Method Probability implements Calibration.
+func (c Platt) Probability(diff float64) float64 {
+	return sigmoid(c.Slope * diff)
+}
+
+// Struct Isotonic is a non-decreasing step-function calibration, fit by
+// isotonic regression. A difference of at most Bounds[i] (and more than
+// Bounds[i-1]) maps to Probs[i].
+type Isotonic struct {
+	Bounds, Probs []float64
+}
+
+// Method Probability implements Calibration.
+func (c Isotonic) Probability(diff float64) float64 {
+	i := sort.SearchFloat64s(c.Bounds, diff)
+	if i == len(c.Probs) {
+		i--
+	}
+	return c.Probs[i]
+}
+
+// Function FitPlatt fits a Platt calibration to the engine's History. Each
+// response is also mirrored (the reverse preference, with the negated
+// difference), so the fit is symmetric, and the targets are smoothed as in
+// (Platt '99) so that perfectly separated responses give a finite slope.
+func FitPlatt(p *Engine) (Platt, error) {
+	diffs := p.responseDiffs()
+	if len(diffs) == 0 {
+		return Platt{}, fmt.Errorf("must have at least one response")
+	}
+	target := float64(len(diffs)+1) / float64(len(diffs)+2)
+
+	// Newton's method on the cross-entropy; by symmetry, each mirrored
+	// response contributes the same as the original.
+	slope := 1.0
+	for i := 0; i < 50; i++ {
+		gradient, hessian := 0.0, 0.0
+		for _, d := range diffs {
+			s := sigmoid(slope * d)
+			gradient += (s - target) * d
+			hessian += s * (1 - s) * d * d
+		}
+		if hessian < 1e-12 {
+			break
+		}
+		step := gradient / hessian
+		slope -= step
+		if math.Abs(step) < 1e-9 {
+			break
+		}
+	}
+	return Platt{Slope: slope}, nil
+}
+
+// Function FitIsotonic fits an Isotonic calibration to the engine's History,
+// using the pool-adjacent-violators algorithm on each response and its mirror
+// image.
+func FitIsotonic(p *Engine) (Isotonic, error) {
+	diffs := p.responseDiffs()
+	if len(diffs) == 0 {
+		return Isotonic{}, fmt.Errorf("must have at least one response")
+	}
+	type point struct{ x, y float64 }
+	points := make([]point, 0, 2*len(diffs))
+	for _, d := range diffs {
+		points = append(points, point{d, 1}, point{-d, 0})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].x < points[j].x })
+
+	var c Isotonic
+	var weights []float64
+	for _, pt := range points {
+		c.Bounds = append(c.Bounds, pt.x)
+		c.Probs = append(c.Probs, pt.y)
+		weights = append(weights, 1)
+		for n := len(c.Probs); n > 1 && c.Probs[n-2] >= c.Probs[n-1]; n-- {
+			w := weights[n-2] + weights[n-1]
+			c.Probs[n-2] = (c.Probs[n-2]*weights[n-2] +
+				c.Probs[n-1]*weights[n-1]) / w
+			weights[n-2] = w
+			c.Bounds[n-2] = c.Bounds[n-1]
+			c.Bounds, c.Probs, weights = c.Bounds[:n-1], c.Probs[:n-1],
+				weights[:n-1]
+		}
+	}
+	return c, nil
+}
+
+// Method PreferenceScore returns a 0–100 score for the choice: the calibrated
+// probability, in percent, that the user prefers it over another choice
+// picked at random.
+func (p *Engine) PreferenceScore(user, choice int, c Calibration) float64 {
+	if p.X.Shape[1] < 2 {
+		return 50
+	}
+	sum := 0.0
+	for o := 0; o < p.X.Shape[1]; o++ {
+		if o != choice {
+			sum += c.Probability(p.Score(user, choice) - p.Score(user, o))
+		}
+	}
+	return 100 * sum / float64(p.X.Shape[1]-1)
+}
+
+// responseDiffs returns, for each pair implied by the History, how far the
+// engine currently scores the preferred choice above the other.
+func (p *Engine) responseDiffs() []float64 {
+	var diffs []float64
+	for _, q := range p.History {
+		for _, pair := range p.preferences(q) {
+			diffs = append(diffs, p.scoreIn(q.User, pair[0], q.Context)-
+				p.scoreIn(q.User, pair[1], q.Context))
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,49 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCalibration(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+	for i := 0; i < 60; i++ {
+		a, b := rand.Intn(4), rand.Intn(3)
+		if b >= a {
+			a, b = b+1, a
+		}
+		// Users prefer lower choices, but are wrong a fifth of the time.
+		if rand.Intn(5) == 0 {
+			a, b = b, a
+		}
+		eng.Respond(Query{User: i % 2, Choices: []int{b, a}})
+	}
+
+	platt, err := FitPlatt(eng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isotonic, err := FitIsotonic(eng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []Calibration{platt, isotonic} {
+		if p := c.Probability(0); p < 0.4 || p > 0.6 {
+			t.Fatalf("%T: expected a tie to be a toss-up, got %v", c, p)
+		}
+		if c.Probability(1) < c.Probability(-1) {
+			t.Fatalf("%T: expected calibration to be increasing", c)
+		}
+		best := eng.PreferenceScore(0, 0, c)
+		worst := eng.PreferenceScore(0, 3, c)
+		if best <= 50 || worst >= 50 || best > 100 || worst < 0 {
+			t.Fatalf("%T: expected scores on 0-100, got %v and %v",
+				c, best, worst)
+		}
+	}
+
+	if _, err := FitPlatt(NewEngine(1, 2)); err == nil {
+		t.Fatalf("expected an error without responses")
+	}
+}
@@ -0,0 +1,72 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// WithCategories assigns each choice to one of the given number of
+// categories (or -1 for none), and learns a per-user offset for each
+// category that is added to the scores of all of its choices. Choices in the
+// same category then share statistical strength: a user who prefers one
+// Italian restaurant is assumed to lean toward the others, and a new choice
+// in a well-understood category starts out ranked near its siblings. The
+// offsets are regularized with a ridge penalty of Lambda.
+func WithCategories(categories []int, n int) Option {
+	return func(p *Engine) error {
+		if len(categories) != p.X.Shape[1] {
+			return fmt.Errorf("must have len(categories) [%d] == %d",
+				len(categories), p.X.Shape[1])
+		}
+		for _, c := range categories {
+			if c < -1 || c >= n {
+				return fmt.Errorf("must have -1 <= category [%d] < %d", c, n)
+			}
+		}
+		p.ItemCategories = append([]int(nil), categories...)
+		p.CategoryOffsets = gauss.Zero(p.X.Shape[0], n)
+		return nil
+	}
+}
+
+// Method AddItemIn appends a new choice in the given category, returning its
+// index. Until it has been compared, each user scores it by their offset for
+// the category.
+func (p *Engine) AddItemIn(category int) (int, error) {
+	if p.CategoryOffsets.Shape == nil {
+		return 0, fmt.Errorf("engine was not configured WithCategories")
+	}
+	if category < -1 || category >= p.CategoryOffsets.Shape[1] {
+		return 0, fmt.Errorf("must have -1 <= category [%d] < %d",
+			category, p.CategoryOffsets.Shape[1])
+	}
+	item := p.AddItem()
+	p.ItemCategories[item] = category
+	return item, nil
+}
+
+// categoryOffset returns the user's offset for the choice's category.
+func (p *Engine) categoryOffset(user, choice int) float64 {
+	if p.CategoryOffsets.Shape == nil || p.ItemCategories[choice] < 0 {
+		return 0
+	}
+	return *p.CategoryOffsets.I(user, p.ItemCategories[choice])
+}
+
+// updateCategoryOffsets takes a gradient step of size nu on CategoryOffsets,
+// given the gradient of the loss with respect to the scores, with a ridge
+// penalty of Lambda.
+func (p *Engine) updateCategoryOffsets(gradient gauss.Array, nu float64) {
+	if p.CategoryOffsets.Shape == nil {
+		return
+	}
+	step := clone(p.CategoryOffsets).Scale(p.Lambda)
+	for u := 0; u < gradient.Shape[0]; u++ {
+		for j, c := range p.ItemCategories {
+			if c >= 0 {
+				*step.I(u, c) += *gradient.I(u, j)
+			}
+		}
+	}
+	p.CategoryOffsets = gauss.Sum(p.CategoryOffsets, step.Scale(-nu))
+}
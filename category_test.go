@@ -0,0 +1,45 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	rand.Seed(23)
+	// Choices 0-1 are Italian and 2-3 are Thai.
+	eng := NewEngine(2, 4, WithCategories([]int{0, 0, 1, 1}, 2))
+	for i := 0; i < 20; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 2}})
+		eng.Respond(Query{User: 1, Choices: []int{3, 1}})
+	}
+
+	if eng.Score(0, 1) <= eng.Score(0, 3) {
+		t.Fatalf("expected user 0's taste for Italian to generalize")
+	}
+	if eng.Score(1, 2) <= eng.Score(1, 0) {
+		t.Fatalf("expected user 1's taste for Thai to generalize")
+	}
+
+	item, err := eng.AddItemIn(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng.Score(1, item) <= eng.Score(1, 0) {
+		t.Fatalf("expected a new Thai choice to start out ahead for user 1")
+	}
+
+	if err := eng.RemoveItem(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(eng.ItemCategories) != 4 || eng.ItemCategories[0] != 0 {
+		t.Fatalf("expected categories to follow removal, got %v",
+			eng.ItemCategories)
+	}
+	if _, err := eng.AddItemIn(2); err == nil {
+		t.Fatalf("expected an error for an unknown category")
+	}
+	if _, err := NewEngineSafe(2, 4, WithCategories([]int{0}, 1)); err == nil {
+		t.Fatalf("expected an error for too few categories")
+	}
+}
@@ -0,0 +1,74 @@
+package collaborativepermute
+
+// clone returns a deep copy of the engine, which can be refit or trained
+// further without affecting the original. The copy does not call OnLoss or the
+// OnUpdate and OnQuery callbacks, record to Audit, report to MetricsHook, or
+// log to Logger. It reports whether the original had converged, but does not
+// pass itself to the original's Criteria, whose state belongs to the original.
+func (p *Engine) clone() *Engine {
+	c := *p
+	c.OnLoss, c.Audit, c.MetricsHook, c.Logger = nil, nil, nil, nil
+	c.onUpdate, c.onQuery = nil, nil
+	if p.Convergence != nil {
+		c.Convergence = &ConvergenceDetector{
+			converged: p.Convergence.converged,
+		}
+	}
+	if p.AutoLambda != nil {
+		a := *p.AutoLambda
+		a.Candidates = append([]float64(nil), a.Candidates...)
+		c.AutoLambda = &a
+	}
+	if p.Privacy != nil {
+		privacy := *p.Privacy
+		c.Privacy = &privacy
+	}
+	c.X, c.Xp, c.Z = clone(p.X), clone(p.Xp), clone(p.Z)
+	if p.A.Shape != nil {
+		c.A = clone(p.A)
+	}
+	if p.B.Shape != nil {
+		c.B = clone(p.B)
+	}
+	if p.C.Shape != nil {
+		c.C = clone(p.C)
+	}
+	if p.CategoryOffsets.Shape != nil {
+		c.CategoryOffsets = clone(p.CategoryOffsets)
+	}
+	if p.ItemCategories != nil {
+		c.ItemCategories = append([]int(nil), p.ItemCategories...)
+	}
+	c.History = append([]Query(nil), p.History...)
+	c.LossHistory = append([]LossRecord(nil), p.LossHistory...)
+	c.ItemFeatures = copyRows(p.ItemFeatures)
+	c.UserFeatures = copyRows(p.UserFeatures)
+	c.Prior = copyRows(p.Prior)
+	if p.Bias != nil {
+		c.Bias = append([]float64(nil), p.Bias...)
+	}
+	if p.Reliability != nil {
+		c.Reliability = append([]float64(nil), p.Reliability...)
+	}
+	if p.UserScales != nil {
+		c.UserOffsets = append([]float64(nil), p.UserOffsets...)
+		c.UserScales = append([]float64(nil), p.UserScales...)
+	}
+	if p.Quarantined != nil {
+		c.Quarantined = append([]bool(nil), p.Quarantined...)
+	}
+	return &c
+}
+
+func copyRows(rows [][]float64) [][]float64 {
+	if rows == nil {
+		return nil
+	}
+	result := make([][]float64, len(rows))
+	for i, row := range rows {
+		if row != nil {
+			result[i] = append([]float64(nil), row...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Struct Clusters describes a partition of users into preference archetypes.
+// Assignments[u] is the cluster of user u, and Rankings[c] is the consensus
+// ranking of cluster c, from most to least preferred.
+type Clusters struct {
+	Assignments []int
+	Rankings    [][]int
+}
+
+// Method ClusterUsers partitions the users into k groups with similar tastes
+// by running k-means on their rows of scores. (Since the belief matrix is
+// low-rank, this is equivalent to clustering the user factors.) Each
+// cluster's consensus ranking orders the choices by their mean score among
+// its members. Initialization is deterministic, by farthest-first traversal
+// starting from user 0.
+func (p *Engine) ClusterUsers(k int) (Clusters, error) {
+	users, choices := p.X.Shape[0], p.X.Shape[1]
+	if k < 1 || k > users {
+		return Clusters{}, fmt.Errorf("must have 1 <= k [%d] <= %d", k, users)
+	}
+
+	rows := make([][]float64, users)
+	for u := range rows {
+		rows[u] = make([]float64, choices)
+		for j := range rows[u] {
+			rows[u][j] = p.Score(u, j)
+		}
+	}
+
+	centers := [][]float64{append([]float64(nil), rows[0]...)}
+	for len(centers) < k {
+		farthest, distance := 0, -1.0
+		for u, row := range rows {
+			if d := nearest(centers, row).distance; d > distance {
+				farthest, distance = u, d
+			}
+		}
+		centers = append(centers, append([]float64(nil), rows[farthest]...))
+	}
+
+	assignments := make([]int, users)
+	for iteration := 0; iteration < 100; iteration++ {
+		changed := iteration == 0
+		for u, row := range rows {
+			if c := nearest(centers, row).index; c != assignments[u] {
+				assignments[u], changed = c, true
+			}
+		}
+		if !changed {
+			break
+		}
+		centers = centroids(rows, assignments, centers)
+	}
+
+	rankings := make([][]int, k)
+	for c, center := range centers {
+		rankings[c] = rankBy(choices, func(j int) float64 {
+			return center[j]
+		})
+	}
+	return Clusters{Assignments: assignments, Rankings: rankings}, nil
+}
+
+type match struct {
+	index    int
+	distance float64
+}
+
+// nearest returns the center closest to row, by squared Euclidean distance.
+func nearest(centers [][]float64, row []float64) match {
+	best := match{distance: math.Inf(1)}
+	for c, center := range centers {
+		d := 0.0
+		for j := range row {
+			d += (row[j] - center[j]) * (row[j] - center[j])
+		}
+		if d < best.distance {
+			best = match{index: c, distance: d}
+		}
+	}
+	return best
+}
+
+// centroids returns the mean row of each cluster, keeping the old center for
+// any cluster that has become empty.
+func centroids(rows [][]float64, assignments []int,
+	old [][]float64) [][]float64 {
+	sums := make([][]float64, len(old))
+	counts := make([]int, len(old))
+	for c := range sums {
+		sums[c] = make([]float64, len(old[c]))
+	}
+	for u, row := range rows {
+		c := assignments[u]
+		counts[c]++
+		for j, v := range row {
+			sums[c][j] += v
+		}
+	}
+	for c := range sums {
+		if counts[c] == 0 {
+			sums[c] = old[c]
+			continue
+		}
+		for j := range sums[c] {
+			sums[c][j] /= float64(counts[c])
+		}
+	}
+	return sums
+}
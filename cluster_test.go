@@ -0,0 +1,35 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestClusterUsers(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(6, 4)
+	for i := 0; i < 60; i++ {
+		u := i % 6
+		if u < 3 {
+			eng.Respond(Query{User: u, Choices: []int{0, 3}})
+		} else {
+			eng.Respond(Query{User: u, Choices: []int{3, 0}})
+		}
+	}
+
+	clusters, err := eng.ClusterUsers(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := clusters.Assignments
+	if a[0] != a[1] || a[1] != a[2] || a[3] != a[4] || a[4] != a[5] ||
+		a[0] == a[3] {
+		t.Fatalf("expected users {0,1,2} and {3,4,5} to cluster, got %v", a)
+	}
+	if clusters.Rankings[a[0]][0] != 0 || clusters.Rankings[a[3]][0] != 3 {
+		t.Fatalf("unexpected consensus rankings %v", clusters.Rankings)
+	}
+	if _, err := eng.ClusterUsers(7); err == nil {
+		t.Fatalf("expected an error for more clusters than users")
+	}
+}
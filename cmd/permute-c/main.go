@@ -0,0 +1,150 @@
+// Command permute-c exports the engine as a C shared library, so that it can
+// be embedded in-process by any language with a C foreign function interface.
+// Build it with
+//
+//	go build -buildmode=c-shared -o libpermute.so ./cmd/permute-c
+//
+// which also writes libpermute.h declaring:
+//
+//	uintptr_t permute_new(int users, int choices);
+//	void permute_free(uintptr_t engine);
+//	int permute_generate(uintptr_t engine, int user, int* out);
+//	int permute_respond(uintptr_t engine, int user, int* choices, int n);
+//	int permute_rank(uintptr_t engine, int user, int* out, int n);
+//	int permute_error(uintptr_t engine, char* buf, int n);
+//
+// permute_new returns 0 if the size is invalid, and otherwise a handle that
+// must eventually be passed to permute_free. permute_generate writes the
+// user and the two choices of a question to out[0], out[1], and out[2].
+// permute_respond takes n choices, most preferred first. permute_rank writes
+// at most n choices, most preferred first, and returns the total number of
+// choices. The other functions return 0 on success and -1 on failure, when
+// permute_error describes the problem: it copies the engine's last error
+// into buf, truncated to n bytes including the terminating NUL, and returns
+// the error's full length, or 0 if there has been none. Each engine may be
+// used from several threads, but the last error is shared between them, so
+// a thread that needs its own must not let others fail in between. From
+// Python, for example:
+//
+//	lib = ctypes.CDLL("./libpermute.so")
+//	lib.permute_new.restype = ctypes.c_size_t
+//	eng = ctypes.c_size_t(lib.permute_new(10, 5))
+//	q = (ctypes.c_int * 3)()
+//	lib.permute_generate(eng, -1, q)
+//	if lib.permute_respond(eng, q[0], (ctypes.c_int * 2)(q[2], q[1]), 2):
+//		buf = ctypes.create_string_buffer(256)
+//		lib.permute_error(eng, buf, len(buf))
+//		raise ValueError(buf.value.decode())
+package main
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// engine guards an Engine, and the description of its last error, for use
+// from several C threads.
+type engine struct {
+	mu  sync.Mutex
+	eng *collaborativepermute.Engine
+	err string
+}
+
+func main() {}
+
+func lookup(h C.uintptr_t) *engine {
+	return cgo.Handle(h).Value().(*engine)
+}
+
+// fail records err as the engine's last error and returns -1.
+func (e *engine) fail(err error) C.int {
+	e.err = err.Error()
+	return -1
+}
+
+//export permute_new
+func permute_new(users, choices C.int) C.uintptr_t {
+	eng, err := collaborativepermute.NewEngineSafe(int(users), int(choices))
+	if err != nil {
+		return 0
+	}
+	return C.uintptr_t(cgo.NewHandle(&engine{eng: eng}))
+}
+
+//export permute_free
+func permute_free(h C.uintptr_t) {
+	cgo.Handle(h).Delete()
+}
+
+//export permute_generate
+func permute_generate(h C.uintptr_t, user C.int, out *C.int) C.int {
+	e := lookup(h)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if out == nil {
+		return e.fail(fmt.Errorf("must have somewhere to write the question"))
+	}
+	q, err := e.eng.GenerateSafe(int(user))
+	if err != nil {
+		return e.fail(err)
+	}
+	dst := unsafe.Slice(out, 3)
+	dst[0], dst[1], dst[2] = C.int(q.User), C.int(q.Choices[0]),
+		C.int(q.Choices[1])
+	return 0
+}
+
+//export permute_respond
+func permute_respond(h C.uintptr_t, user C.int, choices *C.int, n C.int) C.int {
+	e := lookup(h)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if n < 0 || (n > 0 && choices == nil) {
+		return e.fail(fmt.Errorf("must have n [%d] >= 0 choices", n))
+	}
+	q := collaborativepermute.Query{User: int(user)}
+	for _, c := range unsafe.Slice(choices, int(n)) {
+		q.Choices = append(q.Choices, int(c))
+	}
+	if err := e.eng.Respond(q); err != nil {
+		return e.fail(err)
+	}
+	return 0
+}
+
+//export permute_rank
+func permute_rank(h C.uintptr_t, user C.int, out *C.int, n C.int) C.int {
+	e := lookup(h)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ranking, err := e.eng.Rank(int(user))
+	if err != nil {
+		return e.fail(err)
+	}
+	if out != nil {
+		for i := 0; i < int(n) && i < len(ranking); i++ {
+			unsafe.Slice(out, n)[i] = C.int(ranking[i])
+		}
+	}
+	return C.int(len(ranking))
+}
+
+//export permute_error
+func permute_error(h C.uintptr_t, buf *C.char, n C.int) C.int {
+	e := lookup(h)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if buf != nil && n > 0 {
+		dst := unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(n))
+		dst[copy(dst[:n-1], e.err)] = 0
+	}
+	return C.int(len(e.err))
+}
@@ -0,0 +1,129 @@
+//go:build js && wasm
+
+// Command permute-wasm runs small engines entirely in the browser. Build it
+// with
+//
+//	GOOS=js GOARCH=wasm go build -o permute.wasm ./cmd/permute-wasm
+//
+// and load it with the wasm_exec.js shipped with Go. It defines a global
+// collaborativepermute object whose newEngine(users, choices) returns an
+// engine with three methods:
+//
+//	const eng = collaborativepermute.newEngine(10, 5);
+//	const q = eng.generate(-1);        // {user: 3, choices: [4, 1]}
+//	eng.respond(q.user, [1, 4]);       // most preferred first
+//	const ranking = eng.rank(q.user);  // [1, 4, 0, 2, 3]
+//
+// Invalid arguments never throw; each method instead returns an object whose
+// error property describes the problem. The engine is kept in memory only,
+// and is lost when the page is closed.
+package main
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("collaborativepermute", map[string]any{
+		"newEngine": js.FuncOf(newEngine),
+	})
+	select {}
+}
+
+func newEngine(this js.Value, args []js.Value) any {
+	users, err := intArg(args, 0, "users")
+	if err != nil {
+		return fail(err)
+	}
+	choices, err := intArg(args, 1, "choices")
+	if err != nil {
+		return fail(err)
+	}
+	eng, err := collaborativepermute.NewEngineSafe(users, choices)
+	if err != nil {
+		return fail(err)
+	}
+	return map[string]any{
+		"generate": js.FuncOf(func(this js.Value, args []js.Value) any {
+			user, err := intArg(args, 0, "user")
+			if err != nil {
+				return fail(err)
+			}
+			q, err := eng.GenerateSafe(user)
+			if err != nil {
+				return fail(err)
+			}
+			return map[string]any{"user": q.User, "choices": ints(q.Choices)}
+		}),
+		"respond": js.FuncOf(func(this js.Value, args []js.Value) any {
+			user, err := intArg(args, 0, "user")
+			if err != nil {
+				return fail(err)
+			}
+			choices, err := intsArg(args, 1, "choices")
+			if err != nil {
+				return fail(err)
+			}
+			q := collaborativepermute.Query{User: user, Choices: choices}
+			if err := eng.Respond(q); err != nil {
+				return fail(err)
+			}
+			return map[string]any{}
+		}),
+		"rank": js.FuncOf(func(this js.Value, args []js.Value) any {
+			user, err := intArg(args, 0, "user")
+			if err != nil {
+				return fail(err)
+			}
+			ranking, err := eng.Rank(user)
+			if err != nil {
+				return fail(err)
+			}
+			return ints(ranking)
+		}),
+	}
+}
+
+// intArg returns args[i] as an int, rather than letting js.Value.Int panic on
+// a value that is not a whole number.
+func intArg(args []js.Value, i int, name string) (int, error) {
+	if i >= len(args) || args[i].Type() != js.TypeNumber {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	f := args[i].Float()
+	if f != float64(int(f)) {
+		return 0, fmt.Errorf("%s [%v] must be an integer", name, f)
+	}
+	return int(f), nil
+}
+
+func intsArg(args []js.Value, i int, name string) ([]int, error) {
+	if i >= len(args) || !js.Global().Get("Array").Call("isArray", args[i]).Bool() {
+		return nil, fmt.Errorf("%s must be an array", name)
+	}
+	result := make([]int, args[i].Length())
+	for j := range result {
+		n, err := intArg([]js.Value{args[i].Index(j)}, 0,
+			fmt.Sprintf("%s[%d]", name, j))
+		if err != nil {
+			return nil, err
+		}
+		result[j] = n
+	}
+	return result, nil
+}
+
+// ints converts xs to a []any, which js.ValueOf turns into an array.
+func ints(xs []int) []any {
+	result := make([]any, len(xs))
+	for i, x := range xs {
+		result[i] = x
+	}
+	return result
+}
+
+func fail(err error) map[string]any {
+	return map[string]any{"error": err.Error()}
+}
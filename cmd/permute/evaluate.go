@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"os"
+	"strconv"
+)
+
+// evaluate scores a saved engine on held-out responses.
+func evaluate(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	statePath := flags.String("state", "permute.state", "saved engine to evaluate")
+	holdoutPath := flags.String("holdout", "", "CSV of held-out responses: user, then choices from most to least preferred")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *holdoutPath == "" {
+		return fmt.Errorf("evaluate: -holdout is required")
+	}
+
+	f, err := os.Open(*statePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	eng, err := collaborativepermute.Load(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *statePath, err)
+	}
+
+	h, err := os.Open(*holdoutPath)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	holdout, err := readResponses(h)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *holdoutPath, err)
+	}
+
+	printMetrics(out, eng.Evaluate(holdout))
+	return nil
+}
+
+// printMetrics reports the metrics of an evaluation, one per line.
+func printMetrics(out io.Writer, m collaborativepermute.Metrics) {
+	fmt.Fprintf(out, "accuracy:       %.3f\n", m.Accuracy)
+	fmt.Fprintf(out, "average margin: %.3f\n", m.AverageMargin)
+	fmt.Fprintf(out, "pairs:          %d\n", m.Pairs)
+	fmt.Fprintf(out, "skipped:        %d\n", m.Skipped)
+}
+
+// readResponses reads CSV rows of a user index followed by choice indices
+// from most to least preferred.
+func readResponses(r io.Reader) ([]collaborativepermute.Query, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]collaborativepermute.Query, 0, len(rows))
+	for i, row := range rows {
+		values := make([]int, len(row))
+		for j, field := range row {
+			if values[j], err = strconv.Atoi(field); err != nil {
+				return nil, fmt.Errorf("row %d: %v", i+1, err)
+			}
+		}
+		if len(values) < 3 {
+			return nil, fmt.Errorf("row %d: must have a user and two choices",
+				i+1)
+		}
+		responses = append(responses, collaborativepermute.Query{
+			User:    values[0],
+			Choices: values[1:],
+		})
+	}
+	return responses, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	rand.Seed(23)
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state")
+	holdout := filepath.Join(dir, "holdout.csv")
+	eng := collaborativepermute.NewEngine(2, 3)
+	for i := 0; i < 3; i++ {
+		eng.Respond(collaborativepermute.Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(collaborativepermute.Query{User: 1, Choices: []int{1, 2}})
+	}
+	if err := saveState(state, eng); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(holdout, []byte("# user,choices...\n0,0,1\n1,2,1\n1,0,5\n"), 0644)
+
+	var out bytes.Buffer
+	args := []string{"evaluate", "-state", state, "-holdout", holdout}
+	if err := run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "accuracy:       0.500") ||
+		!strings.Contains(out.String(), "skipped:        1") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	os.WriteFile(holdout, []byte("0,zero,1\n"), 0644)
+	if err := run(args, nil, &out); err == nil {
+		t.Fatalf("expected an error for a malformed holdout")
+	}
+}
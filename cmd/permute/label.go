@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// label asks the user to compare pairs of items until they quit.
+func label(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("label", flag.ContinueOnError)
+	flags.SetOutput(out)
+	itemsPath := flags.String("items", "", "file listing one item per line")
+	statePath := flags.String("state", "permute.state", "file to save answers to")
+	user := flags.Int("user", 0, "index of the person answering")
+	users := flags.Int("users", 1, "number of people, when creating the state")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *itemsPath == "" {
+		return fmt.Errorf("label: -items is required")
+	}
+
+	items, err := readItems(*itemsPath)
+	if err != nil {
+		return err
+	}
+	eng, err := loadState(*statePath, *users, len(items))
+	if err != nil {
+		return err
+	}
+	if _, err := eng.Rank(*user); err != nil {
+		return fmt.Errorf("label: %v", err)
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		q := eng.Generate(*user)
+		fmt.Fprintf(out, "\nWhich do you prefer?\n  1) %s\n  2) %s\n"+
+			"[1, 2, s to skip, q to quit]: ",
+			items[q.Choices[0]], items[q.Choices[1]])
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			break
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "q" {
+			break
+		}
+		switch answer {
+		case "2":
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		case "1":
+		default:
+			continue
+		}
+		if err := eng.Respond(q); err != nil {
+			return err
+		}
+		if err := saveState(*statePath, eng); err != nil {
+			return err
+		}
+	}
+
+	ranking, _ := eng.Rank(*user)
+	fmt.Fprintf(out, "\nRanking after %d answers:\n", len(eng.History))
+	for i, c := range ranking {
+		fmt.Fprintf(out, "%3d. %s\n", i+1, items[c])
+	}
+	return nil
+}
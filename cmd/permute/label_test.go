@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLabel(t *testing.T) {
+	rand.Seed(23)
+	dir := t.TempDir()
+	items := filepath.Join(dir, "items.txt")
+	state := filepath.Join(dir, "state")
+	os.WriteFile(items, []byte("apple\nbanana\n\ncherry\n"), 0644)
+	args := []string{"label", "-items", items, "-state", state}
+
+	var out bytes.Buffer
+	answers := strings.Repeat("1\n2\n", 2) + "s\nq\n"
+	if err := run(args, strings.NewReader(answers), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Ranking after 4 answers:") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := run(args, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Ranking after 4 answers:") {
+		t.Fatalf("expected answers to be saved, got:\n%s", out.String())
+	}
+}
+
+func TestLabelErrors(t *testing.T) {
+	dir := t.TempDir()
+	items := filepath.Join(dir, "items.txt")
+	os.WriteFile(items, []byte("apple\n"), 0644)
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"label"},
+		{"label", "-items", items},
+		{"label", "-items", filepath.Join(dir, "missing.txt")},
+	} {
+		if err := run(args, strings.NewReader(""), &bytes.Buffer{}); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
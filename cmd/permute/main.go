@@ -0,0 +1,82 @@
+// Command permute ranks items by asking people to compare them, two at a
+// time.
+//
+// Usage:
+//
+//	permute label -items items.txt [-state permute.state] [-user 0]
+//	permute simulate [-config experiment.json] [-noise logistic] [-json] ...
+//	permute evaluate -holdout holdout.csv [-state permute.state]
+//	permute train -log log.csv -items items.txt [-state permute.state] ...
+//	permute serve [-state permute.state] [-listen :8080] [-grpc :9090]
+//
+// The label subcommand reads one item per line and asks about pairs of them
+// in the terminal, choosing each question to learn as much as possible. Its
+// answers are saved to the state file after every question, so that labeling
+// can be resumed later, and the resulting ranking is printed on quitting.
+//
+// The simulate subcommand runs a synthetic experiment, as declared by
+// sim.Experiment, and reports how many questions the engine needed and how
+// accurate it became. Run permute simulate -h for its flags.
+//
+// The evaluate subcommand scores a saved engine on held-out responses: CSV
+// rows of a user index followed by choice indices from most to least
+// preferred.
+//
+// The train subcommand fits an engine offline to a log of comparisons, as
+// read by collaborativepermute.ImportLog, in which users are named by index
+// and choices by the lines of -items. It loads the state file, or creates one
+// for -users people if there is none, adds the log to the responses already
+// learned, and takes -passes full passes over them all after the usual online
+// replay, as for nightly retraining. The last -holdout fraction of the log is
+// kept out of training and used to report the engine's accuracy, and the
+// result is written back to the state file.
+//
+// The serve subcommand hosts the engine saved in the state file over REST (see
+// package httpapi) and, if -grpc is given, gRPC (see package grpcapi). The
+// engine is saved every -checkpoint interval and again on shutdown. To start
+// from scratch, pass -users (at least 1) and -choices (at least 2).
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Type command runs a subcommand with the given arguments.
+type command func(args []string, in io.Reader, out io.Writer) error
+
+var commands = map[string]command{
+	"label":    label,
+	"simulate": simulate,
+	"evaluate": evaluate,
+	"serve":    serve,
+	"train":    train,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "permute: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, in io.Reader, out io.Writer) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	usage := fmt.Errorf("usage: permute <%s> [flags]", strings.Join(names, "|"))
+
+	if len(args) == 0 {
+		return usage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return usage
+	}
+	return cmd(args[1:], in, out)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/grpcapi"
+	"github.com/fatlotus/collaborativepermute/grpcapi/permutepb"
+	"github.com/fatlotus/collaborativepermute/httpapi"
+	"google.golang.org/grpc"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Struct service hosts an engine over REST and, optionally, gRPC, saving it
+// to a state file periodically and on shutdown.
+type service struct {
+	out       io.Writer
+	statePath string
+	every     time.Duration
+
+	mu     sync.Mutex
+	engine *collaborativepermute.Engine
+	saved  int
+
+	httpListener, grpcListener net.Listener
+}
+
+// serve hosts the engine until interrupted.
+func serve(args []string, in io.Reader, out io.Writer) error {
+	s, err := newService(args, out)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt,
+		syscall.SIGTERM)
+	defer stop()
+	return s.run(ctx)
+}
+
+func newService(args []string, out io.Writer) (*service, error) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(out)
+	statePath := flags.String("state", "permute.state", "file to restore the engine from and save it to")
+	listen := flags.String("listen", ":8080", "address to serve the REST API on")
+	grpcListen := flags.String("grpc", "", "address to serve the gRPC API on, if any")
+	every := flags.Duration("checkpoint", time.Minute, "how often to save the engine")
+	users := flags.Int("users", 0, "number of users, when creating the state")
+	choices := flags.Int("choices", 0, "number of choices, when creating the state")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if *every <= 0 {
+		return nil, fmt.Errorf("serve: must have -checkpoint [%v] > 0", *every)
+	}
+	if _, err := os.Stat(*statePath); os.IsNotExist(err) &&
+		(*users < 1 || *choices < 2) {
+		return nil, fmt.Errorf("serve: %s does not exist, so -users must be at least 1 and -choices at least 2",
+			*statePath)
+	}
+
+	eng, err := loadState(*statePath, *users, *choices)
+	if err != nil {
+		return nil, err
+	}
+	s := &service{
+		out:       out,
+		statePath: *statePath,
+		every:     *every,
+		engine:    eng,
+		saved:     -1,
+	}
+	if s.httpListener, err = net.Listen("tcp", *listen); err != nil {
+		return nil, err
+	}
+	if *grpcListen != "" {
+		if s.grpcListener, err = net.Listen("tcp", *grpcListen); err != nil {
+			s.httpListener.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// run serves until ctx is done, then shuts down and saves the engine.
+func (s *service) run(ctx context.Context) error {
+	api := httpapi.New(s.engine)
+	api.Lock = &s.mu
+	httpServer := &http.Server{Handler: api}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- httpServer.Serve(s.httpListener)
+	}()
+	fmt.Fprintf(s.out, "serving REST on %v\n", s.httpListener.Addr())
+
+	var grpcServer *grpc.Server
+	if s.grpcListener != nil {
+		rpc := grpcapi.New(s.engine)
+		rpc.Lock = &s.mu
+		grpcServer = grpc.NewServer()
+		permutepb.RegisterPermuteServer(grpcServer, rpc)
+		go func() {
+			errs <- grpcServer.Serve(s.grpcListener)
+		}()
+		fmt.Fprintf(s.out, "serving gRPC on %v\n", s.grpcListener.Addr())
+	}
+
+	ticker := time.NewTicker(s.every)
+	defer ticker.Stop()
+	var err error
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.checkpoint(); err != nil {
+				fmt.Fprintf(s.out, "checkpoint failed: %v\n", err)
+			}
+		case err = <-errs:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	httpServer.Shutdown(shutdown)
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return errors.Join(err, s.checkpoint())
+}
+
+// checkpoint saves the engine, unless it has not changed since it was last
+// saved.
+func (s *service) checkpoint() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.engine.History) == s.saved {
+		return nil
+	}
+	if err := saveState(s.statePath, s.engine); err != nil {
+		return err
+	}
+	s.saved = len(s.engine.History)
+	return nil
+}
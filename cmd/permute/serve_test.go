@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	rand.Seed(23)
+	state := filepath.Join(t.TempDir(), "state")
+	var out bytes.Buffer
+	s, err := newService([]string{"-state", state, "-listen", "127.0.0.1:0",
+		"-users", "2", "-choices", "3", "-checkpoint", "10ms"}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.run(ctx) }()
+
+	url := "http://" + s.httpListener.Addr().String() + "/answers"
+	resp, err := http.Post(url, "application/json",
+		strings.NewReader(`{"user": 1, "choices": [2, 0]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	// Wait for a periodic checkpoint before shutting down.
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(state); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := os.Stat(state); err != nil {
+		t.Fatalf("expected a checkpoint while serving: %v", err)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := os.Open(state)
+	defer f.Close()
+	eng, err := collaborativepermute.Load(f)
+	if err != nil || len(eng.History) != 1 {
+		t.Fatalf("expected the answer to be saved, got %v", err)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state")
+	for _, args := range [][]string{
+		{"-state", state},
+		{"-state", state, "-choices", "3"},
+		{"-state", state, "-users", "2", "-choices", "3", "-checkpoint", "0s"},
+		{"-state", state, "-users", "2", "-choices", "3", "-listen", "bogus"},
+	} {
+		if _, err := newService(args, &bytes.Buffer{}); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
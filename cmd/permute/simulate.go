@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute/sim"
+	"io"
+	"math"
+	"os"
+)
+
+// simulate runs a synthetic experiment and reports its summary.
+func simulate(args []string, in io.Reader, out io.Writer) error {
+	var e sim.Experiment
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	config := flags.String("config", "", "JSON file declaring the experiment; flags override it")
+	asJSON := flags.Bool("json", false, "print the summary as JSON")
+	flags.StringVar(&e.Name, "name", "", "name of the experiment")
+	flags.IntVar(&e.Users, "users", 10, "number of synthetic users")
+	flags.IntVar(&e.Choices, "choices", 10, "number of choices")
+	flags.IntVar(&e.Rank, "rank", 2, "rank of the true preferences")
+	flags.StringVar(&e.Noise, "noise", "perfect", "oracle: perfect, logistic, error, lazy, or random")
+	flags.Float64Var(&e.NoiseLevel, "noise-level", 0, "scale of logistic noise (> 0), or rate of errors or lazy answers (0 to 1)")
+	flags.StringVar(&e.Strategy, "strategy", "uncertainty", "query strategy: uncertainty or uniform")
+	flags.Float64Var(&e.Lambda, "lambda", 0, "regularization strength, if positive")
+	flags.IntVar(&e.MaxQuestions, "max-questions", 1000, "questions to ask at most")
+	flags.Float64Var(&e.Target, "target", 0.9, "accuracy at which to stop")
+	flags.Int64Var(&e.Seed, "seed", 1, "seed of the first repetition")
+	flags.IntVar(&e.Repetitions, "repetitions", 5, "number of repetitions")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *config != "" {
+		data, err := os.ReadFile(*config)
+		if err != nil {
+			return err
+		}
+		// Flags given explicitly take precedence over the file.
+		explicit := make(map[string]string)
+		flags.Visit(func(f *flag.Flag) {
+			explicit[f.Name] = f.Value.String()
+		})
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("%s: %v", *config, err)
+		}
+		for name, value := range explicit {
+			flags.Set(name, value)
+		}
+	}
+
+	summary, err := sim.RunExperiment(e)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+	fmt.Fprintf(out, "questions: %.1f ± %.1f\n", summary.Questions.Mean,
+		math.Sqrt(summary.Questions.Variance))
+	fmt.Fprintf(out, "accuracy:  %.3f ± %.3f\n", summary.Accuracy.Mean,
+		math.Sqrt(summary.Accuracy.Variance))
+	fmt.Fprintf(out, "regret:    %.1f ± %.1f\n", summary.Regret.Mean,
+		math.Sqrt(summary.Regret.Variance))
+	fmt.Fprintf(out, "converged: %d of %d\n", summary.Converged,
+		e.Repetitions)
+	return nil
+}
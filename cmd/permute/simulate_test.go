@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute/sim"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	var out bytes.Buffer
+	args := []string{"simulate", "-users", "3", "-choices", "4", "-rank", "1",
+		"-repetitions", "2", "-max-questions", "50", "-noise", "error",
+		"-noise-level", "0.1"}
+	if err := run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "converged: ") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	config := filepath.Join(t.TempDir(), "experiment.json")
+	os.WriteFile(config, []byte(`{"users": 2, "choices": 3, "rank": 1,
+		"strategy": "uniform", "repetitions": 3}`), 0644)
+	out.Reset()
+	args = []string{"simulate", "-config", config, "-choices", "5", "-json"}
+	if err := run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	var summary sim.Summary
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	e := summary.Experiment
+	if e.Users != 2 || e.Choices != 5 || e.Strategy != "uniform" ||
+		e.Repetitions != 3 {
+		t.Fatalf("expected flags to override the config, got %+v", e)
+	}
+
+	if err := run([]string{"simulate", "-noise", "loud"}, nil, &out); err == nil {
+		t.Fatalf("expected an error for an unknown noise")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readItems reads the non-blank lines of a file as item names.
+func readItems(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if item := strings.TrimSpace(scanner.Text()); item != "" {
+			items = append(items, item)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) < 2 {
+		return nil, fmt.Errorf("%s: must list at least two items", path)
+	}
+	return items, nil
+}
+
+// loadState restores the engine saved at path, or creates a new one with the
+// given size if there is none.
+func loadState(path string, users, choices int) (*collaborativepermute.Engine, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return collaborativepermute.NewEngineSafe(users, choices)
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	eng, err := collaborativepermute.Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if eng.X.Shape[1] != choices {
+		return nil, fmt.Errorf("%s: has %d items, not %d", path,
+			eng.X.Shape[1], choices)
+	}
+	return eng, nil
+}
+
+// saveState writes the engine to path, replacing it only once the new state
+// has been written completely.
+func saveState(path string, eng *collaborativepermute.Engine) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := eng.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
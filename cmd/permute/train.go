@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"os"
+	"strconv"
+)
+
+// train fits an engine offline to a log of responses and saves it.
+func train(args []string, in io.Reader, out io.Writer) error {
+	flags := flag.NewFlagSet("train", flag.ContinueOnError)
+	flags.SetOutput(out)
+	logPath := flags.String("log", "", "log of comparisons, as read by collaborativepermute.ImportLog")
+	format := flags.String("format", "csv", "format of the log: csv or jsonl")
+	itemsPath := flags.String("items", "", "file listing one item per line")
+	statePath := flags.String("state", "permute.state", "engine to continue from and save to")
+	users := flags.Int("users", 1, "number of people, when creating the state")
+	passes := flags.Int("passes", 10, "full passes over the history after the online replay")
+	holdout := flags.Float64("holdout", 0, "fraction of the log, from its end, to evaluate on instead of training")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *logPath == "" || *itemsPath == "" {
+		return fmt.Errorf("train: -log and -items are required")
+	}
+	if !(*holdout >= 0 && *holdout < 1) {
+		return fmt.Errorf("train: must have 0 <= -holdout [%v] < 1", *holdout)
+	}
+	var logFormat collaborativepermute.Format
+	switch *format {
+	case "csv":
+		logFormat = collaborativepermute.CSV
+	case "jsonl":
+		logFormat = collaborativepermute.JSONLines
+	default:
+		return fmt.Errorf("train: unknown -format %q", *format)
+	}
+
+	items, err := readItems(*itemsPath)
+	if err != nil {
+		return err
+	}
+	eng, err := loadState(*statePath, *users, len(items))
+	if err != nil {
+		return err
+	}
+	named, err := nameEngine(eng, items)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*logPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	before, known := len(eng.History), eng.X.Shape[0]
+	imported, err := collaborativepermute.ImportLog(named, f, logFormat)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *logPath, err)
+	}
+	if ids := named.Users(); len(ids) > known {
+		return fmt.Errorf("%s: user %q must be an index below %d", *logPath,
+			ids[known], known)
+	}
+	if ids := named.Choices(); len(ids) > len(items) {
+		return fmt.Errorf("%s: item %q is not listed in %s", *logPath,
+			ids[len(items)], *itemsPath)
+	}
+	split := before + imported - int(*holdout*float64(imported))
+	history, held := eng.History[:split], eng.History[split:]
+	if split == before {
+		return fmt.Errorf("%s: must have at least one response to train on",
+			*logPath)
+	}
+
+	losses, err := eng.Train(history, *passes)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *logPath, err)
+	}
+	for i, loss := range losses {
+		fmt.Fprintf(out, "pass %d: loss %.4f\n", i, loss)
+	}
+	if len(held) > 0 {
+		printMetrics(out, eng.Evaluate(held))
+	}
+	return saveState(*statePath, eng)
+}
+
+// nameEngine wraps eng so that users are named by their index and choices by
+// the items listed for them.
+func nameEngine(eng *collaborativepermute.Engine,
+	items []string) (*collaborativepermute.NamedEngine, error) {
+	users := make([]string, eng.X.Shape[0])
+	for u := range users {
+		users[u] = strconv.Itoa(u)
+	}
+	named, err := collaborativepermute.NewNamedEngine(users, items)
+	if err != nil {
+		return nil, err
+	}
+	named.Engine = eng
+	return named, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrain(t *testing.T) {
+	rand.Seed(23)
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state")
+	items := filepath.Join(dir, "items.txt")
+	log := filepath.Join(dir, "log.csv")
+	os.WriteFile(items, []byte("apple\nbanana\ncherry\n"), 0644)
+	rows := "user,winner,loser\n"
+	for i := 0; i < 20; i++ {
+		rows += "0,apple,banana\n0,banana,cherry\n1,cherry,banana\n"
+	}
+	os.WriteFile(log, []byte(rows), 0644)
+
+	var out bytes.Buffer
+	args := []string{"train", "-state", state, "-log", log, "-items", items,
+		"-users", "2", "-passes", "3", "-holdout", "0.1"}
+	if err := run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "pass 3: loss") ||
+		!strings.Contains(out.String(), "pairs:          6") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+
+	f, err := os.Open(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eng, err := collaborativepermute.Load(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng.X.Shape[0] != 2 || eng.X.Shape[1] != 3 || len(eng.History) != 54 {
+		t.Fatalf("expected a 2x3 engine trained on 54 responses, got %v, %d",
+			eng.X.Shape, len(eng.History))
+	}
+
+	// A second run adds to the responses already learned.
+	out.Reset()
+	if err := run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if eng, _ = loadState(state, 2, 3); len(eng.History) != 108 {
+		t.Fatalf("expected the log to be added to the history, got %d",
+			len(eng.History))
+	}
+
+	for _, bad := range []string{
+		"user,winner,loser\n2,apple,banana\n",
+		"user,winner,loser\n0,apple,durian\n",
+		"0,apple,banana\n",
+	} {
+		os.WriteFile(log, []byte(bad), 0644)
+		if err := run(args, nil, &out); err == nil {
+			t.Fatalf("expected an error for the log %q", bad)
+		}
+	}
+	if eng, _ = loadState(state, 2, 3); len(eng.History) != 108 {
+		t.Fatalf("expected a failed run to leave the state unchanged")
+	}
+	if err := run([]string{"train"}, nil, &out); err == nil {
+		t.Fatalf("expected an error without -log")
+	}
+}
@@ -0,0 +1,103 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Type ColdStart determines how the preferences of newly added users are
+// initialized.
+type ColdStart int
+
+const (
+	// New users start out indifferent to every choice.
+	ZeroStart ColdStart = iota
+
+	// New users start out with the mean preferences of all existing users.
+	MeanStart
+)
+
+// WithColdStart sets how AddUser initializes new users.
+func WithColdStart(c ColdStart) Option {
+	return func(p *Engine) error {
+		p.ColdStart = c
+		return nil
+	}
+}
+
+// Method AddUserFrom appends a new user whose preferences are initialized from
+// the k existing users that best agree with the given seed responses. The
+// seeds are then recorded as the new user's first responses; their User field
+// is ignored.
+func (p *Engine) AddUserFrom(seeds []Query, k int) (int, error) {
+	if k <= 0 {
+		return 0, fmt.Errorf("must have k [%d] > 0", k)
+	}
+	for _, seed := range seeds {
+		if err := p.validateChoices(seed.Choices); err != nil {
+			return 0, err
+		}
+	}
+
+	existing := p.X.Shape[0]
+	agreement := make([]int, existing)
+	neighbors := make([]int, existing)
+	for v := range neighbors {
+		neighbors[v] = v
+		for _, seed := range seeds {
+			for _, pair := range p.preferences(seed) {
+				if p.Score(v, pair[0]) > p.Score(v, pair[1]) {
+					agreement[v]++
+				}
+			}
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return agreement[neighbors[i]] > agreement[neighbors[j]]
+	})
+	if k < existing {
+		neighbors = neighbors[:k]
+	}
+
+	user := p.AddUser()
+	if len(neighbors) > 0 {
+		p.initUser(user, p.averageUsers(neighbors))
+	}
+	for _, seed := range seeds {
+		seed.User = user
+		p.History = append(p.History, seed)
+	}
+	if len(seeds) > 0 {
+		p.update(p.History)
+	}
+	return user, nil
+}
+
+// meanUser returns the average preference row of the first n users.
+func (p *Engine) meanUser(n int) []float64 {
+	users := make([]int, n)
+	for i := range users {
+		users[i] = i
+	}
+	return p.averageUsers(users)
+}
+
+func (p *Engine) averageUsers(users []int) []float64 {
+	row := make([]float64, p.X.Shape[1])
+	for _, u := range users {
+		for j := range row {
+			row[j] += *p.X.I(u, j) / float64(len(users))
+		}
+	}
+	return row
+}
+
+// initUser overwrites a user's preferences in every belief matrix, so that
+// the accelerated scheme carries no momentum for them.
+func (p *Engine) initUser(user int, row []float64) {
+	for j, value := range row {
+		*p.X.I(user, j) = value
+		*p.Xp.I(user, j) = value
+		*p.Z.I(user, j) = value
+	}
+}
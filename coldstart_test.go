@@ -0,0 +1,44 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestColdStartMean(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3, WithColdStart(MeanStart))
+	for i := 0; i < 10; i++ {
+		eng.Respond(Query{User: i % 2, Choices: []int{2, 0}})
+	}
+
+	u := eng.AddUser()
+	ranking, _ := eng.Rank(u)
+	if ranking[0] != 2 || ranking[2] != 0 {
+		t.Fatalf("expected new user to inherit the consensus, got %v", ranking)
+	}
+}
+
+func TestAddUserFrom(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(4, 3)
+	for i := 0; i < 40; i++ {
+		if i%2 == 0 {
+			eng.Respond(Query{User: i % 4, Choices: []int{0, 1}})
+		} else {
+			eng.Respond(Query{User: i % 4, Choices: []int{2, 1}})
+		}
+	}
+
+	u, err := eng.AddUserFrom([]Query{{Choices: []int{2, 1}}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranking, _ := eng.Rank(u)
+	if ranking[0] != 2 {
+		t.Fatalf("expected new user to resemble users 1 and 3, got %v", ranking)
+	}
+	if _, err := eng.AddUserFrom([]Query{{Choices: []int{0, 7}}}, 2); err == nil {
+		t.Fatalf("expected an error for an invalid seed")
+	}
+}
@@ -0,0 +1,96 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// WithContext lets responses carry a Context vector of up to d features, such
+// as one-hot encodings of the device, time of day, or occasion. The engine
+// learns a per-choice linear function of the context, C, that offsets the
+// scores of every user in that context, so that "which dinner do you prefer"
+// can have different answers on weekdays and weekends.
+func WithContext(d int) Option {
+	return func(p *Engine) error {
+		if d <= 0 {
+			return fmt.Errorf("must have d [%d] > 0", d)
+		}
+		p.C = gauss.Zero(d, p.X.Shape[1])
+		return nil
+	}
+}
+
+// Method ScoreIn returns the engine's belief about how strongly the given user
+// prefers the given choice in the given context: Score plus the learned
+// context offset. A nil context gives the same result as Score. Unlike Score,
+// it checks its arguments, returning an error if the user or choice is out of
+// range or the context is longer than the engine allows.
+func (p *Engine) ScoreIn(user, choice int, context []float64) (float64, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return 0, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if choice < 0 || choice >= p.X.Shape[1] {
+		return 0, fmt.Errorf("must have 0 <= choice [%d] < %d",
+			choice, p.X.Shape[1])
+	}
+	if err := p.validateContext(context); err != nil {
+		return 0, err
+	}
+	return p.scoreIn(user, choice, context), nil
+}
+
+// scoreIn is ScoreIn for arguments that are known to be valid.
+func (p *Engine) scoreIn(user, choice int, context []float64) float64 {
+	score := p.Score(user, choice)
+	if p.C.Shape != nil {
+		for k, f := range context {
+			score += f * *p.C.I(k, choice)
+		}
+	}
+	return score
+}
+
+// Method RankIn returns the choices ordered from most to least preferred by
+// the given user in the given context.
+func (p *Engine) RankIn(user int, context []float64) ([]int, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if err := p.validateContext(context); err != nil {
+		return nil, err
+	}
+	return rankBy(p.X.Shape[1], func(c int) float64 {
+		return p.scoreIn(user, c, context)
+	}), nil
+}
+
+func (p *Engine) validateContext(context []float64) error {
+	if len(context) == 0 {
+		return nil
+	}
+	if p.C.Shape == nil {
+		return fmt.Errorf("engine was not configured WithContext")
+	}
+	if len(context) > p.C.Shape[0] {
+		return fmt.Errorf("must have len(context) [%d] <= %d",
+			len(context), p.C.Shape[0])
+	}
+	return nil
+}
+
+// updateContextWeights takes a gradient step of size nu on C, given the
+// responses, with a ridge penalty of Lambda.
+func (p *Engine) updateContextWeights(samps []Query, nu float64) {
+	if p.C.Shape == nil {
+		return
+	}
+	gradient := clone(p.C).Scale(p.Lambda)
+	p.responseGradients(samps, func(q Query, choice int, d float64) {
+		for k, f := range q.Context {
+			*gradient.I(k, choice) += d * f
+		}
+	})
+	p.C = gauss.Sum(p.C, gradient.Scale(-nu))
+}
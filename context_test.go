@@ -0,0 +1,48 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(3, 2, WithContext(2))
+	weekday, weekend := []float64{1, 0}, []float64{0, 1}
+
+	// Everyone prefers choice 0 on weekdays and choice 1 on weekends.
+	for i := 0; i < 40; i++ {
+		eng.Respond(Query{User: i % 3, Choices: []int{0, 1}, Context: weekday})
+		eng.Respond(Query{User: i % 3, Choices: []int{1, 0}, Context: weekend})
+	}
+
+	for u := 0; u < 3; u++ {
+		first, _ := eng.RankIn(u, weekday)
+		second, _ := eng.RankIn(u, weekend)
+		if first[0] != 0 || second[0] != 1 {
+			t.Fatalf("user %d: expected context to flip the ranking, got %v and %v",
+				u, first, second)
+		}
+	}
+
+	wide := Query{Choices: []int{0, 1}, Context: []float64{1, 0, 0}}
+	if err := eng.Respond(wide); err == nil {
+		t.Fatalf("expected an error for an oversized context")
+	}
+	if s, err := eng.ScoreIn(0, 1, weekend); err != nil || s <= eng.Score(0, 1) {
+		t.Fatalf("expected the weekend to favor choice 1, got %v, %v", s, err)
+	}
+	for _, args := range [][]int{{3, 0}, {0, 2}, {-1, 0}} {
+		if _, err := eng.ScoreIn(args[0], args[1], nil); err == nil {
+			t.Fatalf("expected an error for user %d, choice %d",
+				args[0], args[1])
+		}
+	}
+	if _, err := eng.ScoreIn(0, 0, wide.Context); err == nil {
+		t.Fatalf("expected an error for an oversized context")
+	}
+	plain := NewEngine(1, 2)
+	if err := plain.Respond(Query{Choices: []int{0, 1}, Context: weekday}); err == nil {
+		t.Fatalf("expected an error for context without WithContext")
+	}
+}
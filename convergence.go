@@ -0,0 +1,159 @@
+package collaborativepermute
+
+import (
+	"math"
+)
+
+// Type Criterion judges whether an engine has converged, from a sequence of
+// observations of it. Criteria keep state between observations, so each
+// should only ever observe a single engine.
+type Criterion interface {
+	// Observe is called after each update, and reports whether the engine
+	// has converged by this criterion.
+	Observe(p *Engine) bool
+}
+
+// Struct ConvergenceDetector decides when an engine has converged, so that
+// callers can stop asking questions. It observes the engine after every
+// update and has converged once all of its Criteria agree.
+type ConvergenceDetector struct {
+	Criteria []Criterion
+
+	converged bool
+}
+
+// NewConvergenceDetector returns a detector requiring all of the criteria.
+func NewConvergenceDetector(criteria ...Criterion) *ConvergenceDetector {
+	return &ConvergenceDetector{Criteria: criteria}
+}
+
+// Method Observe passes the engine to every criterion, and reports whether
+// all of them consider it converged.
+func (d *ConvergenceDetector) Observe(p *Engine) bool {
+	d.converged = len(d.Criteria) > 0
+	for _, c := range d.Criteria {
+		if !c.Observe(p) {
+			d.converged = false
+		}
+	}
+	return d.converged
+}
+
+// Method Converged reports the result of the latest Observe.
+func (d *ConvergenceDetector) Converged() bool {
+	return d.converged
+}
+
+// WithConvergence has the engine pass itself to the detector after every
+// response; see Converged.
+func WithConvergence(d *ConvergenceDetector) Option {
+	return func(p *Engine) error {
+		p.Convergence = d
+		return nil
+	}
+}
+
+// Method Converged reports whether the engine's ConvergenceDetector considers
+// it converged, or false if it has none.
+func (p *Engine) Converged() bool {
+	return p.Convergence != nil && p.Convergence.Converged()
+}
+
+type deltaNorm struct {
+	tol  float64
+	last []float64
+}
+
+func (c *deltaNorm) Observe(p *Engine) bool {
+	comparable := len(c.last) == len(p.X.Data) && c.last != nil
+	diff, norm := 0.0, 0.0
+	for i, v := range p.X.Data {
+		if comparable {
+			diff += (v - c.last[i]) * (v - c.last[i])
+		}
+		norm += v * v
+	}
+	c.last = append(c.last[:0], p.X.Data...)
+	return comparable &&
+		math.Sqrt(diff) <= c.tol*math.Max(math.Sqrt(norm), 1)
+}
+
+// DeltaNorm returns a Criterion satisfied once an update changes X by at most
+// tol relative to its size, in Frobenius norm.
+func DeltaNorm(tol float64) Criterion {
+	return &deltaNorm{tol: tol}
+}
+
+type lossPlateau struct {
+	window int
+	tol    float64
+	losses []float64
+}
+
+func (c *lossPlateau) Observe(p *Engine) bool {
+	loss := 0.0
+	if len(p.History) > 0 {
+		loss = p.loss(p.History)
+	}
+	c.losses = append(c.losses, loss)
+	if len(c.losses) > c.window {
+		c.losses = c.losses[1:]
+	}
+	if len(c.losses) < c.window {
+		return false
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, l := range c.losses {
+		min, max = math.Min(min, l), math.Max(max, l)
+	}
+	return max-min <= c.tol
+}
+
+// LossPlateau returns a Criterion satisfied once the loss over History has
+// varied by at most tol over the last window observations.
+func LossPlateau(window int, tol float64) Criterion {
+	return &lossPlateau{window: window, tol: tol}
+}
+
+type rankStability struct {
+	window   int
+	stable   int
+	rankings [][]int
+}
+
+func (c *rankStability) Observe(p *Engine) bool {
+	rankings := make([][]int, p.X.Shape[0])
+	for u := range rankings {
+		rankings[u], _ = p.Rank(u)
+	}
+	if sameRankings(rankings, c.rankings) {
+		c.stable++
+	} else {
+		c.stable = 0
+	}
+	c.rankings = rankings
+	return c.stable >= c.window
+}
+
+// RankStability returns a Criterion satisfied once no user's ranking has
+// changed over the last window observations.
+func RankStability(window int) Criterion {
+	return &rankStability{window: window}
+}
+
+func sameRankings(a, b [][]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for u := range a {
+		if len(a[u]) != len(b[u]) {
+			return false
+		}
+		for i := range a[u] {
+			if a[u][i] != b[u][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
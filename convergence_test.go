@@ -0,0 +1,67 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestConvergenceDetector(t *testing.T) {
+	rand.Seed(23)
+	detector := NewConvergenceDetector(RankStability(10), LossPlateau(10, 0.01))
+	eng := NewEngine(3, 4, WithConvergence(detector))
+
+	asked := 0
+	for !eng.Converged() && asked < 500 {
+		q := eng.Generate(-1)
+		if q.Choices[0] > q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		eng.Respond(q)
+		asked++
+	}
+	if !eng.Converged() || asked < 10 {
+		t.Fatalf("expected to converge after a while, asked %d", asked)
+	}
+	for u := 0; u < 3; u++ {
+		if ranking, _ := eng.Rank(u); ranking[0] != 0 {
+			t.Fatalf("user %d: expected choice 0 first, got %v", u, ranking)
+		}
+	}
+
+	delta := DeltaNorm(0.01)
+	if delta.Observe(eng) {
+		t.Fatalf("expected the first observation not to converge")
+	}
+	if !delta.Observe(eng) {
+		t.Fatalf("expected an unchanged engine to converge")
+	}
+	if NewConvergenceDetector().Observe(eng) {
+		t.Fatalf("expected a detector without criteria never to converge")
+	}
+}
+
+// countingCriterion counts its observations and always considers the engine
+// converged.
+type countingCriterion struct{ observed int }
+
+func (c *countingCriterion) Observe(p *Engine) bool {
+	c.observed++
+	return true
+}
+
+func TestConvergenceClone(t *testing.T) {
+	rand.Seed(23)
+	criterion := &countingCriterion{}
+	eng := NewEngine(2, 3, WithConvergence(NewConvergenceDetector(criterion)))
+	eng.Respond(Query{User: 0, Choices: []int{1, 0}})
+
+	c := eng.clone()
+	if !c.Converged() {
+		t.Fatalf("expected the clone to report the original's convergence")
+	}
+	c.Respond(Query{User: 1, Choices: []int{2, 0}})
+	if criterion.observed != 1 {
+		t.Fatalf("expected the clone not to observe itself, got %d "+
+			"observations", criterion.observed)
+	}
+}
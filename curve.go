@@ -0,0 +1,57 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct CurvePoint describes what the engine learned from one of a user's
+// answers.
+type CurvePoint struct {
+	// Answers is the number of the user's answers so far, including this one.
+	Answers int
+
+	// Predicted is the fraction of the pairs implied by this answer that the
+	// engine already predicted correctly before seeing it.
+	Predicted float64
+
+	// Changed is the number of pairs of choices whose order in the user's
+	// ranking changed because of this answer.
+	Changed int
+}
+
+// Method LearningCurve replays the History from scratch and returns one
+// CurvePoint for each of the user's answers, in order. As the engine learns a
+// user, Predicted should approach one and Changed fall to zero; where each
+// levels off suggests how many questions a user like this one needs. The
+// engine itself is left unchanged.
+func (p *Engine) LearningCurve(user int) ([]CurvePoint, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	replay := p.clone()
+	replay.History = nil
+	replay.Refit()
+
+	var curve []CurvePoint
+	for _, q := range p.History {
+		if q.User != user {
+			replay.History = append(replay.History, q)
+			replay.update(replay.History)
+			continue
+		}
+		point := CurvePoint{Answers: len(curve) + 1}
+		if m := replay.Evaluate([]Query{q}); m.Pairs > 0 {
+			point.Predicted = m.Accuracy
+		} else {
+			point.Predicted = 1
+		}
+		before, _ := replay.Rank(user)
+		replay.History = append(replay.History, q)
+		replay.update(replay.History)
+		after, _ := replay.Rank(user)
+		point.Changed, _ = Discordant(after, before)
+		curve = append(curve, point)
+	}
+	return curve, nil
+}
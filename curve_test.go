@@ -0,0 +1,45 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLearningCurve(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 5)
+	for i := 0; i < 60; i++ {
+		q := eng.Generate(i % 2)
+		if q.Choices[0] > q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		eng.Respond(q)
+	}
+	before := append([]float64(nil), eng.X.Data...)
+
+	curve, err := eng.LearningCurve(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(curve) != 30 || curve[29].Answers != 30 {
+		t.Fatalf("expected a point per answer, got %d", len(curve))
+	}
+	early, late := 0, 0
+	for i := 0; i < 10; i++ {
+		early += curve[i].Changed
+		late += curve[20+i].Changed
+	}
+	if late >= early {
+		t.Fatalf("expected the ranking to settle, got %d then %d changes",
+			early, late)
+	}
+	for i, v := range eng.X.Data {
+		if v != before[i] {
+			t.Fatalf("expected the engine to be unchanged")
+		}
+	}
+
+	if _, err := eng.LearningCurve(2); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+}
@@ -0,0 +1,41 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// WithTimeDecay discounts older responses exponentially, so that a response
+// given halfLife before the newest one counts half as much in the loss. This
+// lets the engine follow preferences that drift over time. Ages are measured
+// from the newest response rather than the wall clock, so that Refit
+// reproduces the same model.
+func WithTimeDecay(halfLife time.Duration) Option {
+	return func(p *Engine) error {
+		if halfLife <= 0 {
+			return fmt.Errorf("must have halfLife [%v] > 0", halfLife)
+		}
+		p.HalfLife = halfLife
+		return nil
+	}
+}
+
+// decay returns the weight of a response of the given age.
+func decay(age, halfLife time.Duration) float64 {
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// newest returns the latest Time among the responses.
+func newest(samps []Query) time.Time {
+	var result time.Time
+	for _, q := range samps {
+		if q.Time.After(result) {
+			result = q.Time
+		}
+	}
+	return result
+}
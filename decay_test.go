@@ -0,0 +1,39 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTimeDecay(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(1, 2, WithTimeDecay(time.Hour))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The user preferred 0 for a long time, but has recently changed their
+	// mind.
+	for i := 0; i < 30; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		eng.Respond(Query{Choices: []int{0, 1}, Time: at})
+	}
+	for i := 0; i < 15; i++ {
+		at := start.Add(24*time.Hour + time.Duration(i)*time.Minute)
+		eng.Respond(Query{Choices: []int{1, 0}, Time: at})
+	}
+	if eng.Score(0, 1) <= eng.Score(0, 0) {
+		t.Fatalf("expected recent responses to dominate, got %v", eng.X.Data)
+	}
+
+	if w := decay(2*time.Hour, time.Hour); w != 0.25 {
+		t.Fatalf("expected two half-lives to quarter the weight, got %v", w)
+	}
+	if _, err := NewEngineSafe(1, 2, WithTimeDecay(0)); err == nil {
+		t.Fatalf("expected an error for a zero half-life")
+	}
+
+	eng.Respond(Query{Choices: []int{1, 0}})
+	if eng.History[len(eng.History)-1].Time.IsZero() {
+		t.Fatalf("expected Respond to record the time")
+	}
+}
@@ -0,0 +1,142 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Struct Diagnosis summarizes what the engine knows about a user, for
+// answering "why are this user's recommendations bad?"
+type Diagnosis struct {
+	// Answers is the number of the user's responses in History.
+	Answers int
+
+	// Agreement is the fraction of the pairs implied by the user's responses
+	// that the model currently reproduces. Low agreement means the user's
+	// answers could not be reconciled with each other or with other users.
+	Agreement float64
+
+	// Consistency measures the user's cycles and disagreement with others.
+	Consistency
+
+	// RecentChanges is the mean number of pairs in the user's ranking that
+	// changed with each of their last few answers; if it is still high, the
+	// engine has not settled on the user's preferences yet.
+	RecentChanges float64
+
+	// Uncertain lists the pairs of choices whose order for the user is most
+	// uncertain, that is, with the closest scores, most uncertain first.
+	// Asking about them would help the most.
+	Uncertain [][2]int
+}
+
+// Method Diagnose returns a Diagnosis for the user.
+func (p *Engine) Diagnose(user int) (Diagnosis, error) {
+	var d Diagnosis
+	var err error
+	if d.Consistency, err = p.Consistency(user); err != nil {
+		return d, err
+	}
+
+	var own []Query
+	for _, q := range p.History {
+		if q.User == user {
+			own = append(own, q)
+		}
+	}
+	d.Answers = len(own)
+	d.Agreement = 1
+	if len(own) > 0 {
+		d.Agreement = p.Evaluate(own).Accuracy
+	}
+
+	curve, _ := p.LearningCurve(user)
+	const recent = 5
+	if len(curve) > recent {
+		curve = curve[len(curve)-recent:]
+	}
+	for _, point := range curve {
+		d.RecentChanges += float64(point.Changed) / float64(len(curve))
+	}
+
+	type gap struct {
+		pair [2]int
+		size float64
+	}
+	var gaps []gap
+	for a := 0; a < p.X.Shape[1]; a++ {
+		for b := a + 1; b < p.X.Shape[1]; b++ {
+			size := math.Abs(p.Score(user, a) - p.Score(user, b))
+			gaps = append(gaps, gap{[2]int{a, b}, size})
+		}
+	}
+	sort.SliceStable(gaps, func(i, j int) bool {
+		return gaps[i].size < gaps[j].size
+	})
+	const uncertain = 5
+	for i := 0; i < len(gaps) && i < uncertain; i++ {
+		d.Uncertain = append(d.Uncertain, gaps[i].pair)
+	}
+	return d, nil
+}
+
+// Struct ItemDiagnosis summarizes what the engine knows about an item, for
+// deciding which items need more labeling.
+type ItemDiagnosis struct {
+	// Comparisons is the number of responses in History that include the
+	// item.
+	Comparisons int
+
+	// Mean and Variance describe the item's score across users. High
+	// variance means users disagree about the item.
+	Mean, Variance float64
+
+	// Neighbors lists the other items that are hardest to tell apart from
+	// this one, that is, with the smallest mean score gap across users, most
+	// confusable first.
+	Neighbors []int
+}
+
+// Method ItemDiagnostics returns an ItemDiagnosis for the item.
+func (p *Engine) ItemDiagnostics(item int) (ItemDiagnosis, error) {
+	var d ItemDiagnosis
+	users, choices := p.X.Shape[0], p.X.Shape[1]
+	if item < 0 || item >= choices {
+		return d, fmt.Errorf("must have 0 <= item [%d] < %d", item, choices)
+	}
+
+	for _, q := range p.History {
+		for _, c := range q.Choices {
+			if c == item {
+				d.Comparisons++
+				break
+			}
+		}
+	}
+
+	for u := 0; u < users; u++ {
+		d.Mean += p.Score(u, item) / float64(users)
+	}
+	for u := 0; u < users; u++ {
+		diff := p.Score(u, item) - d.Mean
+		d.Variance += diff * diff / float64(users)
+	}
+
+	gaps := make([]float64, choices)
+	for c := 0; c < choices; c++ {
+		for u := 0; u < users; u++ {
+			gaps[c] += math.Abs(p.Score(u, item)-p.Score(u, c)) /
+				float64(users)
+		}
+	}
+	for _, c := range rankBy(choices, func(c int) float64 { return -gaps[c] }) {
+		if c != item && len(d.Neighbors) < neighbors {
+			d.Neighbors = append(d.Neighbors, c)
+		}
+	}
+	return d, nil
+}
+
+// neighbors is the number of confusable items reported by ItemDiagnostics.
+const neighbors = 3
@@ -0,0 +1,63 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDiagnose(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+	for i := 0; i < 3; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(Query{User: 0, Choices: []int{1, 2}})
+		eng.Respond(Query{User: 0, Choices: []int{2, 3}})
+	}
+
+	d, err := eng.Diagnose(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Answers != 9 || d.Agreement != 1 || d.CycleRate != 0 {
+		t.Fatalf("expected a consistent user, got %+v", d)
+	}
+	if len(d.Uncertain) != 5 {
+		t.Fatalf("expected 5 uncertain pairs, got %v", d.Uncertain)
+	}
+
+	fresh, _ := eng.Diagnose(1)
+	if fresh.Answers != 0 || fresh.Uncertain[0] != [2]int{0, 1} {
+		t.Fatalf("expected a user without answers to be undecided, got %+v",
+			fresh)
+	}
+	if _, err := eng.Diagnose(2); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+}
+
+func TestItemDiagnostics(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 5)
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(Query{User: 1, Choices: []int{1, 0}})
+		eng.Respond(Query{User: 0, Choices: []int{2, 3}})
+		eng.Respond(Query{User: 1, Choices: []int{2, 3}})
+	}
+
+	d, err := eng.ItemDiagnostics(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Comparisons != 10 || len(d.Neighbors) != 3 {
+		t.Fatalf("unexpected diagnosis %+v", d)
+	}
+	agreed, _ := eng.ItemDiagnostics(2)
+	if d.Variance <= agreed.Variance {
+		t.Fatalf("expected a contested item to vary more, got %v <= %v",
+			d.Variance, agreed.Variance)
+	}
+	if _, err := eng.ItemDiagnostics(5); err == nil {
+		t.Fatalf("expected an error for an unknown item")
+	}
+}
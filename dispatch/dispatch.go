@@ -0,0 +1,135 @@
+// Package dispatch hands out questions to a fleet of frontends through a
+// message queue, rather than over a synchronous API. A Dispatcher publishes
+// a few questions for every user, keyed by user, and learns from the answers
+// that come back, publishing a fresh question for each:
+//
+//	d, err := dispatch.New(publisher, eng, 2)
+//	...
+//	err = d.Run(ctx, answers)
+//
+// where answers is an ingest.ResponseSource, such as one from package
+// ingest/kafka, whose Publisher can also serve as the publisher.
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/ingest"
+	"sync"
+	"time"
+)
+
+// Type Publisher sends questions to the frontends. Implementations should
+// key each question by its User, so that frontends can pull the questions for
+// the users they serve.
+type Publisher interface {
+	Publish(ctx context.Context, q collaborativepermute.Query) error
+}
+
+// Struct Dispatcher keeps Outstanding questions published for every user.
+// Engines are not safe for concurrent use, so the Dispatcher holds Lock
+// while using its engine; replace Lock before running to share the engine
+// with other goroutines.
+type Dispatcher struct {
+	Lock sync.Locker
+
+	// BatchSize and MaxWait configure how answers are batched; see
+	// ingest.NewConsumer. By default, each answer is learned as it arrives.
+	BatchSize int
+	MaxWait   time.Duration
+
+	// OnInvalid, if set, is called with the error describing answers the
+	// engine rejected.
+	OnInvalid func(error)
+
+	publisher   Publisher
+	engine      *collaborativepermute.Engine
+	outstanding int
+}
+
+// New creates a Dispatcher publishing outstanding questions at a time for
+// each user of eng.
+func New(p Publisher, eng *collaborativepermute.Engine,
+	outstanding int) (*Dispatcher, error) {
+	if outstanding < 1 {
+		return nil, fmt.Errorf("must have outstanding [%d] >= 1",
+			outstanding)
+	}
+	return &Dispatcher{
+		Lock:        new(sync.Mutex),
+		BatchSize:   1,
+		publisher:   p,
+		engine:      eng,
+		outstanding: outstanding,
+	}, nil
+}
+
+// Method Run publishes the first questions for every user, then learns from
+// answers until the source ends, ctx is done, or publishing fails. After each
+// batch of answers is learned and committed, a question is published for the
+// user of each answer.
+func (d *Dispatcher) Run(ctx context.Context,
+	answers ingest.ResponseSource) error {
+	d.Lock.Lock()
+	users := d.engine.X.Shape[0]
+	d.Lock.Unlock()
+	for u := 0; u < users; u++ {
+		for i := 0; i < d.outstanding; i++ {
+			if err := d.publish(ctx, u); err != nil {
+				return err
+			}
+		}
+	}
+
+	c, err := ingest.NewConsumer(&watcher{ResponseSource: answers, d: d},
+		d.engine, d.BatchSize, d.MaxWait)
+	if err != nil {
+		return err
+	}
+	c.Lock, c.OnInvalid = d.Lock, d.OnInvalid
+	return c.Run(ctx)
+}
+
+// publish generates and publishes a question for user, skipping unknown
+// users.
+func (d *Dispatcher) publish(ctx context.Context, user int) error {
+	d.Lock.Lock()
+	q, err := d.engine.GenerateSafe(user)
+	d.Lock.Unlock()
+	if err != nil {
+		return nil
+	}
+	return d.publisher.Publish(ctx, q)
+}
+
+// watcher records the users of the answers read from a ResponseSource, and
+// publishes a question for each once they are committed.
+type watcher struct {
+	ingest.ResponseSource
+	d     *Dispatcher
+	users []int
+}
+
+func (w *watcher) Next(ctx context.Context) (collaborativepermute.Query,
+	error) {
+	q, err := w.ResponseSource.Next(ctx)
+	if err == nil && q.User >= 0 {
+		w.users = append(w.users, q.User)
+	}
+	return q, err
+}
+
+func (w *watcher) Commit(ctx context.Context) error {
+	if err := w.ResponseSource.Commit(ctx); err != nil {
+		return err
+	}
+	users := w.users
+	w.users = nil
+	for _, u := range users {
+		if err := w.d.publish(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
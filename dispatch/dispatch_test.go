@@ -0,0 +1,77 @@
+package dispatch
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// queue publishes questions to itself and answers them in order, preferring
+// the lower-numbered choice, until it has answered a fixed number.
+type queue struct {
+	published []collaborativepermute.Query
+	answered  int
+	limit     int
+}
+
+func (q *queue) Publish(ctx context.Context,
+	query collaborativepermute.Query) error {
+	q.published = append(q.published, query)
+	return nil
+}
+
+func (q *queue) Next(ctx context.Context) (collaborativepermute.Query,
+	error) {
+	if q.answered == q.limit || q.answered == len(q.published) {
+		return collaborativepermute.Query{}, io.EOF
+	}
+	answer := q.published[q.answered]
+	q.answered++
+	if answer.Choices[0] > answer.Choices[1] {
+		answer.Choices = []int{answer.Choices[1], answer.Choices[0]}
+	}
+	return answer, nil
+}
+
+func (q *queue) Commit(ctx context.Context) error {
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	rand.Seed(23)
+	eng := collaborativepermute.NewEngine(3, 4)
+	q := &queue{limit: 10}
+	d, err := New(q, eng, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Run(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+
+	perUser := map[int]int{}
+	for _, p := range q.published[:6] {
+		perUser[p.User]++
+	}
+	if len(perUser) != 3 || perUser[0] != 2 {
+		t.Fatalf("expected 2 questions for each of 3 users, got %v",
+			perUser)
+	}
+	if len(eng.History) != 10 || len(q.published) != 16 {
+		t.Fatalf("expected a new question for each of 10 answers, got "+
+			"%d answers and %d questions", len(eng.History),
+			len(q.published))
+	}
+	for i, p := range q.published[6:] {
+		if p.User != q.published[i].User {
+			t.Fatalf("expected question %d to replace one for user %d",
+				i+6, q.published[i].User)
+		}
+	}
+
+	if _, err := New(q, eng, 0); err == nil {
+		t.Fatalf("expected an error for no outstanding questions")
+	}
+}
@@ -0,0 +1,63 @@
+package collaborativepermute
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Method WriteDOT writes the user's inferred preference graph to w in the DOT
+// language of Graphviz. There is an edge from a to b whenever a is probably
+// preferred to b, labeled with that probability and drawn thicker the more
+// certain it is; edges with probability below min are left out to reduce
+// clutter. If user is negative, the probabilities are averaged over every
+// user, which can reveal intransitive consensus preferences. Nodes are
+// labeled with labels[c] if given, and otherwise with the choice index.
+func (p *Engine) WriteDOT(w io.Writer, user int, labels []string, min float64) error {
+	users, choices := p.X.Shape[0], p.X.Shape[1]
+	if user >= users {
+		return fmt.Errorf("must have user [%d] < %d", user, users)
+	}
+	if labels != nil && len(labels) != choices {
+		return fmt.Errorf("must have a label for each of %d choices, got %d",
+			choices, len(labels))
+	}
+
+	probability := func(a, b int) float64 {
+		if user >= 0 {
+			return p.Probability(user, a, b)
+		}
+		sum := 0.0
+		for u := 0; u < users; u++ {
+			sum += p.Probability(u, a, b)
+		}
+		return sum / float64(users)
+	}
+
+	out := bufio.NewWriter(w)
+	name := "consensus"
+	if user >= 0 {
+		name = fmt.Sprintf("user%d", user)
+	}
+	fmt.Fprintf(out, "digraph %s {\n", name)
+	for c := 0; c < choices; c++ {
+		label := strconv.Itoa(c)
+		if labels != nil {
+			label = labels[c]
+		}
+		fmt.Fprintf(out, "\t%d [label=%s];\n", c, strconv.Quote(label))
+	}
+	for a := 0; a < choices; a++ {
+		for b := 0; b < choices; b++ {
+			prob := probability(a, b)
+			if a == b || prob <= 0.5 || prob < min {
+				continue
+			}
+			fmt.Fprintf(out, "\t%d -> %d [label=\"%.2f\", weight=%.3f, "+
+				"penwidth=%.2f];\n", a, b, prob, prob, 1+4*(2*prob-1))
+		}
+	}
+	fmt.Fprintf(out, "}\n")
+	return out.Flush()
+}
@@ -0,0 +1,40 @@
+package collaborativepermute
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3, WithLoss(Logistic))
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(Query{User: 1, Choices: []int{0, 1}})
+	}
+
+	var buf bytes.Buffer
+	if err := eng.WriteDOT(&buf, 0, []string{"tea", "\"coffee\"", "water"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, "digraph user0 {") ||
+		!strings.Contains(dot, `1 [label="\"coffee\""];`) ||
+		!strings.Contains(dot, "0 -> 1 [") || strings.Contains(dot, "1 -> 0 [") {
+		t.Fatalf("unexpected graph:\n%s", dot)
+	}
+
+	buf.Reset()
+	if err := eng.WriteDOT(&buf, -1, nil, 0.99); err != nil {
+		t.Fatal(err)
+	}
+	if dot := buf.String(); !strings.HasPrefix(dot, "digraph consensus {") ||
+		strings.Contains(dot, "->") {
+		t.Fatalf("expected uncertain edges to be left out:\n%s", dot)
+	}
+	if err := eng.WriteDOT(&buf, 0, []string{"tea"}, 0); err == nil {
+		t.Fatalf("expected an error for missing labels")
+	}
+}
@@ -0,0 +1,125 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Struct EloEngine is a lightweight baseline learner that keeps an
+// independent Elo rating of every choice for every user. Each response costs
+// constant time and no matrix factorization is involved, so it remains usable
+// for item sets far too large for the other engines. Since users do not share
+// information, it also serves as a sanity check for how much the
+// collaborative engines gain from doing so.
+type EloEngine struct {
+	Ratings [][]float64
+	K       float64
+	History []Query
+}
+
+// NewEloEngine allocates an Elo learner for the given corpus size, with every
+// rating starting at 1500 and a K-factor of 32.
+func NewEloEngine(users, choices int) (*EloEngine, error) {
+	if users < 0 || choices < 0 {
+		return nil, fmt.Errorf("must have users [%d] >= 0 and choices [%d] >= 0",
+			users, choices)
+	}
+	ratings := make([][]float64, users)
+	for u := range ratings {
+		ratings[u] = make([]float64, choices)
+		for c := range ratings[u] {
+			ratings[u][c] = 1500
+		}
+	}
+	return &EloEngine{Ratings: ratings, K: 32, History: make([]Query, 0)}, nil
+}
+
+var _ Learner = (*EloEngine)(nil)
+
+// Method Probability returns the expected probability that the user prefers
+// choice a over choice b under the Elo model.
+func (p *EloEngine) Probability(user, a, b int) float64 {
+	return 1 / (1 + math.Pow(10, (p.Ratings[user][b]-p.Ratings[user][a])/400))
+}
+
+// Method Respond takes a completed Query and adjusts the two ratings.
+func (p *EloEngine) Respond(prompt Query) error {
+	if len(prompt.Choices) != 2 {
+		return fmt.Errorf("can only handle binary rankings")
+	}
+	if prompt.User < 0 || prompt.User >= len(p.Ratings) {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			prompt.User, len(p.Ratings))
+	}
+	for _, choice := range prompt.Choices {
+		if choice < 0 || choice >= len(p.Ratings[prompt.User]) {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d",
+				choice, len(p.Ratings[prompt.User]))
+		}
+	}
+	if prompt.Choices[0] == prompt.Choices[1] {
+		return fmt.Errorf("choice %d is ranked twice", prompt.Choices[0])
+	}
+	u, a, b := prompt.User, prompt.Choices[0], prompt.Choices[1]
+	delta := p.K * (1 - p.Probability(u, a, b))
+	p.Ratings[u][a] += delta
+	p.Ratings[u][b] -= delta
+	p.History = append(p.History, prompt)
+	return nil
+}
+
+// Method Generate creates a new Query to display to the user. Rather than
+// weighing every pair, it picks a random position in the user's current
+// ranking and asks about the choices on either side of it, which are the
+// closest-rated and so the least certain. Generate panics if there is no
+// question to ask; GenerateSafe returns an error instead.
+func (p *EloEngine) Generate(user int) Query {
+	q, err := p.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (p *EloEngine) GenerateSafe(user int) (Query, error) {
+	if user >= len(p.Ratings) {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, len(p.Ratings))
+	}
+	if user < 0 {
+		if len(p.Ratings) == 0 {
+			return Query{}, ErrNoQuestion
+		}
+		user = rand.Intn(len(p.Ratings))
+	}
+	if len(p.Ratings[user]) < 2 {
+		return Query{}, ErrNoQuestion
+	}
+	ratings := p.Ratings[user]
+	order := make([]int, len(ratings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ratings[order[i]] > ratings[order[j]]
+	})
+	i := rand.Intn(len(order) - 1)
+	return Query{User: user, Choices: []int{order[i], order[i+1]}}, nil
+}
+
+// Method Rank returns the choices ordered from highest to lowest rating for
+// the given user.
+func (p *EloEngine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= len(p.Ratings) {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, len(p.Ratings))
+	}
+	ratings := p.Ratings[user]
+	return rankBy(len(ratings), func(choice int) float64 {
+		return ratings[choice]
+	}), nil
+}
@@ -0,0 +1,35 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEloConvergence(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewEloEngine(3, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 600; i++ {
+		q := eng.Generate(-1)
+		if q.Choices[0] > q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		eng.Respond(q)
+	}
+
+	for u := 0; u < 3; u++ {
+		ranking, _ := eng.Rank(u)
+		if ranking[0] != 0 || ranking[19] != 19 {
+			t.Fatalf("user %d: expected choices in order, got %v", u, ranking)
+		}
+	}
+	if err := eng.Respond(Query{User: 3, Choices: []int{0, 1}}); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	if err := eng.Respond(Query{Choices: []int{4, 4}}); err == nil {
+		t.Fatalf("expected an error for a choice compared with itself")
+	}
+}
@@ -0,0 +1,106 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct Ensemble combines several engines of the same size, typically
+// trained with different seeds or hyperparameters. Every response is given to
+// each member, and predictions are averaged across them, which is far less
+// sensitive to the quirks of any single run.
+type Ensemble struct {
+	Members []*Engine
+
+	next int
+}
+
+var _ Learner = (*Ensemble)(nil)
+
+// NewEnsemble returns an ensemble of the given engines, which must all have
+// the same number of users and choices.
+func NewEnsemble(members ...*Engine) (*Ensemble, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("must have at least one member")
+	}
+	for i, m := range members[1:] {
+		if m.X.Shape[0] != members[0].X.Shape[0] ||
+			m.X.Shape[1] != members[0].X.Shape[1] {
+			return nil, fmt.Errorf("member %d is %dx%d, expected %dx%d",
+				i+1, m.X.Shape[0], m.X.Shape[1],
+				members[0].X.Shape[0], members[0].X.Shape[1])
+		}
+	}
+	return &Ensemble{Members: members}, nil
+}
+
+// Method Score returns the mean of the members' scores for the given user
+// and choice, each z-scored over the user's choices, so that members whose
+// scores are on different scales count equally.
+func (e *Ensemble) Score(user, choice int) float64 {
+	sum := 0.0
+	for _, m := range e.Members {
+		sum += m.standardizedScore(user, choice)
+	}
+	return sum / float64(len(e.Members))
+}
+
+// Method Generate creates a new Query to display to the user, taking turns
+// among the members so that each one's strategy is followed in rotation. The
+// choices are ordered by the ensemble's averaged beliefs. Generate panics if
+// there is no question to ask; GenerateSafe returns an error instead.
+func (e *Ensemble) Generate(user int) Query {
+	q, err := e.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (e *Ensemble) GenerateSafe(user int) (Query, error) {
+	q, err := e.Members[e.next].GenerateSafe(user)
+	if err != nil {
+		return Query{}, err
+	}
+	e.next = (e.next + 1) % len(e.Members)
+	if e.Score(q.User, q.Choices[0]) < e.Score(q.User, q.Choices[1]) {
+		q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+	}
+	return q, nil
+}
+
+// Method Respond takes a completed Query and updates every member. The
+// response is validated against every member before any member is updated,
+// as members configured differently may accept different responses.
+func (e *Ensemble) Respond(prompt Query) error {
+	for i, m := range e.Members {
+		if err := m.validate(prompt); err != nil {
+			return fmt.Errorf("member %d: %v", i, err)
+		}
+	}
+	for _, m := range e.Members {
+		if err := m.Respond(prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user, according to the averaged z-scored scores.
+func (e *Ensemble) Rank(user int) ([]int, error) {
+	first := e.Members[0]
+	if user < 0 || user >= first.X.Shape[0] {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, first.X.Shape[0])
+	}
+	sums := make([]float64, first.X.Shape[1])
+	for _, m := range e.Members {
+		offset, scale := m.standardization(user)
+		for c := range sums {
+			sums[c] += (m.Score(user, c) - offset) / scale
+		}
+	}
+	return rankBy(len(sums), func(c int) float64 { return sums[c] }), nil
+}
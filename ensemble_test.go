@@ -0,0 +1,55 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEnsemble(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewEnsemble(
+		NewEngine(3, 5),
+		NewEngine(3, 5, WithLambda(0.1)),
+		NewEngine(3, 5, WithLoss(Logistic), WithRNG(rand.New(rand.NewSource(1)))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 150; i++ {
+		q := eng.Generate(-1)
+		if q.Choices[0] > q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		if err := eng.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for u := 0; u < 3; u++ {
+		ranking, _ := eng.Rank(u)
+		if ranking[0] != 0 || ranking[4] != 4 {
+			t.Fatalf("user %d: expected choices in order, got %v", u, ranking)
+		}
+	}
+	for _, m := range eng.Members {
+		if len(m.History) != 150 {
+			t.Fatalf("expected every member to see every response")
+		}
+	}
+
+	if err := eng.Respond(Query{User: 3, Choices: []int{0, 1}}); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	mixed, _ := NewEnsemble(NewEngine(1, 3, WithLikert(LikertThresholds(5))),
+		NewEngine(1, 3))
+	if err := mixed.Respond(Query{Choices: []int{0, 1}, Likert: 5}); err == nil {
+		t.Fatalf("expected an error for a response one member rejects")
+	}
+	if len(mixed.Members[0].History) != 0 {
+		t.Fatalf("expected no member to be updated by a rejected response")
+	}
+	if _, err := NewEnsemble(NewEngine(3, 5), NewEngine(3, 4)); err == nil {
+		t.Fatalf("expected an error for mismatched sizes")
+	}
+}
@@ -0,0 +1,66 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct Metrics summarizes how well the engine predicts a set of held-out
+// responses.
+type Metrics struct {
+	// Accuracy is the fraction of the pairs implied by the responses whose
+	// preferred choice the engine scores strictly higher.
+	Accuracy float64
+
+	// AverageMargin is the mean difference in score between the preferred
+	// and the other choice of each pair; it is negative when the engine is
+	// confidently wrong.
+	AverageMargin float64
+
+	// Pairs is the number of pairs evaluated, and Skipped the number of
+	// responses that were invalid for this engine and so ignored.
+	Pairs, Skipped int
+}
+
+// Method Evaluate scores the engine's current predictions on held-out
+// responses that it has not been trained on, for honest progress reporting
+// while responses are still being collected.
+func (p *Engine) Evaluate(holdout []Query) Metrics {
+	var m Metrics
+	correct := 0
+	for _, q := range holdout {
+		if err := p.validate(q); err != nil {
+			m.Skipped++
+			continue
+		}
+		for _, pair := range p.preferences(q) {
+			diff := p.scoreIn(q.User, pair[0], q.Context) -
+				p.scoreIn(q.User, pair[1], q.Context)
+			if diff > 0 {
+				correct++
+			}
+			m.AverageMargin += diff
+			m.Pairs++
+		}
+	}
+	if m.Pairs > 0 {
+		m.Accuracy = float64(correct) / float64(m.Pairs)
+		m.AverageMargin /= float64(m.Pairs)
+	}
+	return m
+}
+
+// Method LossOn returns the mean loss of the current model on the given
+// responses, weighted as in training, for example to compare cohorts. (The
+// name Loss is taken by the field selecting the loss function.) An error is
+// returned if any response is invalid for this engine.
+func (p *Engine) LossOn(samples []Query) (float64, error) {
+	if len(samples) == 0 {
+		return 0, fmt.Errorf("must have at least one response")
+	}
+	for i, q := range samples {
+		if err := p.validate(q); err != nil {
+			return 0, fmt.Errorf("response %d: %v", i, err)
+		}
+	}
+	return p.loss(samples), nil
+}
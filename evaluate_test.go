@@ -0,0 +1,56 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+	for i := 0; i < 20; i++ {
+		eng.Respond(Query{User: i % 2, Choices: []int{i % 3, i%3 + 1}})
+	}
+
+	m := eng.Evaluate([]Query{
+		{User: 0, Choices: []int{0, 3}},
+		{User: 1, Choices: []int{1, 3}},
+		{User: 2, Choices: []int{0, 1}},
+	})
+	if m.Accuracy != 1 || m.AverageMargin <= 0 {
+		t.Fatalf("expected held-out transitive pairs to be right, got %+v", m)
+	}
+	if m.Pairs != 2 || m.Skipped != 1 {
+		t.Fatalf("expected to skip the unknown user, got %+v", m)
+	}
+
+	m = eng.Evaluate([]Query{{User: 0, Choices: []int{3, 0}}})
+	if m.Accuracy != 0 || m.AverageMargin >= 0 {
+		t.Fatalf("expected a reversed pair to be wrong, got %+v", m)
+	}
+}
+
+func TestLossOn(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	for i := 0; i < 10; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	}
+
+	agree, err := eng.LossOn([]Query{{User: 0, Choices: []int{0, 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	disagree, _ := eng.LossOn([]Query{{User: 0, Choices: []int{1, 0}}})
+	if agree >= disagree {
+		t.Fatalf("expected disagreement to cost more, got %v and %v",
+			agree, disagree)
+	}
+
+	if _, err := eng.LossOn([]Query{{User: 0, Choices: []int{0, 3}}}); err == nil {
+		t.Fatalf("expected an error for an unknown choice")
+	}
+	if _, err := eng.LossOn(nil); err == nil {
+		t.Fatalf("expected an error for no responses")
+	}
+}
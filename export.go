@@ -0,0 +1,95 @@
+package collaborativepermute
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Struct Prediction is one user's predicted ranking, as written by
+// ExportPredictions in JSON lines.
+type Prediction struct {
+	User    string        `json:"user"`
+	Ranking []RankedScore `json:"ranking"`
+}
+
+// Struct RankedScore is an item and its score in a Prediction.
+type RankedScore struct {
+	Item  string  `json:"item"`
+	Score float64 `json:"score"`
+}
+
+// Method ExportPredictions writes every user's items from most to least
+// preferred, with their scores, for loading into spreadsheets and other
+// analysis tools. CSV has one row per user and item with the columns user,
+// rank (counting from 1), item, and score; JSON lines have one Prediction
+// per user. Users and items are labeled by index.
+func (p *Engine) ExportPredictions(w io.Writer, format Format) error {
+	users := make([]string, p.X.Shape[0])
+	for u := range users {
+		users[u] = strconv.Itoa(u)
+	}
+	items := make([]string, p.X.Shape[1])
+	for c := range items {
+		items[c] = strconv.Itoa(c)
+	}
+	return p.exportPredictions(w, format, users, items)
+}
+
+// Method ExportPredictions writes every user's ranked items as the Engine
+// method does, labeling users and items by their keys.
+func (n *TypedEngine[U, I]) ExportPredictions(w io.Writer, format Format) error {
+	users := make([]string, len(n.users))
+	for u, id := range n.users {
+		users[u] = fmt.Sprint(id)
+	}
+	items := make([]string, len(n.choices))
+	for c, id := range n.choices {
+		items[c] = fmt.Sprint(id)
+	}
+	return n.Engine.exportPredictions(w, format, users, items)
+}
+
+func (p *Engine) exportPredictions(w io.Writer, format Format,
+	users, items []string) error {
+	predictions := make([]Prediction, len(users))
+	for u := range users {
+		scores := make([]float64, len(items))
+		for c := range items {
+			scores[c] = p.Score(u, c)
+		}
+		ranking := rankBy(len(items), func(c int) float64 { return scores[c] })
+		predictions[u].User = users[u]
+		for _, c := range ranking {
+			predictions[u].Ranking = append(predictions[u].Ranking,
+				RankedScore{Item: items[c], Score: scores[c]})
+		}
+	}
+
+	switch format {
+	case CSV:
+		out := csv.NewWriter(w)
+		out.Write([]string{"user", "rank", "item", "score"})
+		for _, pred := range predictions {
+			for i, r := range pred.Ranking {
+				out.Write([]string{pred.User, strconv.Itoa(i + 1), r.Item,
+					strconv.FormatFloat(r.Score, 'g', -1, 64)})
+			}
+		}
+		out.Flush()
+		return out.Error()
+	case JSONLines:
+		out := bufio.NewWriter(w)
+		enc := json.NewEncoder(out)
+		for _, pred := range predictions {
+			if err := enc.Encode(pred); err != nil {
+				return err
+			}
+		}
+		return out.Flush()
+	}
+	return fmt.Errorf("unknown format %v", format)
+}
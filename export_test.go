@@ -0,0 +1,71 @@
+package collaborativepermute
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExportPredictionsCSV(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{2, 0}})
+		eng.Respond(Query{User: 1, Choices: []int{1, 2}})
+	}
+	var out strings.Builder
+	if err := eng.ExportPredictions(&out, CSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 7 || strings.Join(rows[0], ",") != "user,rank,item,score" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+	ranking, _ := eng.Rank(1)
+	if rows[4][0] != "1" || rows[4][1] != "1" ||
+		rows[4][2] != strconv.Itoa(ranking[0]) {
+		t.Fatalf("expected user 1's top item %d, got %v", ranking[0], rows[4])
+	}
+
+	if err := eng.ExportPredictions(&out, Format(7)); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
+func TestExportPredictionsJSONLines(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine([]string{"ann", "bob"},
+		[]string{"tea", "coffee", "water"})
+	for i := 0; i < 5; i++ {
+		eng.Respond(NamedQuery{User: "ann", Choices: []string{"water", "tea"}})
+	}
+	var out strings.Builder
+	if err := eng.ExportPredictions(&out, JSONLines); err != nil {
+		t.Fatal(err)
+	}
+	var preds []Prediction
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var pred Prediction
+		if err := json.Unmarshal(scanner.Bytes(), &pred); err != nil {
+			t.Fatal(err)
+		}
+		preds = append(preds, pred)
+	}
+	ranking, _ := eng.Rank("ann")
+	if len(preds) != 2 || preds[0].User != "ann" ||
+		len(preds[0].Ranking) != 3 || preds[0].Ranking[0].Item != ranking[0] {
+		t.Fatalf("unexpected predictions %+v", preds)
+	}
+	r := preds[0].Ranking
+	if r[0].Score < r[1].Score || r[1].Score < r[2].Score {
+		t.Fatalf("expected scores in decreasing order, got %+v", r)
+	}
+}
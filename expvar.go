@@ -0,0 +1,70 @@
+//go:build !js
+
+package collaborativepermute
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+)
+
+// WithExpvar publishes the engine's UpdateMetrics as an expvar.Map, so that
+// they appear on the standard /debug/vars endpoint. The map is named by the
+// given prefix and the first of "_0", "_1", ... that no other engine in the
+// process has taken, and is recorded in ExpvarName. It holds the number of
+// updates and responses, the duration of the last update and of its SVD in
+// seconds, and the loss. Nothing is published unless every option succeeds.
+func WithExpvar(prefix string) Option {
+	return func(p *Engine) error {
+		if prefix == "" {
+			return fmt.Errorf("must have a non-empty expvar prefix")
+		}
+		p.publish = append(p.publish, func() {
+			name, vars := publishExpvar(prefix)
+			p.ExpvarName = name
+			p.addMetricsHook(expvarHook{vars})
+		})
+		return nil
+	}
+}
+
+// expvarMu serializes publishExpvar, so that engines created concurrently
+// take distinct names.
+var expvarMu sync.Mutex
+
+// publishExpvar publishes a new expvar.Map named by prefix and the first
+// free sequence number.
+func publishExpvar(prefix string) (string, *expvar.Map) {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("%s_%d", prefix, i)
+		if expvar.Get(name) == nil {
+			return name, expvar.NewMap(name)
+		}
+	}
+}
+
+type expvarHook struct {
+	vars *expvar.Map
+}
+
+func (h expvarHook) ObserveUpdate(m UpdateMetrics) {
+	h.vars.Add("updates", 1)
+	h.vars.Set("responses", intVar(int64(m.Responses)))
+	h.vars.Set("update_seconds", floatVar(m.Duration.Seconds()))
+	h.vars.Set("svd_seconds", floatVar(m.SVD.Seconds()))
+	h.vars.Set("loss", floatVar(m.Loss))
+}
+
+func intVar(v int64) *expvar.Int {
+	i := new(expvar.Int)
+	i.Set(v)
+	return i
+}
+
+func floatVar(v float64) *expvar.Float {
+	f := new(expvar.Float)
+	f.Set(v)
+	return f
+}
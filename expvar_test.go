@@ -0,0 +1,51 @@
+//go:build !js
+
+package collaborativepermute
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestWithExpvar(t *testing.T) {
+	rand.Seed(23)
+	prefix := fmt.Sprintf("test_engine_%d", time.Now().UnixNano())
+	if _, err := NewEngineSafe(1, 2, WithExpvar(prefix),
+		WithLambda(-1)); err == nil {
+		t.Fatalf("expected an error for a negative lambda")
+	}
+	if expvar.Get(prefix+"_0") != nil {
+		t.Fatalf("expected nothing to be published by a failed NewEngineSafe")
+	}
+
+	var observed metricsRecorder
+	eng := NewEngine(2, 3, WithMetrics(&observed), WithExpvar(prefix))
+	eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	eng.Respond(Query{User: 1, Choices: []int{2, 1}})
+	if eng.ExpvarName != prefix+"_0" {
+		t.Fatalf("expected %s_0, got %q", prefix, eng.ExpvarName)
+	}
+
+	var vars struct {
+		Updates, Responses int
+		Loss               float64
+	}
+	if err := json.Unmarshal([]byte(expvar.Get(eng.ExpvarName).String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars.Updates != 2 || vars.Responses != 2 || vars.Loss != observed[1].Loss {
+		t.Fatalf("unexpected published vars %+v", vars)
+	}
+	if len(observed) != 2 {
+		t.Fatalf("expected the existing hook to be kept, got %d updates",
+			len(observed))
+	}
+	if other := NewEngine(1, 2, WithExpvar(prefix)); other.ExpvarName != prefix+"_1" {
+		t.Fatalf("expected a second engine to take %s_1, got %q", prefix,
+			other.ExpvarName)
+	}
+}
@@ -0,0 +1,174 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// WithItemFeatures enables the hybrid model, in which each choice's score is
+// the sum of a low-rank residual and a learned per-user linear function of the
+// choice's features. Sparse feedback on one choice then generalizes to every
+// choice with similar features. Choices without features (nil rows) rely on
+// the residual alone.
+func WithItemFeatures(features [][]float64) Option {
+	return func(p *Engine) error {
+		d := 0
+		for _, f := range features {
+			if len(f) > d {
+				d = len(f)
+			}
+		}
+		p.ItemFeatures = features
+		p.A = gauss.Zero(p.X.Shape[0], d)
+		return nil
+	}
+}
+
+// WithUserFeatures adds a learned per-choice linear function of each user's
+// covariates (such as demographics or onboarding-survey answers) to their
+// scores, so that a new user's first recommendations can be informed by
+// similar users before they have answered any questions. Users without
+// covariates (nil rows) rely on the other terms alone.
+func WithUserFeatures(features [][]float64) Option {
+	return func(p *Engine) error {
+		d := 0
+		for _, f := range features {
+			if len(f) > d {
+				d = len(f)
+			}
+		}
+		p.UserFeatures = features
+		p.B = gauss.Zero(d, p.X.Shape[1])
+		return nil
+	}
+}
+
+// Method SetUserFeatures records the covariates for a user, such as one just
+// added with AddUser.
+func (p *Engine) SetUserFeatures(user int, features []float64) error {
+	if user < 0 || user >= p.X.Shape[0] {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if p.B.Shape == nil {
+		return fmt.Errorf("engine was not configured WithUserFeatures")
+	}
+	if len(features) > p.B.Shape[0] {
+		return fmt.Errorf("must have len(features) [%d] <= %d",
+			len(features), p.B.Shape[0])
+	}
+	for len(p.UserFeatures) < p.X.Shape[0] {
+		p.UserFeatures = append(p.UserFeatures, nil)
+	}
+	p.UserFeatures[user] = append([]float64(nil), features...)
+	return nil
+}
+
+// updateFeatureWeights takes a gradient step of size nu on A and B, given the
+// gradient of the loss with respect to the scores, with a ridge penalty of
+// Lambda.
+func (p *Engine) updateFeatureWeights(gradient gauss.Array, nu float64) {
+	if p.B.Shape != nil {
+		for k := 0; k < p.B.Shape[0]; k++ {
+			for j := 0; j < p.B.Shape[1]; j++ {
+				step := p.Lambda * *p.B.I(k, j)
+				for u, f := range p.UserFeatures {
+					if k < len(f) {
+						step += *gradient.I(u, j) * f[k]
+					}
+				}
+				*p.B.I(k, j) -= nu * step
+			}
+		}
+	}
+	if p.A.Shape == nil {
+		return
+	}
+	for u := 0; u < p.A.Shape[0]; u++ {
+		for k := 0; k < p.A.Shape[1]; k++ {
+			step := p.Lambda * *p.A.I(u, k)
+			for j, f := range p.ItemFeatures {
+				if k < len(f) {
+					step += *gradient.I(u, j) * f[k]
+				}
+			}
+			*p.A.I(u, k) -= nu * step
+		}
+	}
+}
+
+// Method AddItemFrom appends a new choice whose initial scores are predicted
+// from its feature vector, returning its index.
+//
+// In the hybrid model, the learned feature weights already provide this
+// prediction. Otherwise, each user's scores for the existing choices with
+// known ItemFeatures are regressed onto those features (with a ridge penalty
+// of Lambda), and the fitted map is applied to the new choice. Either way, a
+// brand-new choice starts out ranked like the choices it resembles.
+func (p *Engine) AddItemFrom(features []float64) (int, error) {
+	if p.A.Shape != nil && len(features) > p.A.Shape[1] {
+		return 0, fmt.Errorf("must have len(features) [%d] <= %d",
+			len(features), p.A.Shape[1])
+	}
+	known := make([]int, 0)
+	for j, f := range p.ItemFeatures {
+		if f == nil {
+			continue
+		}
+		if len(f) != len(features) && p.A.Shape == nil {
+			return 0, fmt.Errorf("must have len(features) [%d] == %d",
+				len(features), len(f))
+		}
+		known = append(known, j)
+	}
+
+	column := make([]float64, p.X.Shape[0])
+	if len(known) > 0 && len(features) > 0 && p.A.Shape == nil {
+		weights, err := p.regressFeatures(known, len(features))
+		if err != nil {
+			return 0, err
+		}
+		for u := range column {
+			for k, f := range features {
+				column[u] += f * weights[k][u]
+			}
+		}
+	}
+
+	if p.ItemFeatures == nil {
+		p.ItemFeatures = make([][]float64, p.X.Shape[1])
+	}
+	item := p.AddItem()
+	p.ItemFeatures[item] = append([]float64(nil), features...)
+	for u, value := range column {
+		*p.X.I(u, item) = value
+		*p.Xp.I(u, item) = value
+		*p.Z.I(u, item) = value
+	}
+	return item, nil
+}
+
+// regressFeatures solves the ridge regression from the features of the given
+// choices to every user's scores, returning a d×users weight matrix.
+func (p *Engine) regressFeatures(items []int, d int) ([][]float64, error) {
+	users := p.X.Shape[0]
+	gram := make([][]float64, d)
+	cross := make([][]float64, d)
+	for k := range gram {
+		gram[k] = make([]float64, d)
+		gram[k][k] = p.Lambda
+		cross[k] = make([]float64, users)
+	}
+	for _, j := range items {
+		f := p.ItemFeatures[j]
+		for k := range f {
+			for l := range f {
+				gram[k][l] += f[k] * f[l]
+			}
+			for u := 0; u < users; u++ {
+				cross[k][u] += f[k] * *p.X.I(u, j)
+			}
+		}
+	}
+	return solve(gram, cross)
+}
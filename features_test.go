@@ -0,0 +1,72 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAddItemFrom(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 0)
+	for _, f := range [][]float64{{1, 0}, {0, 1}, {0.9, 0.1}} {
+		if _, err := eng.AddItemFrom(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 30; i++ {
+		eng.Respond(Query{User: i % 2, Choices: []int{0, 1}})
+		eng.Respond(Query{User: i % 2, Choices: []int{2, 1}})
+	}
+
+	item, err := eng.AddItemFrom([]float64{1, 0.05})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *eng.X.I(0, item) <= *eng.X.I(0, 1) {
+		t.Fatalf("expected new item to resemble item 0 more than item 1")
+	}
+	if _, err := eng.AddItemFrom([]float64{1}); err == nil {
+		t.Fatalf("expected an error for mismatched feature lengths")
+	}
+}
+
+func TestHybridGeneralizes(t *testing.T) {
+	rand.Seed(23)
+	features := [][]float64{{1, 0}, {0, 1}, {1, 0}, {0, 1}}
+	eng := NewEngine(1, 4, WithItemFeatures(features))
+	for i := 0; i < 30; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	}
+
+	if eng.Score(0, 2) <= eng.Score(0, 3) {
+		t.Fatalf("expected preference for item 0 to carry over to item 2")
+	}
+	if _, err := eng.AddItemFrom([]float64{1, 0, 1}); err == nil {
+		t.Fatalf("expected an error for too many features")
+	}
+}
+
+func TestUserFeatures(t *testing.T) {
+	rand.Seed(23)
+	covariates := [][]float64{{1, 0}, {0, 1}, {1, 0}, {0, 1}}
+	eng := NewEngine(4, 2, WithUserFeatures(covariates))
+	for i := 0; i < 30; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(Query{User: 1, Choices: []int{1, 0}})
+	}
+
+	if eng.Score(2, 0) <= eng.Score(2, 1) {
+		t.Fatalf("expected user 2 to resemble user 0")
+	}
+	if eng.Score(3, 1) <= eng.Score(3, 0) {
+		t.Fatalf("expected user 3 to resemble user 1")
+	}
+
+	u := eng.AddUser()
+	if err := eng.SetUserFeatures(u, []float64{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if eng.Score(u, 1) <= eng.Score(u, 0) {
+		t.Fatalf("expected new user to resemble user 1")
+	}
+}
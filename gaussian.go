@@ -0,0 +1,181 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Struct GaussianEngine is a TrueSkill-style learner that maintains a
+// Gaussian belief, with a Mean and Variance, about how strongly each user
+// prefers each choice. Each response is incorporated by assumed-density
+// filtering, with Beta the standard deviation of the noise in a user's
+// judgments and Tau the drift in preferences added before each update.
+//
+// Because it tracks its own uncertainty, the engine can ask about the pairs
+// it knows least about and report how confident it is in each score.
+type GaussianEngine struct {
+	Mean, Variance [][]float64
+	Beta, Tau, T   float64
+	History        []Query
+}
+
+// NewGaussianEngine allocates a Gaussian learner for the given corpus size,
+// with every belief starting as a standard normal.
+func NewGaussianEngine(users, choices int) (*GaussianEngine, error) {
+	if users < 0 || choices < 0 {
+		return nil, fmt.Errorf("must have users [%d] >= 0 and choices [%d] >= 0",
+			users, choices)
+	}
+	mean, variance := make([][]float64, users), make([][]float64, users)
+	for u := range mean {
+		mean[u] = make([]float64, choices)
+		variance[u] = make([]float64, choices)
+		for c := range variance[u] {
+			variance[u][c] = 1
+		}
+	}
+	return &GaussianEngine{
+		Mean:     mean,
+		Variance: variance,
+		Beta:     0.5,
+		T:        1,
+		History:  make([]Query, 0),
+	}, nil
+}
+
+var _ Learner = (*GaussianEngine)(nil)
+
+// Method Probability returns the modeled probability that the user prefers
+// choice a over choice b, accounting for the uncertainty in both beliefs.
+func (p *GaussianEngine) Probability(user, a, b int) float64 {
+	diff := p.Mean[user][a] - p.Mean[user][b]
+	return normalCDF(diff / p.spread(user, a, b))
+}
+
+// Method Interval returns a credible interval of z standard deviations
+// around the user's mean score for the choice.
+func (p *GaussianEngine) Interval(user, choice int, z float64) (lo, hi float64) {
+	sd := math.Sqrt(p.Variance[user][choice])
+	return p.Mean[user][choice] - z*sd, p.Mean[user][choice] + z*sd
+}
+
+// Method Quality returns the TrueSkill match quality of asking the user to
+// compare choices a and b: how likely the outcome is to be close, scaled by
+// how much is still unknown. It is highest for uncertain, evenly matched
+// pairs.
+func (p *GaussianEngine) Quality(user, a, b int) float64 {
+	c := p.spread(user, a, b)
+	diff := p.Mean[user][a] - p.Mean[user][b]
+	return math.Sqrt(2*p.Beta*p.Beta) / c * math.Exp(-diff*diff/(2*c*c))
+}
+
+// Method Respond takes a completed Query and updates the beliefs about both
+// choices.
+func (p *GaussianEngine) Respond(prompt Query) error {
+	if len(prompt.Choices) != 2 {
+		return fmt.Errorf("can only handle binary rankings")
+	}
+	if prompt.User < 0 || prompt.User >= len(p.Mean) {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			prompt.User, len(p.Mean))
+	}
+	for _, choice := range prompt.Choices {
+		if choice < 0 || choice >= len(p.Mean[prompt.User]) {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d",
+				choice, len(p.Mean[prompt.User]))
+		}
+	}
+	if prompt.Choices[0] == prompt.Choices[1] {
+		return fmt.Errorf("choice %d is ranked twice", prompt.Choices[0])
+	}
+
+	u, w, l := prompt.User, prompt.Choices[0], prompt.Choices[1]
+	mean, variance := p.Mean[u], p.Variance[u]
+	variance[w] += p.Tau * p.Tau
+	variance[l] += p.Tau * p.Tau
+
+	c := p.spread(u, w, l)
+	t := (mean[w] - mean[l]) / c
+	v := millsRatio(t)
+	shrink := v * (v + t)
+
+	mean[w] += variance[w] / c * v
+	mean[l] -= variance[l] / c * v
+	variance[w] *= 1 - variance[w]/(c*c)*shrink
+	variance[l] *= 1 - variance[l]/(c*c)*shrink
+
+	p.History = append(p.History, prompt)
+	return nil
+}
+
+// Method Generate creates a new Query to display to the user, sampling pairs
+// in proportion to their Quality raised to the power 1/T. If user is
+// non-negative, only return queries for that user. Generate panics if there
+// is no question to ask; GenerateSafe returns an error instead.
+func (p *GaussianEngine) Generate(user int) Query {
+	q, err := p.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (p *GaussianEngine) GenerateSafe(user int) (Query, error) {
+	if user >= len(p.Mean) {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, len(p.Mean))
+	}
+	choices := 0
+	if len(p.Mean) > 0 {
+		choices = len(p.Mean[0])
+	}
+	q, ok := sampleQuery(len(p.Mean), choices, user, rand.Float64,
+		func(u, a, b int) float64 {
+			return math.Pow(p.Quality(u, a, b), 1/p.T)
+		},
+		func(u, c int) float64 { return p.Mean[u][c] })
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	return q, nil
+}
+
+// Method Rank returns the choices ordered from highest to lowest mean score
+// for the given user.
+func (p *GaussianEngine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= len(p.Mean) {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, len(p.Mean))
+	}
+	mean := p.Mean[user]
+	return rankBy(len(mean), func(choice int) float64 {
+		return mean[choice]
+	}), nil
+}
+
+// spread returns the standard deviation of the difference in performance
+// between two choices.
+func (p *GaussianEngine) spread(user, a, b int) float64 {
+	return math.Sqrt(2*p.Beta*p.Beta + p.Variance[user][a] + p.Variance[user][b])
+}
+
+func normalPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func normalCDF(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// millsRatio returns normalPDF(x) / normalCDF(x). Far below the mean, where
+// both underflow, it uses the asymptotic expansion of the ratio instead.
+func millsRatio(x float64) float64 {
+	if x > -20 {
+		return normalPDF(x) / normalCDF(x)
+	}
+	x2 := x * x
+	return -x / (1 - 1/x2 + 3/(x2*x2) - 15/(x2*x2*x2))
+}
@@ -0,0 +1,79 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGaussianConvergence(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewGaussianEngine(10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incorrect := 0
+
+	for i := 0; i < 300; i++ {
+		q := eng.Generate(-1)
+		if q.Choices[0] >= q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+			incorrect += 1
+		}
+		eng.Respond(q)
+	}
+
+	// Users share nothing, so this cannot match the collaborative engines,
+	// but should do far better than the 150 mistakes of random guessing.
+	if incorrect > 100 {
+		t.Fatalf("needed %v mistakes for a 10x10 matrix", incorrect)
+	}
+}
+
+func TestGaussianUncertainty(t *testing.T) {
+	eng, _ := NewGaussianEngine(1, 3)
+	before := eng.Quality(0, 0, 1)
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	}
+
+	lo, hi := eng.Interval(0, 0, 2)
+	if lo >= eng.Mean[0][0] || hi <= eng.Mean[0][0] || hi-lo >= 4 {
+		t.Fatalf("expected a narrowed interval around the mean, got [%v, %v]",
+			lo, hi)
+	}
+	if eng.Variance[0][2] != 1 {
+		t.Fatalf("expected an unasked choice to keep its prior variance")
+	}
+	if eng.Quality(0, 0, 1) >= before {
+		t.Fatalf("expected a settled pair to be less worth asking about")
+	}
+	if p := eng.Probability(0, 0, 1); p <= 0.5 {
+		t.Fatalf("expected choice 0 to be favored, got %v", p)
+	}
+	if err := eng.Respond(Query{Choices: []int{2, 2}}); err == nil {
+		t.Fatalf("expected an error for a choice compared with itself")
+	}
+}
+
+func TestGaussianUpset(t *testing.T) {
+	eng, _ := NewGaussianEngine(1, 2)
+	eng.Mean[0][0], eng.Variance[0][0] = 1000, 100
+	eng.Respond(Query{Choices: []int{1, 0}})
+	for c := range eng.Mean[0] {
+		if math.IsNaN(eng.Mean[0][c]) || math.IsNaN(eng.Variance[0][c]) {
+			t.Fatalf("expected an upset to keep the beliefs finite, "+
+				"got %v, %v", eng.Mean[0], eng.Variance[0])
+		}
+	}
+	if eng.Mean[0][1] <= 0 {
+		t.Fatalf("expected the upset to raise the loser's mean, got %v",
+			eng.Mean[0])
+	}
+
+	ratio := normalPDF(-20) / normalCDF(-20)
+	if v := millsRatio(-20); math.Abs(v-ratio) > 1e-6 {
+		t.Fatalf("expected the expansion to agree with the ratio at -20, "+
+			"got %v and %v", v, ratio)
+	}
+}
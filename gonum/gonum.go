@@ -0,0 +1,65 @@
+// Package gonum converts between the gauss arrays used by
+// collaborativepermute and gonum matrices, so that learned preferences can be
+// analyzed with the rest of the gonum ecosystem, and gonum matrices used to
+// seed engines. Engine.SetPriorMatrix accepts a mat.Matrix directly, and
+// Backend lets the engine compute its SVDs with gonum.
+package gonum
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Function Dense copies a two-dimensional gauss.Array, such as Engine.X, into
+// a new mat.Dense.
+func Dense(a gauss.Array) (*mat.Dense, error) {
+	if len(a.Shape) != 2 {
+		return nil, fmt.Errorf("must have a two-dimensional array, not %v",
+			a.Shape)
+	}
+	rows, cols := a.Shape[0], a.Shape[1]
+	if rows == 0 || cols == 0 {
+		return nil, fmt.Errorf("gonum cannot represent an empty %d×%d matrix",
+			rows, cols)
+	}
+	return mat.NewDense(rows, cols, append([]float64(nil), a.Data...)), nil
+}
+
+// Function Array copies a mat.Matrix into a new gauss.Array.
+func Array(m mat.Matrix) gauss.Array {
+	rows, cols := m.Dims()
+	a := gauss.Zero(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			*a.I(i, j) = m.At(i, j)
+		}
+	}
+	return a
+}
+
+// Type Backend computes singular value decompositions with gonum's LAPACK
+// bindings. Pass it to collaborativepermute.WithBackend.
+type Backend struct{}
+
+// Method SVD returns the thin SVD of a.
+func (Backend) SVD(a gauss.Array) (u, s, v gauss.Array) {
+	rows, cols := a.Shape[0], a.Shape[1]
+	k := rows
+	if cols < k {
+		k = cols
+	}
+	if k == 0 {
+		return gauss.Zero(rows, 0), gauss.Zero(0), gauss.Zero(cols, 0)
+	}
+	var svd mat.SVD
+	if !svd.Factorize(mat.NewDense(rows, cols, a.Data), mat.SVDThin) {
+		panic("gonum: SVD failed to converge")
+	}
+	var U, V mat.Dense
+	svd.UTo(&U)
+	svd.VTo(&V)
+	s = gauss.Zero(k)
+	svd.Values(s.Data)
+	return Array(&U), s, Array(&V)
+}
@@ -0,0 +1,64 @@
+package gonum
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/gauss"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rand.Seed(23)
+	eng := collaborativepermute.NewEngine(2, 3)
+	eng.Respond(collaborativepermute.Query{User: 1, Choices: []int{2, 0}})
+
+	dense, err := Dense(eng.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, c := dense.Dims(); r != 2 || c != 3 || dense.At(1, 2) != *eng.X.I(1, 2) {
+		t.Fatalf("unexpected matrix %v", dense)
+	}
+	back := Array(dense)
+	for i := range back.Data {
+		if back.Data[i] != eng.X.Data[i] {
+			t.Fatalf("round trip differs at %d", i)
+		}
+	}
+	dense.Set(0, 0, 7)
+	if *eng.X.I(0, 0) == 7 {
+		t.Fatalf("expected Dense to copy the array")
+	}
+
+	if _, err := Dense(gauss.Zero(0, 3)); err == nil {
+		t.Fatalf("expected an error for an empty array")
+	}
+}
+
+func TestSetPriorMatrix(t *testing.T) {
+	eng := collaborativepermute.NewEngine(1, 2)
+	if err := eng.SetPriorMatrix(mat.NewDense(1, 2, []float64{1, 2}), 1); err != nil {
+		t.Fatal(err)
+	}
+	if eng.Score(0, 1) != 2 {
+		t.Fatalf("expected the prior to be applied")
+	}
+}
+
+func TestBackend(t *testing.T) {
+	rand.Seed(23)
+	eng := collaborativepermute.NewEngine(3, 4,
+		collaborativepermute.WithBackend(Backend{}))
+	ref := collaborativepermute.NewEngine(3, 4)
+	for _, e := range []*collaborativepermute.Engine{eng, ref} {
+		e.Respond(collaborativepermute.Query{User: 0, Choices: []int{1, 0}})
+		e.Respond(collaborativepermute.Query{User: 2, Choices: []int{3, 1}})
+	}
+	for i := range ref.X.Data {
+		if math.Abs(eng.X.Data[i]-ref.X.Data[i]) > 1e-9 {
+			t.Fatalf("gonum backend diverged from gauss at %d", i)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package grpcapi serves a collaborativepermute Engine over gRPC, so that
+// clients in any language can use a hosted engine. The service is defined in
+// permutepb/permute.proto.
+//
+//	s := grpc.NewServer()
+//	permutepb.RegisterPermuteServer(s, grpcapi.New(eng))
+//	s.Serve(listener)
+package grpcapi
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/grpcapi/permutepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"sync"
+)
+
+// Struct Server implements the Permute service for an Engine. Engines are
+// not safe for concurrent use, so the Server holds Lock while using it;
+// replace Lock before serving to share the engine with other goroutines.
+type Server struct {
+	permutepb.UnimplementedPermuteServer
+
+	Lock   sync.Locker
+	engine *collaborativepermute.Engine
+}
+
+// New creates a Server for the engine.
+func New(eng *collaborativepermute.Engine) *Server {
+	return &Server{Lock: new(sync.Mutex), engine: eng}
+}
+
+// Method GenerateQuery returns the next question to ask the requested user,
+// or any user if it is negative.
+func (s *Server) GenerateQuery(ctx context.Context, req *permutepb.GenerateQueryRequest) (*permutepb.Query, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	return s.generate(ctx, int(req.GetUser()))
+}
+
+// Method SubmitResponse teaches the engine a user's answer.
+func (s *Server) SubmitResponse(ctx context.Context, req *permutepb.Query) (*permutepb.SubmitResponseReply, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	if err := s.engine.RespondContext(ctx, query(req)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &permutepb.SubmitResponseReply{}, nil
+}
+
+// Method GetRanking returns the user's predicted order of every choice.
+func (s *Server) GetRanking(ctx context.Context, req *permutepb.GetRankingRequest) (*permutepb.Ranking, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	ranking, err := s.engine.Rank(int(req.GetUser()))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &permutepb.Ranking{User: req.GetUser(), Choices: int32s(ranking)}, nil
+}
+
+// Method GetStats summarizes the state of the engine; see Engine.Stats.
+func (s *Server) GetStats(ctx context.Context, req *permutepb.GetStatsRequest) (*permutepb.Stats, error) {
+	s.Lock.Lock()
+	stats := s.engine.Stats()
+	s.Lock.Unlock()
+
+	perUser := make([]int64, len(stats.PerUser))
+	for u, n := range stats.PerUser {
+		perUser[u] = int64(n)
+	}
+	return &permutepb.Stats{
+		Responses:         int64(stats.Responses),
+		PerUser:           perUser,
+		Rank:              int32(stats.Rank),
+		LastUpdateSeconds: stats.LastUpdate.Seconds(),
+		Loss:              stats.Loss,
+	}, nil
+}
+
+// Method Session asks the user named by the first message question after
+// question, sending the next one as soon as each answer has been learned, to
+// save rapid comparison UIs a round trip per answer. The session ends when
+// the client closes its side, with InvalidArgument at the first invalid
+// answer or one from another user, or with FailedPrecondition once there is
+// no question to ask.
+func (s *Server) Session(stream permutepb.Permute_SessionServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if len(first.GetChoices()) > 0 {
+		return status.Error(codes.InvalidArgument,
+			"first message must name only the user")
+	}
+	user := int(first.GetUser())
+
+	s.Lock.Lock()
+	q, err := s.generate(ctx, user)
+	s.Lock.Unlock()
+	if err != nil {
+		return err
+	}
+	for {
+		if err := stream.Send(q); err != nil {
+			return err
+		}
+		answer, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if user >= 0 && int(answer.GetUser()) != user {
+			return status.Errorf(codes.InvalidArgument,
+				"must answer as user %d, not %d", user, answer.GetUser())
+		}
+		s.Lock.Lock()
+		q, err = s.respond(ctx, answer, user)
+		s.Lock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// respond teaches the engine an answer and returns the next question for
+// the user. The caller must hold Lock.
+func (s *Server) respond(ctx context.Context, answer *permutepb.Query, user int) (*permutepb.Query, error) {
+	if err := s.engine.RespondContext(ctx, query(answer)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.generate(ctx, user)
+}
+
+// generate returns the next question for the user, or any user if it is
+// negative. The caller must hold Lock.
+func (s *Server) generate(ctx context.Context, user int) (*permutepb.Query, error) {
+	q, err := s.engine.GenerateSafeContext(ctx, user)
+	if err == collaborativepermute.ErrNoQuestion {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &permutepb.Query{User: int32(q.User), Choices: int32s(q.Choices)}, nil
+}
+
+// query converts a protocol buffer Query for the engine.
+func query(q *permutepb.Query) collaborativepermute.Query {
+	choices := make([]int, len(q.GetChoices()))
+	for i, c := range q.GetChoices() {
+		choices[i] = int(c)
+	}
+	return collaborativepermute.Query{User: int(q.GetUser()), Choices: choices}
+}
+
+func int32s(values []int) []int32 {
+	result := make([]int32, len(values))
+	for i, v := range values {
+		result[i] = int32(v)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/grpcapi/permutepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	rand.Seed(23)
+	ctx := context.Background()
+	s := New(collaborativepermute.NewEngine(2, 3))
+
+	q, err := s.GenerateQuery(ctx, &permutepb.GenerateQueryRequest{User: 1})
+	if err != nil || q.GetUser() != 1 || len(q.GetChoices()) != 2 {
+		t.Fatalf("unexpected question %v, %v", q, err)
+	}
+	answer := &permutepb.Query{User: 1, Choices: []int32{2, 0}}
+	if _, err := s.SubmitResponse(ctx, answer); err != nil {
+		t.Fatal(err)
+	}
+	ranking, err := s.GetRanking(ctx, &permutepb.GetRankingRequest{User: 1})
+	if err != nil || ranking.GetChoices()[0] == 0 {
+		t.Fatalf("unexpected ranking %v, %v", ranking, err)
+	}
+	stats, err := s.GetStats(ctx, &permutepb.GetStatsRequest{})
+	if err != nil || stats.GetResponses() != 1 || stats.GetPerUser()[1] != 1 {
+		t.Fatalf("unexpected stats %v, %v", stats, err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	rand.Seed(23)
+	ctx := context.Background()
+	s := New(collaborativepermute.NewEngine(2, 3))
+
+	_, err := s.GenerateQuery(ctx, &permutepb.GenerateQueryRequest{User: 2})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an unknown user, got %v", err)
+	}
+	_, err = s.SubmitResponse(ctx, &permutepb.Query{Choices: []int32{1, 1}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a bad answer, got %v", err)
+	}
+	_, err = s.GetRanking(ctx, &permutepb.GetRankingRequest{User: -1})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an unknown user, got %v", err)
+	}
+
+	empty := New(collaborativepermute.NewEngine(0, 3))
+	_, err = empty.GenerateQuery(ctx, &permutepb.GenerateQueryRequest{User: -1})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition with no users, got %v", err)
+	}
+	single := New(collaborativepermute.NewEngine(1, 1))
+	_, err = single.GenerateQuery(ctx, &permutepb.GenerateQueryRequest{User: 0})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition with one choice, got %v", err)
+	}
+}
+
+// fakeSession is a Session stream whose client side is driven by channels.
+type fakeSession struct {
+	permutepb.Permute_SessionServer
+	in, out chan *permutepb.Query
+}
+
+func (f *fakeSession) Context() context.Context { return context.Background() }
+
+func (f *fakeSession) Send(q *permutepb.Query) error {
+	f.out <- q
+	return nil
+}
+
+func (f *fakeSession) Recv() (*permutepb.Query, error) {
+	q, ok := <-f.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return q, nil
+}
+
+func TestServerSession(t *testing.T) {
+	rand.Seed(23)
+	s := New(collaborativepermute.NewEngine(2, 3))
+	stream := &fakeSession{in: make(chan *permutepb.Query),
+		out: make(chan *permutepb.Query)}
+	done := make(chan error)
+	go func() { done <- s.Session(stream) }()
+
+	stream.in <- &permutepb.Query{User: 1}
+	for i := 0; i < 3; i++ {
+		q := <-stream.out
+		if q.GetUser() != 1 || len(q.GetChoices()) != 2 {
+			t.Fatalf("unexpected question %v", q)
+		}
+		stream.in <- q
+	}
+	<-stream.out
+	close(stream.in)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	stats, _ := s.GetStats(context.Background(), &permutepb.GetStatsRequest{})
+	if stats.GetPerUser()[1] != 3 {
+		t.Fatalf("expected three answers from user 1, got %v", stats)
+	}
+
+	stream.in = make(chan *permutepb.Query)
+	go func() { done <- s.Session(stream) }()
+	stream.in <- &permutepb.Query{User: 1}
+	<-stream.out
+	stream.in <- &permutepb.Query{User: 0, Choices: []int32{0, 1}}
+	if err := <-done; status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for another user's answer, got %v",
+			err)
+	}
+
+	go func() { done <- s.Session(stream) }()
+	stream.in <- &permutepb.Query{User: 2}
+	if err := <-done; status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for an unknown user, got %v", err)
+	}
+
+	single := New(collaborativepermute.NewEngine(1, 1))
+	go func() { done <- single.Session(stream) }()
+	stream.in <- &permutepb.Query{User: 0}
+	if err := <-done; status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition with one choice, got %v", err)
+	}
+}
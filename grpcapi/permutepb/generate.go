@@ -0,0 +1,5 @@
+// Package permutepb holds the protocol buffer messages and gRPC stubs
+// generated from permute.proto.
+package permutepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative permute.proto
@@ -0,0 +1,65 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Struct Heatmap is the user×item score matrix, laid out for plotting
+// libraries such as d3 and plotly: Scores[u][c] is the score of Items[c] for
+// Users[u]. If Standardized is set, each user's scores are z-scored, so that
+// users are comparable.
+type Heatmap struct {
+	Users        []string    `json:"users"`
+	Items        []string    `json:"items"`
+	Scores       [][]float64 `json:"scores"`
+	Standardized bool        `json:"standardized"`
+}
+
+// Method Heatmap returns the engine's scores as a Heatmap, labeling users
+// and items by index.
+func (p *Engine) Heatmap(standardize bool) Heatmap {
+	users := make([]string, p.X.Shape[0])
+	for u := range users {
+		users[u] = strconv.Itoa(u)
+	}
+	items := make([]string, p.X.Shape[1])
+	for c := range items {
+		items[c] = strconv.Itoa(c)
+	}
+	return p.heatmap(users, items, standardize)
+}
+
+// Method Heatmap returns the engine's scores as a Heatmap, labeling users
+// and items by their keys.
+func (n *TypedEngine[U, I]) Heatmap(standardize bool) Heatmap {
+	users := make([]string, len(n.users))
+	for u, id := range n.users {
+		users[u] = fmt.Sprint(id)
+	}
+	items := make([]string, len(n.choices))
+	for c, id := range n.choices {
+		items[c] = fmt.Sprint(id)
+	}
+	return n.Engine.heatmap(users, items, standardize)
+}
+
+func (p *Engine) heatmap(users, items []string, standardize bool) Heatmap {
+	h := Heatmap{
+		Users:        users,
+		Items:        items,
+		Scores:       make([][]float64, len(users)),
+		Standardized: standardize,
+	}
+	for u := range h.Scores {
+		h.Scores[u] = make([]float64, len(items))
+		for c := range items {
+			if standardize {
+				h.Scores[u][c] = p.standardizedScore(u, c)
+			} else {
+				h.Scores[u][c] = p.Score(u, c)
+			}
+		}
+	}
+	return h
+}
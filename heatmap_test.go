@@ -0,0 +1,41 @@
+package collaborativepermute
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHeatmap(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	eng.Respond(Query{User: 1, Choices: []int{2, 0}})
+
+	h := eng.Heatmap(false)
+	if len(h.Users) != 2 || h.Items[2] != "2" || h.Scores[1][2] != eng.Score(1, 2) {
+		t.Fatalf("unexpected heatmap %+v", h)
+	}
+	z := eng.Heatmap(true)
+	mean := (z.Scores[1][0] + z.Scores[1][1] + z.Scores[1][2]) / 3
+	if !z.Standardized || math.Abs(mean) > 1e-9 {
+		t.Fatalf("expected standardized scores, got %+v", z)
+	}
+}
+
+func TestNamedHeatmap(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine([]string{"ann", "bob"}, []string{"tea", "coffee"})
+	eng.Respond(NamedQuery{User: "bob", Choices: []string{"coffee", "tea"}})
+
+	encoded, err := json.Marshal(eng.Heatmap(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h Heatmap
+	json.Unmarshal(encoded, &h)
+	if h.Users[1] != "bob" || h.Items[1] != "coffee" ||
+		h.Scores[1][1] <= h.Scores[1][0] {
+		t.Fatalf("unexpected heatmap %s", encoded)
+	}
+}
@@ -0,0 +1,70 @@
+package collaborativepermute
+
+// Struct Snapshot is a read-only copy of an engine at one moment, passed to
+// the OnUpdate and OnQuery callbacks. It is unaffected by later responses, and
+// so may be kept, for instance to serve rankings from a cache.
+type Snapshot struct {
+	engine *Engine
+}
+
+// Method Users returns the number of users.
+func (s Snapshot) Users() int {
+	return s.engine.X.Shape[0]
+}
+
+// Method Choices returns the number of choices.
+func (s Snapshot) Choices() int {
+	return s.engine.X.Shape[1]
+}
+
+// Method Responses returns the number of responses the engine had learned
+// from.
+func (s Snapshot) Responses() int {
+	return len(s.engine.History)
+}
+
+// Method Score returns the engine's score for the choice by the user; see
+// Engine.Score.
+func (s Snapshot) Score(user, choice int) float64 {
+	return s.engine.Score(user, choice)
+}
+
+// Method Rank returns the user's choices from most to least preferred; see
+// Engine.Rank.
+func (s Snapshot) Rank(user int) ([]int, error) {
+	return s.engine.Rank(user)
+}
+
+// Method OnUpdate registers f to be called with a Snapshot after every
+// response the engine learns from, and after it is refit. Callbacks are
+// called in the order they were registered, on the goroutine that updated the
+// engine.
+func (p *Engine) OnUpdate(f func(Snapshot)) {
+	p.onUpdate = append(p.onUpdate, f)
+}
+
+// Method OnQuery registers f to be called with every query returned by
+// Generate, along with a Snapshot of the engine that generated it.
+func (p *Engine) OnQuery(f func(Query, Snapshot)) {
+	p.onQuery = append(p.onQuery, f)
+}
+
+func (p *Engine) notifyUpdate() {
+	if len(p.onUpdate) == 0 {
+		return
+	}
+	s := Snapshot{p.clone()}
+	for _, f := range p.onUpdate {
+		f(s)
+	}
+}
+
+func (p *Engine) notifyQuery(q Query) {
+	if len(p.onQuery) == 0 {
+		return
+	}
+	s := Snapshot{p.clone()}
+	for _, f := range p.onQuery {
+		f(q, s)
+	}
+}
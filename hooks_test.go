@@ -0,0 +1,49 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOnUpdate(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	var snapshots []Snapshot
+	eng.OnUpdate(func(s Snapshot) { snapshots = append(snapshots, s) })
+	eng.Respond(Query{User: 0, Choices: []int{2, 0}})
+	eng.Respond(Query{User: 0, Choices: []int{2, 1}})
+	eng.Refit()
+
+	if len(snapshots) != 3 {
+		t.Fatalf("expected 3 updates, got %d", len(snapshots))
+	}
+	first := snapshots[0]
+	if first.Responses() != 1 || first.Users() != 2 || first.Choices() != 3 {
+		t.Fatalf("unexpected first snapshot")
+	}
+	if first.Score(0, 2) == eng.Score(0, 2) {
+		t.Fatalf("expected the snapshot to be unaffected by later responses")
+	}
+	if rank, _ := snapshots[1].Rank(0); rank[0] != 2 {
+		t.Fatalf("expected choice 2 to rank first, got %v", rank)
+	}
+}
+
+func TestOnQuery(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	var asked []Query
+	eng.OnQuery(func(q Query, s Snapshot) {
+		if s.Responses() != len(eng.History) {
+			t.Fatalf("expected a snapshot of the current engine")
+		}
+		asked = append(asked, q)
+	})
+	q := eng.Generate(1)
+	eng.Respond(q)
+	eng.clone().Generate(1)
+
+	if len(asked) != 1 || asked[0].User != 1 {
+		t.Fatalf("unexpected queries %v", asked)
+	}
+}
@@ -0,0 +1,180 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+// Struct Stats is the response body of GET /surveys/{id}/stats on an Admin;
+// see collaborativepermute.Stats.
+type Stats struct {
+	Responses         int     `json:"responses"`
+	PerUser           []int   `json:"per_user"`
+	Rank              int     `json:"rank"`
+	LastUpdateSeconds float64 `json:"last_update_seconds"`
+	Loss              float64 `json:"loss"`
+}
+
+// Struct Admin is an http.Handler for operating the surveys of a Registry:
+//
+//	GET /surveys/films/stats
+//	  → {"responses": 12, "per_user": [5, 7], "rank": 2, ...}
+//	GET /surveys/films/snapshot
+//	  → the engine, as written by Engine.Save
+//	POST /surveys/films/refit
+//	  → 204 No Content
+//	POST /surveys/films/reset
+//	  → 204 No Content
+//
+// Refitting replays the survey's responses from scratch, and resetting
+// forgets them; both are saved to the Registry's Store, if any, as after a
+// response. Admin is meant to be served apart from the Registry, such as
+// under /admin/ or on an internal port.
+type Admin struct {
+	// Authorize is called before every request, which is refused with 403
+	// Forbidden if it returns an error. If it is nil, every request is
+	// refused. See BearerToken.
+	Authorize func(r *http.Request) error
+
+	registry *Registry
+	mux      *http.ServeMux
+}
+
+// adminEndpoints returns the routes of an Admin.
+func adminEndpoints() []endpoint[*Admin] {
+	return []endpoint[*Admin]{
+		{operation{
+			method: "GET", path: "/surveys/{id}/stats",
+			id:       "getSurveyStats",
+			summary:  "Summarize the state of a survey's engine",
+			response: Stats{}, status: http.StatusOK,
+		}, (*Admin).stats},
+		{operation{
+			method: "GET", path: "/surveys/{id}/snapshot",
+			id:      "getSurveySnapshot",
+			summary: "Download a survey's engine, as written by Engine.Save",
+			status:  http.StatusOK,
+		}, (*Admin).snapshot},
+		{operation{
+			method: "POST", path: "/surveys/{id}/refit",
+			id:      "refitSurvey",
+			summary: "Replay a survey's responses from scratch",
+			status:  http.StatusNoContent,
+		}, (*Admin).refit},
+		{operation{
+			method: "POST", path: "/surveys/{id}/reset",
+			id:      "resetSurvey",
+			summary: "Forget every response to a survey",
+			status:  http.StatusNoContent,
+		}, (*Admin).reset},
+		{operation{
+			method: "GET", path: "/openapi.json",
+			id:       "getOpenAPI",
+			summary:  "Describe this API as an OpenAPI document",
+			response: map[string]any{}, status: http.StatusOK,
+		}, func(a *Admin, w http.ResponseWriter, r *http.Request) {
+			reply(w, http.StatusOK, a.OpenAPI())
+		}},
+	}
+}
+
+// NewAdmin creates an Admin for the surveys of r, allowing the requests that
+// authorize accepts.
+func NewAdmin(r *Registry, authorize func(*http.Request) error) *Admin {
+	a := &Admin{Authorize: authorize, registry: r, mux: http.NewServeMux()}
+	route(a.mux, a, adminEndpoints())
+	return a
+}
+
+// BearerToken returns an Authorize function accepting requests with the
+// header "Authorization: Bearer <token>".
+func BearerToken(token string) func(*http.Request) error {
+	want := []byte("Bearer " + token)
+	return func(r *http.Request) error {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			return fmt.Errorf("invalid or missing bearer token")
+		}
+		return nil
+	}
+}
+
+// Method OpenAPI returns an OpenAPI 3.1 document describing the Admin's
+// endpoints, which is also served at GET /openapi.json.
+func (a *Admin) OpenAPI() map[string]any {
+	return openAPI("collaborativepermute administration",
+		operations(adminEndpoints()))
+}
+
+// Method ServeHTTP authorizes the request, then dispatches it to the
+// matching endpoint.
+func (a *Admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.Authorize == nil {
+		fail(w, http.StatusForbidden, fmt.Errorf("administration is disabled"))
+		return
+	}
+	if err := a.Authorize(r); err != nil {
+		fail(w, http.StatusForbidden, err)
+		return
+	}
+	a.mux.ServeHTTP(w, r)
+}
+
+func (a *Admin) stats(w http.ResponseWriter, r *http.Request) {
+	s, ok := a.registry.survey(w, r)
+	if !ok {
+		return
+	}
+	s.server.Lock.Lock()
+	stats := s.engine.Stats()
+	s.server.Lock.Unlock()
+	reply(w, http.StatusOK, Stats{
+		Responses:         stats.Responses,
+		PerUser:           stats.PerUser,
+		Rank:              stats.Rank,
+		LastUpdateSeconds: stats.LastUpdate.Seconds(),
+		Loss:              stats.Loss,
+	})
+}
+
+func (a *Admin) snapshot(w http.ResponseWriter, r *http.Request) {
+	s, ok := a.registry.survey(w, r)
+	if !ok {
+		return
+	}
+	s.server.Lock.Lock()
+	defer s.server.Lock.Unlock()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", s.ID+".state"))
+	s.engine.Save(w)
+}
+
+func (a *Admin) refit(w http.ResponseWriter, r *http.Request) {
+	a.modify(w, r, func(s *survey) {
+		s.engine.Refit()
+	})
+}
+
+func (a *Admin) reset(w http.ResponseWriter, r *http.Request) {
+	a.modify(w, r, func(s *survey) {
+		s.engine.History = nil
+		s.engine.Refit()
+	})
+}
+
+// modify applies f to the {id} survey with its engine locked. Refit saves
+// the engine to the Store through the Registry's OnUpdate hook, which
+// reports errors to OnError.
+func (a *Admin) modify(w http.ResponseWriter, r *http.Request,
+	f func(*survey)) {
+	s, ok := a.registry.survey(w, r)
+	if !ok {
+		return
+	}
+	s.server.Lock.Lock()
+	defer s.server.Lock.Unlock()
+	f(s)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"net/http"
+	"testing"
+)
+
+// withToken sets the Authorization header of every request to h.
+func withToken(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(w, r)
+	})
+}
+
+func TestAdmin(t *testing.T) {
+	rand.Seed(23)
+	store := &memoryStore{saved: map[string]int{}}
+	r := NewRegistry()
+	r.Store = store
+	do(r, "POST", "/surveys",
+		`{"id": "films", "users": 2, "items": ["Alien", "Brazil", "Cars"]}`)
+	do(r, "POST", "/surveys/films/answers", `{"user": 1, "choices": [2, 0]}`)
+	do(r, "POST", "/surveys/films/answers", `{"user": 0, "choices": [1, 0]}`)
+	admin := withToken(NewAdmin(r, BearerToken("secret")), "secret")
+
+	rec := do(admin, "GET", "/surveys/films/stats", "")
+	var stats Stats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil ||
+		stats.Responses != 2 || stats.PerUser[1] != 1 {
+		t.Fatalf("unexpected stats %d %+v", rec.Code, stats)
+	}
+
+	rec = do(admin, "GET", "/surveys/films/snapshot", "")
+	eng, err := collaborativepermute.Load(rec.Body)
+	if err != nil || len(eng.History) != 2 {
+		t.Fatalf("expected a snapshot of the engine, got %d %v", rec.Code, err)
+	}
+
+	rec = do(admin, "POST", "/surveys/films/refit", "")
+	if rec.Code != http.StatusNoContent || store.saved["films"] != 2 {
+		t.Fatalf("expected the survey to be refit and saved, got %d %v",
+			rec.Code, store.saved)
+	}
+	rec = do(admin, "POST", "/surveys/films/reset", "")
+	if rec.Code != http.StatusNoContent || store.saved["films"] != 0 {
+		t.Fatalf("expected the survey to be reset and saved, got %d %v",
+			rec.Code, store.saved)
+	}
+	rec = do(r, "GET", "/surveys/films/rankings/1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the survey to still be served, got %d", rec.Code)
+	}
+
+	if rec := do(admin, "POST", "/surveys/music/reset", ""); rec.Code !=
+		http.StatusNotFound {
+		t.Fatalf("expected an unknown survey to be missing, got %d", rec.Code)
+	}
+}
+
+func TestAdminAuthorize(t *testing.T) {
+	r := NewRegistry()
+	for _, h := range []http.Handler{
+		NewAdmin(r, BearerToken("secret")),
+		withToken(NewAdmin(r, BearerToken("secret")), "wrong"),
+		withToken(NewAdmin(r, nil), ""),
+	} {
+		if rec := do(h, "GET", "/openapi.json", ""); rec.Code !=
+			http.StatusForbidden {
+			t.Fatalf("expected the request to be forbidden, got %d", rec.Code)
+		}
+	}
+	h := withToken(NewAdmin(r, BearerToken("secret")), "secret")
+	if rec := do(h, "GET", "/openapi.json", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected the request to be allowed, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"html/template"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Struct Item describes a choice shown by a Form: its Label and, optionally,
+// the URL of an Image.
+type Item struct {
+	Label string
+	Image string
+}
+
+// Struct Option is one of the two choices on a FormPage; Index is its choice
+// in the learner.
+type Option struct {
+	Index int
+	Item
+}
+
+// Struct FormPage is the data a Form executes its Template with. The template
+// should post the fields user, choices (both Options' indices), and winner
+// (the chosen one's index) back to the page.
+type FormPage struct {
+	Prompt  string
+	User    int
+	Options [2]Option
+}
+
+// DefaultForm is the Template of a new Form: the prompt above two buttons,
+// each showing an item's image, if any, and label.
+var DefaultForm = template.Must(template.New("form").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Prompt}}</title>
+<style>
+body { font-family: sans-serif; text-align: center; }
+form { display: flex; gap: 1em; justify-content: center; }
+button { flex: 1; max-width: 20em; padding: 1em; font-size: 1.2em; }
+img { display: block; max-width: 100%; margin: 0 auto 0.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Prompt}}</h1>
+<form method="post">
+<input type="hidden" name="user" value="{{.User}}">
+{{range .Options}}<input type="hidden" name="choices" value="{{.Index}}">
+{{end}}{{range .Options}}<button type="submit" name="winner" value="{{.Index}}">
+{{if .Image}}<img src="{{.Image}}" alt="">{{end}}{{.Label}}
+</button>
+{{end}}</form>
+</body>
+</html>
+`))
+
+// Struct Form is an http.Handler that asks people to choose between two
+// items, for a complete preference-collection site:
+//
+//	eng := collaborativepermute.NewEngine(1000, len(items))
+//	http.Handle("/", httpapi.NewForm(eng, items))
+//	http.ListenAndServe(":8080", nil)
+//
+// GET renders a question for the user given by the user query parameter or,
+// without one, for whomever the learner chooses; POST records the answer and
+// redirects back for the next question. GET fails with 409 Conflict if there
+// is no question to ask. Like Server, Form holds Lock while using the
+// learner.
+type Form struct {
+	Lock sync.Locker
+
+	// Prompt is the question asked; by default, "Which do you prefer?".
+	Prompt string
+
+	// Template renders a FormPage; by default, DefaultForm.
+	Template *template.Template
+
+	learner collaborativepermute.Learner
+	items   []Item
+}
+
+// NewForm creates a Form asking about items, which are indexed by choice.
+func NewForm(l collaborativepermute.Learner, items []Item) *Form {
+	return &Form{
+		Lock:     new(sync.Mutex),
+		Prompt:   "Which do you prefer?",
+		Template: DefaultForm,
+		learner:  l,
+		items:    items,
+	}
+}
+
+// Method ServeHTTP renders a question or records an answer.
+func (f *Form) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		f.ask(w, r)
+	case http.MethodPost:
+		f.record(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *Form) ask(w http.ResponseWriter, r *http.Request) {
+	user := -1
+	if v := r.URL.Query().Get("user"); v != "" {
+		var err error
+		if user, err = strconv.Atoi(v); err != nil {
+			http.Error(w, fmt.Sprintf("invalid user %q", v),
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	f.Lock.Lock()
+	if user >= 0 {
+		if _, err := f.learner.Rank(user); err != nil {
+			f.Lock.Unlock()
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	}
+	q, err := generate(f.learner, user)
+	f.Lock.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	page := FormPage{Prompt: f.Prompt, User: q.User}
+	for i, choice := range q.Choices[:2] {
+		if choice >= len(f.items) {
+			http.Error(w, fmt.Sprintf("no item for choice %d", choice),
+				http.StatusInternalServerError)
+			return
+		}
+		page.Options[i] = Option{Index: choice, Item: f.items[choice]}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	f.Template.Execute(w, page)
+}
+
+func (f *Form) record(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user, err := strconv.Atoi(r.PostForm.Get("user"))
+	if err != nil {
+		http.Error(w, "invalid user", http.StatusBadRequest)
+		return
+	}
+	var choices []int
+	for _, v := range r.PostForm["choices"] {
+		choice, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid choice %q", v),
+				http.StatusBadRequest)
+			return
+		}
+		choices = append(choices, choice)
+	}
+	winner, err := strconv.Atoi(r.PostForm.Get("winner"))
+	if err != nil || len(choices) != 2 ||
+		(winner != choices[0] && winner != choices[1]) {
+		http.Error(w, "must choose one of two choices",
+			http.StatusBadRequest)
+		return
+	}
+	if winner == choices[1] {
+		choices[0], choices[1] = choices[1], choices[0]
+	}
+
+	f.Lock.Lock()
+	err = f.learner.Respond(collaborativepermute.Query{
+		User:    user,
+		Choices: choices,
+	})
+	f.Lock.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	// Redirect relative to the page, which may be mounted under any prefix.
+	w.Header().Set("Location", "?"+r.URL.RawQuery)
+	w.WriteHeader(http.StatusSeeOther)
+}
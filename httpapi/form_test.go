@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"html/template"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestForm(t *testing.T) {
+	rand.Seed(23)
+	eng := collaborativepermute.NewEngine(2, 3)
+	f := NewForm(eng, []Item{
+		{Label: "Apples"},
+		{Label: "Bananas", Image: "/bananas.png"},
+		{Label: "Cherries"},
+	})
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest("GET", "/?user=1", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK ||
+		!strings.Contains(body, "Which do you prefer?") ||
+		!strings.Contains(body, `name="user" value="1"`) {
+		t.Fatalf("unexpected form %d %s", rec.Code, body)
+	}
+
+	form := url.Values{"user": {"1"}, "choices": {"0", "1"}, "winner": {"1"}}
+	req := httptest.NewRequest("POST", "/?user=1",
+		strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther ||
+		rec.Header().Get("Location") != "?user=1" {
+		t.Fatalf("expected a redirect to the next question, got %d %v",
+			rec.Code, rec.Header())
+	}
+	if len(eng.History) != 1 || eng.History[0].Choices[0] != 1 {
+		t.Fatalf("expected the answer preferring 1 to be recorded")
+	}
+
+	form.Set("winner", "2")
+	req = httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	f.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a winner outside the choices to be rejected")
+	}
+
+	rec = httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest("GET", "/?user=5", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected an unknown user to be rejected, got %d", rec.Code)
+	}
+
+	single := NewForm(collaborativepermute.NewEngine(1, 1),
+		[]Item{{Label: "Apples"}})
+	rec = httptest.NewRecorder()
+	single.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected Conflict with one item, got %d", rec.Code)
+	}
+}
+
+func TestFormTemplate(t *testing.T) {
+	rand.Seed(23)
+	f := NewForm(collaborativepermute.NewEngine(1, 2),
+		[]Item{{Label: "A"}, {Label: "B"}})
+	f.Template = template.Must(template.New("").Parse(
+		`{{range .Options}}[{{.Label}}]{{end}}`))
+
+	rec := httptest.NewRecorder()
+	f.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if body := rec.Body.String(); body != "[B][A]" && body != "[A][B]" {
+		t.Fatalf("unexpected page %q", body)
+	}
+}
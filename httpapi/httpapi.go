@@ -0,0 +1,244 @@
+// Package httpapi serves a collaborativepermute Learner over HTTP:
+//
+//	POST /questions {"user": 3}
+//	  → {"user": 3, "choices": [4, 1]}
+//	POST /answers {"user": 3, "choices": [1, 4]}
+//	  → 204 No Content
+//	GET /rankings/3
+//	  → {"user": 3, "ranking": [1, 4, 0, 2]}
+//	GET /sessions/3 (WebSocket)
+//	  → {"user": 3, "choices": [4, 1]}
+//	  ← {"user": 3, "choices": [1, 4]}
+//	  → {"user": 3, "choices": [0, 2]} …
+//
+// Omitting the user from POST /questions lets the learner choose whom to ask;
+// if there is no question to ask, as when there are fewer than two choices,
+// it fails with 409 Conflict. Errors are reported as {"error": "..."} with a
+// 4xx status. GET /openapi.json
+// describes the endpoints as an OpenAPI document, from which clients can be
+// generated.
+//
+// Form instead serves an HTML page asking people to choose between two items,
+// and Registry hosts many surveys, each with its own engine, under
+// /surveys/{id}/. Admin lets operators inspect, snapshot, refit, and reset
+// the surveys of a Registry.
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Struct Server is an http.Handler exposing a Learner. Learners are not safe
+// for concurrent use, so the Server holds Lock while using it; replace Lock
+// before serving to share the learner with other goroutines.
+type Server struct {
+	Lock    sync.Locker
+	learner collaborativepermute.Learner
+	mux     *http.ServeMux
+
+	// Upgrader accepts WebSocket sessions; by default, only from pages on
+	// the same origin.
+	Upgrader websocket.Upgrader
+}
+
+// Struct Question is the request body of POST /questions. A nil User lets the
+// learner choose.
+type Question struct {
+	User *int `json:"user,omitempty"`
+}
+
+// Struct Answer is the response body of POST /questions and the request body
+// of POST /answers, with Choices ordered from most to least preferred.
+type Answer struct {
+	User    int   `json:"user"`
+	Choices []int `json:"choices"`
+}
+
+// Struct Ranking is the response body of GET /rankings/{user}.
+type Ranking struct {
+	User    int   `json:"user"`
+	Ranking []int `json:"ranking"`
+}
+
+// serverEndpoints returns the routes of a Server.
+func serverEndpoints() []endpoint[*Server] {
+	return []endpoint[*Server]{
+		{operation{
+			method: "POST", path: "/questions",
+			id:      "generateQuestion",
+			summary: "Choose a question to ask a user, or any user",
+			request: Question{}, requestOptional: true,
+			response: Answer{}, status: http.StatusOK,
+		}, (*Server).questions},
+		{operation{
+			method: "POST", path: "/answers",
+			id:      "submitAnswer",
+			summary: "Learn from a user's answer, most preferred choice first",
+			request: Answer{}, status: http.StatusNoContent,
+		}, (*Server).answers},
+		{operation{
+			method: "GET", path: "/rankings/{user}",
+			id:       "getRanking",
+			summary:  "Rank the choices for a user, most preferred first",
+			response: Ranking{}, status: http.StatusOK,
+		}, (*Server).rankings},
+		{operation{
+			method: "GET", path: "/sessions/{user}",
+			id: "openSession",
+			summary: "Open a WebSocket that pushes questions as Answer " +
+				"messages and accepts answers in the same form",
+			status: http.StatusSwitchingProtocols,
+		}, (*Server).session},
+		{operation{
+			method: "GET", path: "/openapi.json",
+			id:       "getOpenAPI",
+			summary:  "Describe this API as an OpenAPI document",
+			response: map[string]any{}, status: http.StatusOK,
+		}, func(s *Server, w http.ResponseWriter, r *http.Request) {
+			reply(w, http.StatusOK, s.OpenAPI())
+		}},
+	}
+}
+
+// New creates a Server for the learner.
+func New(l collaborativepermute.Learner) *Server {
+	s := &Server{Lock: new(sync.Mutex), learner: l, mux: http.NewServeMux()}
+	route(s.mux, s, serverEndpoints())
+	return s
+}
+
+// Method OpenAPI returns an OpenAPI 3.1 document describing the Server's
+// endpoints, which is also served at GET /openapi.json, so that clients can
+// be generated from it.
+func (s *Server) OpenAPI() map[string]any {
+	return openAPI("collaborativepermute", operations(serverEndpoints()))
+}
+
+// Method ServeHTTP dispatches the request to the matching endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) questions(w http.ResponseWriter, r *http.Request) {
+	var question Question
+	if r.ContentLength != 0 {
+		if err := decode(r, &question); err != nil {
+			fail(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	user := -1
+	if question.User != nil {
+		user = *question.User
+		if _, err := s.learner.Rank(user); err != nil {
+			fail(w, http.StatusNotFound, err)
+			return
+		}
+	}
+	q, err := generate(s.learner, user)
+	if err != nil {
+		fail(w, http.StatusConflict, err)
+		return
+	}
+	reply(w, http.StatusOK, Answer{User: q.User, Choices: q.Choices})
+}
+
+func (s *Server) answers(w http.ResponseWriter, r *http.Request) {
+	var answer Answer
+	if err := decode(r, &answer); err != nil {
+		fail(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	q := collaborativepermute.Query{User: answer.User, Choices: answer.Choices}
+	if err := s.learner.Respond(q); err != nil {
+		fail(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) rankings(w http.ResponseWriter, r *http.Request) {
+	user, err := strconv.Atoi(r.PathValue("user"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, fmt.Errorf("invalid user %q",
+			r.PathValue("user")))
+		return
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	ranking, err := s.learner.Rank(user)
+	if err != nil {
+		fail(w, http.StatusNotFound, err)
+		return
+	}
+	reply(w, http.StatusOK, Ranking{User: user, Ranking: ranking})
+}
+
+// user parses the {user} path parameter and checks that the learner knows it.
+func (s *Server) user(w http.ResponseWriter, r *http.Request) (int, bool) {
+	user, err := strconv.Atoi(r.PathValue("user"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, fmt.Errorf("invalid user %q",
+			r.PathValue("user")))
+		return 0, false
+	}
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	if _, err := s.learner.Rank(user); err != nil {
+		fail(w, http.StatusNotFound, err)
+		return 0, false
+	}
+	return user, true
+}
+
+// generate asks the learner for a question for the user, or any user if it
+// is negative, returning an error where Generate would panic, as when there
+// are fewer than two choices. Learners with a GenerateSafe method, as every
+// learner in collaborativepermute has, are asked through it; otherwise, the
+// panic is recovered.
+func generate(l collaborativepermute.Learner,
+	user int) (q collaborativepermute.Query, err error) {
+	if safe, ok := l.(interface {
+		GenerateSafe(user int) (collaborativepermute.Query, error)
+	}); ok {
+		return safe.GenerateSafe(user)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return l.Generate(user), nil
+}
+
+func decode(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func reply(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func fail(w http.ResponseWriter, status int, err error) {
+	reply(w, status, Error{err.Error()})
+}
@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	rand.Seed(23)
+	s := New(collaborativepermute.NewEngine(2, 3))
+
+	rec := do(s, "POST", "/questions", `{"user": 1}`)
+	var question Answer
+	if err := json.NewDecoder(rec.Body).Decode(&question); err != nil ||
+		rec.Code != http.StatusOK || question.User != 1 ||
+		len(question.Choices) != 2 {
+		t.Fatalf("unexpected question %d %+v", rec.Code, question)
+	}
+	if rec := do(s, "POST", "/questions", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected a question for any user, got %d", rec.Code)
+	}
+
+	rec = do(s, "POST", "/answers", `{"user": 1, "choices": [2, 0]}`)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected the answer to be accepted, got %d %s", rec.Code,
+			rec.Body)
+	}
+
+	rec = do(s, "GET", "/rankings/1", "")
+	var ranking Ranking
+	json.NewDecoder(rec.Body).Decode(&ranking)
+	if rec.Code != http.StatusOK || ranking.Ranking[0] == 0 {
+		t.Fatalf("unexpected ranking %d %+v", rec.Code, ranking)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	rand.Seed(23)
+	s := New(collaborativepermute.NewEngine(2, 3))
+	cases := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/questions", `{"user": 5}`, http.StatusNotFound},
+		{"POST", "/questions", `{"usr": 1}`, http.StatusBadRequest},
+		{"POST", "/answers", `{"user": 0, "choices": [1, 1]}`,
+			http.StatusUnprocessableEntity},
+		{"GET", "/rankings/x", "", http.StatusBadRequest},
+		{"GET", "/rankings/2", "", http.StatusNotFound},
+		{"GET", "/answers", "", http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		if rec := do(s, c.method, c.path, c.body); rec.Code != c.status {
+			t.Fatalf("%s %s: expected %d, got %d %s", c.method, c.path,
+				c.status, rec.Code, rec.Body)
+		}
+	}
+}
+
+// stuckLearner has one user and nothing to ask them.
+type stuckLearner struct{ collaborativepermute.Learner }
+
+func (stuckLearner) Generate(user int) collaborativepermute.Query {
+	panic("Could not find another question")
+}
+
+func (stuckLearner) Rank(user int) ([]int, error) { return nil, nil }
+
+func TestServerNoQuestion(t *testing.T) {
+	cases := []struct {
+		learner collaborativepermute.Learner
+		body    string
+		status  int
+	}{
+		{collaborativepermute.NewEngine(0, 3), "", http.StatusConflict},
+		{collaborativepermute.NewEngine(0, 3), `{"user": 0}`,
+			http.StatusNotFound},
+		{collaborativepermute.NewEngine(1, 1), `{"user": 0}`,
+			http.StatusConflict},
+		{stuckLearner{}, "", http.StatusConflict},
+	}
+	for _, c := range cases {
+		rec := do(New(c.learner), "POST", "/questions", c.body)
+		if rec.Code != c.status {
+			t.Fatalf("%T %q: expected %d, got %d %s", c.learner, c.body,
+				c.status, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package httpapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct Error is the body of every error response.
+type Error struct {
+	Message string `json:"error"`
+}
+
+// operation documents an endpoint: its method and path, a unique ID and
+// summary for generated clients, the types of its request and response
+// bodies (nil for none), and its status on success.
+type operation struct {
+	method, path    string
+	id, summary     string
+	request         any
+	requestOptional bool
+	response        any
+	status          int
+}
+
+// endpoint is an operation together with the method of T that serves it, so
+// that routes and their documentation are defined in one place.
+type endpoint[T any] struct {
+	operation
+	serve func(T, http.ResponseWriter, *http.Request)
+}
+
+// route registers each endpoint on mux, to be served by recv.
+func route[T any](mux *http.ServeMux, recv T, endpoints []endpoint[T]) {
+	for _, e := range endpoints {
+		serve := e.serve
+		mux.HandleFunc(e.method+" "+e.path,
+			func(w http.ResponseWriter, r *http.Request) {
+				serve(recv, w, r)
+			})
+	}
+}
+
+func operations[T any](endpoints []endpoint[T]) []operation {
+	ops := make([]operation, len(endpoints))
+	for i, e := range endpoints {
+		ops[i] = e.operation
+	}
+	return ops
+}
+
+// parameters describes each path parameter, by name.
+var parameters = map[string]map[string]any{
+	"user": {"type": "integer", "minimum": 0},
+	"id":   {"type": "string", "pattern": surveyID.String()},
+}
+
+var pathParameter = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPI returns an OpenAPI 3.1 document describing the operations.
+func openAPI(title string, ops []operation) map[string]any {
+	schemas := map[string]any{}
+	errorSchema := schema(reflect.TypeOf(Error{}), schemas)
+	paths := map[string]any{}
+	for _, op := range ops {
+		doc := map[string]any{
+			"operationId": op.id,
+			"summary":     op.summary,
+		}
+		var params []any
+		for _, m := range pathParameter.FindAllStringSubmatch(op.path, -1) {
+			params = append(params, map[string]any{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   parameters[m[1]],
+			})
+		}
+		if params != nil {
+			doc["parameters"] = params
+		}
+		if op.request != nil {
+			doc["requestBody"] = map[string]any{
+				"required": !op.requestOptional,
+				"content": jsonContent(
+					schema(reflect.TypeOf(op.request), schemas)),
+			}
+		}
+		success := map[string]any{"description": http.StatusText(op.status)}
+		if op.response != nil {
+			success["content"] = jsonContent(
+				schema(reflect.TypeOf(op.response), schemas))
+		}
+		doc["responses"] = map[string]any{
+			strconv.Itoa(op.status): success,
+			"default": map[string]any{
+				"description": "Error",
+				"content":     jsonContent(errorSchema),
+			},
+		}
+
+		path, _ := paths[op.path].(map[string]any)
+		if path == nil {
+			path = map[string]any{}
+			paths[op.path] = path
+		}
+		path[strings.ToLower(op.method)] = doc
+	}
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   title,
+			"version": "1.0.0",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{
+		"schema": schema,
+	}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema returns the JSON Schema of values of type t, as encoded by
+// encoding/json. Named structs are added to schemas and referred to by name.
+func schema(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schema(t.Elem(), schemas)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": schema(t.Elem(), schemas),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schema(t.Elem(), schemas),
+		}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		schemas[t.Name()] = nil // Break cycles.
+		properties := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schema(f.Type, schemas)
+			if !strings.Contains(opts, "omitempty") &&
+				f.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+		obj := map[string]any{"type": "object", "properties": properties}
+		if required != nil {
+			obj["required"] = required
+		}
+		schemas[t.Name()] = obj
+		return ref
+	}
+	return map[string]any{}
+}
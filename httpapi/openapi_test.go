@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"net/http"
+	"testing"
+)
+
+// document is the part of an OpenAPI document checked by the tests.
+type document struct {
+	OpenAPI string `json:"openapi"`
+	Paths   map[string]map[string]struct {
+		OperationID string `json:"operationId"`
+		Parameters  []struct {
+			Name string `json:"name"`
+		} `json:"parameters"`
+		Responses map[string]any `json:"responses"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	s := New(collaborativepermute.NewEngine(1, 2))
+	rec := do(s, "GET", "/openapi.json", "")
+	var doc document
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil ||
+		rec.Code != http.StatusOK || doc.OpenAPI != "3.1.0" {
+		t.Fatalf("unexpected document %d: %v", rec.Code, err)
+	}
+
+	answers := doc.Paths["/answers"]["post"]
+	if answers.OperationID != "submitAnswer" ||
+		answers.Responses["204"] == nil {
+		t.Fatalf("unexpected POST /answers %+v", answers)
+	}
+	if p := doc.Paths["/rankings/{user}"]["get"].Parameters; len(p) != 1 ||
+		p[0].Name != "user" {
+		t.Fatalf("expected a user parameter, got %+v", p)
+	}
+	answer := doc.Components.Schemas["Answer"]
+	if answer.Properties["choices"]["type"] != "array" ||
+		len(answer.Required) != 2 {
+		t.Fatalf("unexpected Answer schema %+v", answer)
+	}
+	question := doc.Components.Schemas["Question"]
+	if len(question.Required) != 0 {
+		t.Fatalf("expected the user of a Question to be optional")
+	}
+}
+
+func TestRegistryOpenAPI(t *testing.T) {
+	rec := do(NewRegistry(), "GET", "/openapi.json", "")
+	var doc document
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	ranking := doc.Paths["/surveys/{id}/rankings/{user}"]["get"]
+	if ranking.OperationID != "surveyGetRanking" ||
+		len(ranking.Parameters) != 2 {
+		t.Fatalf("unexpected survey ranking %+v", ranking)
+	}
+	if doc.Paths["/surveys"]["post"].OperationID != "createSurvey" {
+		t.Fatalf("expected the registry's own endpoints")
+	}
+	if _, ok := doc.Paths["/surveys/{id}/openapi.json"]; ok {
+		t.Fatalf("expected surveys not to repeat the document")
+	}
+}
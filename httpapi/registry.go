@@ -0,0 +1,276 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Struct Survey describes one of the surveys in a Registry: its ID, its
+// number of users, and its items, indexed by choice. It is the request body
+// of POST /surveys, where an empty ID is replaced by a random one and there
+// must be at least one user and two items, and the response body of the
+// survey endpoints.
+type Survey struct {
+	ID    string   `json:"id"`
+	Users int      `json:"users"`
+	Items []string `json:"items"`
+}
+
+// Type Store persists the surveys of a Registry. Save is called when a survey
+// is created and after every response it learns from, while the survey's
+// engine is locked; Delete is called when a survey is deleted. DirStore
+// implements Store.
+type Store interface {
+	Save(s Survey, eng *collaborativepermute.Engine) error
+	Delete(id string) error
+}
+
+// Struct Registry is an http.Handler hosting many surveys, each with its own
+// engine and item list:
+//
+//	POST /surveys {"id": "films", "users": 100, "items": ["Alien", ...]}
+//	  → 201 {"id": "films", "users": 100, "items": ["Alien", ...]}
+//	GET /surveys
+//	  → [{"id": "films", ...}, ...]
+//	GET /surveys/films
+//	  → {"id": "films", ...}
+//	DELETE /surveys/films
+//	  → 204 No Content
+//
+// Every endpoint of Server is available under /surveys/{id}/, such as
+// POST /surveys/films/answers. Survey IDs consist of at most 64 letters,
+// digits, hyphens, and underscores.
+type Registry struct {
+	// Store, if set, persists every survey.
+	Store Store
+
+	// Options configure the engine of each survey created over HTTP.
+	Options []collaborativepermute.Option
+
+	// OnError, if set, is called with every error returned by the Store
+	// while saving a response, which is otherwise ignored.
+	OnError func(id string, err error)
+
+	mu      sync.Mutex
+	surveys map[string]*survey
+	mux     *http.ServeMux
+}
+
+type survey struct {
+	Survey
+	server *Server
+	engine *collaborativepermute.Engine
+}
+
+var surveyID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// registryEndpoints returns the routes of a Registry, other than those of
+// each survey's Server.
+func registryEndpoints() []endpoint[*Registry] {
+	return []endpoint[*Registry]{
+		{operation{
+			method: "POST", path: "/surveys",
+			id:      "createSurvey",
+			summary: "Create a survey, with a random ID if none is given",
+			request: Survey{}, response: Survey{}, status: http.StatusCreated,
+		}, (*Registry).create},
+		{operation{
+			method: "GET", path: "/surveys",
+			id:       "listSurveys",
+			summary:  "List the surveys, ordered by ID",
+			response: []Survey{}, status: http.StatusOK,
+		}, (*Registry).list},
+		{operation{
+			method: "GET", path: "/surveys/{id}",
+			id:       "getSurvey",
+			summary:  "Describe a survey",
+			response: Survey{}, status: http.StatusOK,
+		}, (*Registry).get},
+		{operation{
+			method: "DELETE", path: "/surveys/{id}",
+			id:      "deleteSurvey",
+			summary: "Delete a survey and its responses",
+			status:  http.StatusNoContent,
+		}, (*Registry).delete},
+		{operation{
+			method: "GET", path: "/openapi.json",
+			id:       "getOpenAPI",
+			summary:  "Describe this API as an OpenAPI document",
+			response: map[string]any{}, status: http.StatusOK,
+		}, func(r *Registry, w http.ResponseWriter, req *http.Request) {
+			reply(w, http.StatusOK, r.OpenAPI())
+		}},
+	}
+}
+
+// NewRegistry creates a Registry with no surveys.
+func NewRegistry() *Registry {
+	r := &Registry{surveys: map[string]*survey{}, mux: http.NewServeMux()}
+	route(r.mux, r, registryEndpoints())
+	r.mux.HandleFunc("/surveys/{id}/", r.forward)
+	return r
+}
+
+// Method OpenAPI returns an OpenAPI 3.1 document describing the Registry's
+// endpoints, including those of each survey's Server, which is also served
+// at GET /openapi.json.
+func (r *Registry) OpenAPI() map[string]any {
+	ops := operations(registryEndpoints())
+	for _, op := range operations(serverEndpoints()) {
+		if op.path == "/openapi.json" {
+			continue
+		}
+		op.path = "/surveys/{id}" + op.path
+		op.id = "survey" + strings.ToUpper(op.id[:1]) + op.id[1:]
+		ops = append(ops, op)
+	}
+	return openAPI("collaborativepermute surveys", ops)
+}
+
+// Method Add hosts eng as the survey s, for example after loading it from a
+// Store. It does not save the survey.
+func (r *Registry) Add(s Survey, eng *collaborativepermute.Engine) error {
+	if !surveyID.MatchString(s.ID) {
+		return fmt.Errorf("invalid survey ID %q", s.ID)
+	}
+	if s.Users != eng.X.Shape[0] || len(s.Items) != eng.X.Shape[1] {
+		return fmt.Errorf("survey of %d users and %d items does not match "+
+			"an engine of %d users and %d choices", s.Users, len(s.Items),
+			eng.X.Shape[0], eng.X.Shape[1])
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.surveys[s.ID]; ok {
+		return fmt.Errorf("survey %q already exists", s.ID)
+	}
+	sv := &survey{Survey: s, server: New(eng), engine: eng}
+	eng.OnUpdate(func(collaborativepermute.Snapshot) {
+		// Called from Respond, with the server's lock held.
+		r.mu.Lock()
+		deleted := r.surveys[s.ID] != sv
+		r.mu.Unlock()
+		if r.Store == nil || deleted {
+			return
+		}
+		if err := r.Store.Save(sv.Survey, eng); err != nil &&
+			r.OnError != nil {
+			r.OnError(s.ID, err)
+		}
+	})
+	r.surveys[s.ID] = sv
+	return nil
+}
+
+// Method ServeHTTP dispatches the request to the matching endpoint.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+func (r *Registry) create(w http.ResponseWriter, req *http.Request) {
+	var s Survey
+	if err := decode(req, &s); err != nil {
+		fail(w, http.StatusBadRequest, err)
+		return
+	}
+	if s.ID == "" {
+		var id [8]byte
+		rand.Read(id[:])
+		s.ID = hex.EncodeToString(id[:])
+	}
+	if !surveyID.MatchString(s.ID) {
+		fail(w, http.StatusBadRequest, fmt.Errorf("invalid survey ID %q",
+			s.ID))
+		return
+	}
+	// Surveys cannot grow, so one that could never ask a question is useless.
+	if s.Users < 1 || len(s.Items) < 2 {
+		fail(w, http.StatusBadRequest, fmt.Errorf("must have users [%d] >= 1 "+
+			"and at least two items, got %d", s.Users, len(s.Items)))
+		return
+	}
+	eng, err := collaborativepermute.NewEngineSafe(s.Users, len(s.Items),
+		r.Options...)
+	if err != nil {
+		fail(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := r.Add(s, eng); err != nil {
+		fail(w, http.StatusConflict, err)
+		return
+	}
+	if r.Store != nil {
+		if err := r.Store.Save(s, eng); err != nil {
+			r.mu.Lock()
+			delete(r.surveys, s.ID)
+			r.mu.Unlock()
+			fail(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	reply(w, http.StatusCreated, s)
+}
+
+func (r *Registry) list(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	surveys := make([]Survey, 0, len(r.surveys))
+	for _, s := range r.surveys {
+		surveys = append(surveys, s.Survey)
+	}
+	r.mu.Unlock()
+	sort.Slice(surveys, func(i, j int) bool {
+		return surveys[i].ID < surveys[j].ID
+	})
+	reply(w, http.StatusOK, surveys)
+}
+
+func (r *Registry) get(w http.ResponseWriter, req *http.Request) {
+	if s, ok := r.survey(w, req); ok {
+		reply(w, http.StatusOK, s.Survey)
+	}
+}
+
+func (r *Registry) delete(w http.ResponseWriter, req *http.Request) {
+	s, ok := r.survey(w, req)
+	if !ok {
+		return
+	}
+	s.server.Lock.Lock()
+	defer s.server.Lock.Unlock()
+	if r.Store != nil {
+		if err := r.Store.Delete(s.ID); err != nil {
+			fail(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	r.mu.Lock()
+	delete(r.surveys, s.ID)
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *Registry) forward(w http.ResponseWriter, req *http.Request) {
+	if s, ok := r.survey(w, req); ok {
+		http.StripPrefix("/surveys/"+s.ID, s.server).ServeHTTP(w, req)
+	}
+}
+
+// survey looks up the {id} path parameter.
+func (r *Registry) survey(w http.ResponseWriter, req *http.Request) (*survey,
+	bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.surveys[req.PathValue("id")]
+	if !ok {
+		fail(w, http.StatusNotFound, fmt.Errorf("no survey %q",
+			req.PathValue("id")))
+	}
+	return s, ok
+}
@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"net/http"
+	"testing"
+)
+
+// memoryStore records the surveys saved and deleted.
+type memoryStore struct {
+	saved   map[string]int
+	deleted []string
+}
+
+func (m *memoryStore) Save(s Survey, eng *collaborativepermute.Engine) error {
+	m.saved[s.ID] = len(eng.History)
+	return nil
+}
+
+func (m *memoryStore) Delete(id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	rand.Seed(23)
+	store := &memoryStore{saved: map[string]int{}}
+	r := NewRegistry()
+	r.Store = store
+
+	rec := do(r, "POST", "/surveys",
+		`{"id": "films", "users": 2, "items": ["Alien", "Brazil", "Cars"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected the survey to be created, got %d %s", rec.Code,
+			rec.Body)
+	}
+	rec = do(r, "POST", "/surveys", `{"users": 1, "items": ["x", "y"]}`)
+	var anonymous Survey
+	if err := json.NewDecoder(rec.Body).Decode(&anonymous); err != nil ||
+		anonymous.ID == "" {
+		t.Fatalf("expected a random ID, got %d %+v", rec.Code, anonymous)
+	}
+	rec = do(r, "POST", "/surveys",
+		`{"id": "films", "users": 1, "items": ["x", "y"]}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected a duplicate ID to conflict, got %d", rec.Code)
+	}
+	rec = do(r, "POST", "/surveys",
+		`{"id": "../etc", "users": 1, "items": ["x", "y"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid ID to be rejected, got %d", rec.Code)
+	}
+	for _, body := range []string{
+		`{"id": "nobody", "users": 0, "items": ["x", "y"]}`,
+		`{"id": "single", "users": 1, "items": ["x"]}`,
+	} {
+		if rec := do(r, "POST", "/surveys", body); rec.Code !=
+			http.StatusBadRequest {
+			t.Fatalf("expected %s to be rejected, got %d", body, rec.Code)
+		}
+	}
+
+	rec = do(r, "POST", "/surveys/films/answers",
+		`{"user": 1, "choices": [2, 0]}`)
+	if rec.Code != http.StatusNoContent || store.saved["films"] != 1 {
+		t.Fatalf("expected the answer to be learned and saved, got %d %v",
+			rec.Code, store.saved)
+	}
+	rec = do(r, "GET", "/surveys/films/rankings/1", "")
+	var ranking Ranking
+	json.NewDecoder(rec.Body).Decode(&ranking)
+	if len(ranking.Ranking) != 3 || ranking.Ranking[0] != 2 {
+		t.Fatalf("unexpected ranking %d %+v", rec.Code, ranking)
+	}
+	if rec := do(r, "GET", "/surveys/"+anonymous.ID+"/rankings/0",
+		""); rec.Code != http.StatusOK {
+		t.Fatalf("expected each survey to have its own engine, got %d",
+			rec.Code)
+	}
+
+	rec = do(r, "GET", "/surveys", "")
+	var surveys []Survey
+	json.NewDecoder(rec.Body).Decode(&surveys)
+	if len(surveys) != 2 {
+		t.Fatalf("expected 2 surveys, got %+v", surveys)
+	}
+
+	rec = do(r, "DELETE", "/surveys/films", "")
+	if rec.Code != http.StatusNoContent || len(store.deleted) != 1 {
+		t.Fatalf("expected the survey to be deleted, got %d", rec.Code)
+	}
+	rec = do(r, "GET", "/surveys/films/rankings/1", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected a deleted survey to be gone, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"net/http"
+)
+
+// session serves GET /sessions/{user}, a WebSocket over which the server
+// pushes a question and the client answers it, for as long as the connection
+// is open. Each question is generated as soon as the previous answer has
+// updated the model, so that users can compare choices in rapid succession.
+// Answers are always attributed to the session's user; invalid answers are
+// reported as {"error": "..."} and the question is asked again. If there is
+// no question to ask, the request fails with 409 Conflict, or the session
+// ends with an error message.
+func (s *Server) session(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.user(w, r)
+	if !ok {
+		return
+	}
+	s.Lock.Lock()
+	q, err := generate(s.learner, user)
+	s.Lock.Unlock()
+	if err != nil {
+		fail(w, http.StatusConflict, err)
+		return
+	}
+	conn, err := s.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has already replied with an error.
+	}
+	defer conn.Close()
+
+	for {
+		if err := conn.WriteJSON(Answer{User: q.User, Choices: q.Choices}); err != nil {
+			return
+		}
+		var answer Answer
+		if err := conn.ReadJSON(&answer); err != nil {
+			return
+		}
+
+		s.Lock.Lock()
+		err := s.learner.Respond(collaborativepermute.Query{
+			User:    user,
+			Choices: answer.Choices,
+		})
+		if err == nil {
+			if q, err = generate(s.learner, user); err != nil {
+				s.Lock.Unlock()
+				conn.WriteJSON(Error{err.Error()})
+				return
+			}
+		}
+		s.Lock.Unlock()
+		if err != nil {
+			if err := conn.WriteJSON(Error{err.Error()}); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/gorilla/websocket"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	rand.Seed(23)
+	eng := collaborativepermute.NewEngine(2, 3)
+	server := httptest.NewServer(New(eng))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/sessions/1"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 3; i++ {
+		var question Answer
+		if err := conn.ReadJSON(&question); err != nil {
+			t.Fatal(err)
+		}
+		if question.User != 1 || len(question.Choices) != 2 {
+			t.Fatalf("unexpected question %+v", question)
+		}
+		if err := conn.WriteJSON(question); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var question Answer
+	conn.ReadJSON(&question)
+	conn.WriteJSON(Answer{Choices: []int{0, 0}})
+	var failure struct{ Error string }
+	if err := conn.ReadJSON(&failure); err != nil || failure.Error == "" {
+		t.Fatalf("expected an error for an invalid answer, got %+v", failure)
+	}
+	var again Answer
+	conn.ReadJSON(&again)
+	if again.Choices[0] != question.Choices[0] || len(eng.History) != 3 {
+		t.Fatalf("expected the question to be asked again, got %+v", again)
+	}
+
+	resp, err := http.Get(server.URL + "/sessions/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected NotFound for an unknown user, got %d",
+			resp.StatusCode)
+	}
+
+	single := httptest.NewServer(New(collaborativepermute.NewEngine(1, 1)))
+	defer single.Close()
+	resp, err = http.Get(single.URL + "/sessions/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected Conflict with one choice, got %d", resp.StatusCode)
+	}
+}
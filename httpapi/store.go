@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Type DirStore is a Store keeping each survey in a directory, as the files
+// {id}.json, holding the Survey, and {id}.state, holding the engine as
+// written by Engine.Save. Files are replaced atomically.
+type DirStore string
+
+var _ Store = DirStore("")
+
+// Method Save writes the survey and its engine.
+func (d DirStore) Save(s Survey, eng *collaborativepermute.Engine) error {
+	err := d.write(s.ID+".state", func(w io.Writer) error {
+		return eng.Save(w)
+	})
+	if err != nil {
+		return err
+	}
+	return d.write(s.ID+".json", func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(s)
+	})
+}
+
+// Method Delete removes the survey's files.
+func (d DirStore) Delete(id string) error {
+	for _, name := range []string{id + ".json", id + ".state"} {
+		err := os.Remove(filepath.Join(string(d), name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Method Load adds every survey in the directory to r, restoring each engine
+// with opts.
+func (d DirStore) Load(r *Registry, opts ...collaborativepermute.Option) error {
+	names, err := filepath.Glob(filepath.Join(string(d), "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		id := strings.TrimSuffix(filepath.Base(name), ".json")
+		s, eng, err := d.load(id, opts)
+		if err != nil {
+			return fmt.Errorf("survey %q: %v", id, err)
+		}
+		if err := r.Add(s, eng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d DirStore) load(id string, opts []collaborativepermute.Option) (Survey,
+	*collaborativepermute.Engine, error) {
+	var s Survey
+	data, err := os.ReadFile(filepath.Join(string(d), id+".json"))
+	if err != nil {
+		return s, nil, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, nil, err
+	}
+	f, err := os.Open(filepath.Join(string(d), id+".state"))
+	if err != nil {
+		return s, nil, err
+	}
+	defer f.Close()
+	eng, err := collaborativepermute.Load(f, opts...)
+	return s, eng, err
+}
+
+// write atomically replaces the named file with what fill writes.
+func (d DirStore) write(name string, fill func(io.Writer) error) error {
+	f, err := os.CreateTemp(string(d), "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := fill(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(string(d), name))
+}
@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestDirStore(t *testing.T) {
+	rand.Seed(23)
+	dir := DirStore(t.TempDir())
+	r := NewRegistry()
+	r.Store = dir
+	do(r, "POST", "/surveys",
+		`{"id": "films", "users": 2, "items": ["Alien", "Brazil", "Cars"]}`)
+	do(r, "POST", "/surveys/films/answers",
+		`{"user": 0, "choices": [1, 2]}`)
+
+	restored := NewRegistry()
+	if err := dir.Load(restored); err != nil {
+		t.Fatal(err)
+	}
+	s := restored.surveys["films"]
+	if s == nil || s.Items[1] != "Brazil" || len(s.engine.History) != 1 {
+		t.Fatalf("expected the survey and its response to be restored")
+	}
+	if rank, _ := s.engine.Rank(0); rank[0] != 1 {
+		t.Fatalf("expected the restored engine to be refit, got %v", rank)
+	}
+
+	if err := dir.Delete("films"); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(string(dir)); len(files) != 0 {
+		t.Fatalf("expected no files to remain, got %v", files)
+	}
+}
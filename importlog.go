@@ -0,0 +1,198 @@
+package collaborativepermute
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type Format selects how comparison logs and predictions are encoded.
+type Format int
+
+const (
+	// CSV is comma-separated values with a header row naming the columns.
+	CSV Format = iota
+
+	// JSONLines is one JSON object per line.
+	JSONLines
+)
+
+// Method String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case CSV:
+		return "csv"
+	case JSONLines:
+		return "jsonl"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Struct LogRow is one comparison in a log: User preferred Winner to Loser.
+// Timestamp and Weight are optional; see Query.
+type LogRow struct {
+	User      string    `json:"user"`
+	Winner    string    `json:"winner"`
+	Loser     string    `json:"loser"`
+	Timestamp time.Time `json:"timestamp"`
+	Weight    float64   `json:"weight"`
+}
+
+// Function ImportLog reads a log of comparisons and trains the engine on all
+// of them at once, returning the number imported. Users and choices are
+// referred to by their string IDs; unknown IDs are added to the engine. CSV
+// logs must begin with a header naming the user, winner, and loser columns,
+// and optionally the timestamp (RFC 3339) and weight columns, in any order.
+// JSON lines have the same fields. Every row is validated before any is
+// imported, so a log with an invalid row leaves the engine unchanged. See
+// ImportMapped for CSV exports with other layouts.
+func ImportLog(n *NamedEngine, r io.Reader, format Format) (int, error) {
+	var rows []LogRow
+	var err error
+	switch format {
+	case CSV:
+		rows, err = readCSVLog(r)
+	case JSONLines:
+		rows, err = readJSONLog(r)
+	default:
+		return 0, fmt.Errorf("unknown format %v", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return importRows(n, rows)
+}
+
+// importRows validates the rows, then gives them to the engine with
+// RespondBatch, which audits them and takes a single update step.
+func importRows(n *NamedEngine, rows []LogRow) (int, error) {
+	for i, row := range rows {
+		if err := row.validate(); err != nil {
+			return 0, fmt.Errorf("row %d: %v", i+1, err)
+		}
+	}
+
+	for _, row := range rows {
+		for _, id := range []string{row.Winner, row.Loser} {
+			if _, ok := n.choiceIndex[id]; !ok {
+				n.AddItem(id)
+			}
+		}
+		if _, ok := n.userIndex[row.User]; !ok {
+			n.AddUser(row.User)
+		}
+	}
+	prompts := make([]Query, len(rows))
+	for i, row := range rows {
+		prompts[i] = Query{
+			User:    n.userIndex[row.User],
+			Choices: []int{n.choiceIndex[row.Winner], n.choiceIndex[row.Loser]},
+			Time:    row.Timestamp,
+			Weight:  row.Weight,
+		}
+	}
+	before := len(n.Engine.History)
+	err := n.Engine.RespondBatch(prompts)
+	return len(n.Engine.History) - before, err
+}
+
+func (row LogRow) validate() error {
+	if row.User == "" || row.Winner == "" || row.Loser == "" {
+		return fmt.Errorf("must have a user, winner, and loser")
+	}
+	if row.Winner == row.Loser {
+		return fmt.Errorf("choice %q is compared with itself", row.Winner)
+	}
+	if row.Weight < 0 || math.IsNaN(row.Weight) {
+		return fmt.Errorf("must have weight [%v] >= 0", row.Weight)
+	}
+	return nil
+}
+
+func readCSVLog(r io.Reader) ([]LogRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"user", "winner", "loser"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header must name a %q column", required)
+		}
+	}
+
+	var rows []LogRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		row, err := parseCSVRow(record, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseCSVRow(record []string, columns map[string]int) (LogRow, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row := LogRow{
+		User:   field("user"),
+		Winner: field("winner"),
+		Loser:  field("loser"),
+	}
+	if s := field("timestamp"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return row, fmt.Errorf("invalid timestamp: %v", err)
+		}
+		row.Timestamp = t
+	}
+	if s := field("weight"); s != "" {
+		w, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return row, fmt.Errorf("invalid weight: %v", err)
+		}
+		row.Weight = w
+	}
+	return row, nil
+}
+
+func readJSONLog(r io.Reader) ([]LogRow, error) {
+	var rows []LogRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row LogRow
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
@@ -0,0 +1,81 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestImportLogCSV(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine([]string{"ann"}, []string{"tea"})
+	log := "weight,user,winner,loser,timestamp\n" +
+		",ann,coffee,tea,2024-01-02T15:04:05Z\n" +
+		"2,bob,tea,water,\n" +
+		"0.5, bob, coffee, water,\n"
+	n, err := ImportLog(eng, strings.NewReader(log), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(eng.Users()) != 2 || len(eng.Choices()) != 3 {
+		t.Fatalf("unexpected import of %d rows: %v, %v", n, eng.Users(),
+			eng.Choices())
+	}
+	h := eng.Engine.History
+	if h[0].Time.Year() != 2024 || h[1].Weight != 2 || h[2].Time.IsZero() {
+		t.Fatalf("unexpected history %+v", h)
+	}
+	if ranking, _ := eng.Rank("ann"); ranking[0] != "coffee" {
+		t.Fatalf("expected the engine to be trained, got %v", ranking)
+	}
+}
+
+func TestImportLogJSONLines(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine(nil, nil)
+	var observed metricsRecorder
+	audited := 0
+	eng.Engine.MetricsHook = &observed
+	eng.Engine.Audit = AuditFunc(func(AuditEvent) error {
+		audited++
+		return nil
+	})
+	log := `{"user": "ann", "winner": "tea", "loser": "coffee"}
+
+{"user": "ann", "winner": "tea", "loser": "water", "weight": 3}
+`
+	if n, err := ImportLog(eng, strings.NewReader(log), JSONLines); err != nil || n != 2 {
+		t.Fatalf("unexpected import of %d rows: %v", n, err)
+	}
+	if eng.Engine.History[1].Weight != 3 {
+		t.Fatalf("expected the weight to be imported")
+	}
+	if audited != 2 || len(observed) != 1 || observed[0].Learned != 2 {
+		t.Fatalf("expected both rows to be audited and learned, got %d "+
+			"and %+v", audited, observed)
+	}
+}
+
+func TestImportLogErrors(t *testing.T) {
+	cases := []struct {
+		log    string
+		format Format
+	}{
+		{"user,winner\nann,tea\n", CSV},
+		{"user,winner,loser\nann,tea,tea\n", CSV},
+		{"user,winner,loser,weight\nann,tea,coffee,-1\n", CSV},
+		{"user,winner,loser,timestamp\nann,tea,coffee,yesterday\n", CSV},
+		{"user,winner,loser\nann,tea,coffee\n,tea,coffee\n", CSV},
+		{`{"user": "ann", "winner": "tea", "looser": "coffee"}`, JSONLines},
+		{"", Format(7)},
+	}
+	for _, c := range cases {
+		eng, _ := NewNamedEngine(nil, nil)
+		if _, err := ImportLog(eng, strings.NewReader(c.log), c.format); err == nil {
+			t.Fatalf("expected an error importing %q", c.log)
+		}
+		if len(eng.Users()) != 0 || len(eng.Engine.History) != 0 {
+			t.Fatalf("expected a failed import to leave the engine unchanged")
+		}
+	}
+}
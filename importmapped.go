@@ -0,0 +1,239 @@
+package collaborativepermute
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct Mapping describes the CSV layout of a third-party survey export, so
+// that ImportMapped can read it without a conversion step. Columns are named
+// either by their header, compared case-insensitively, or by their position
+// from 1 as "#3". A Mapping can be loaded from JSON.
+type Mapping struct {
+	// User names the column identifying the respondent.
+	User string `json:"user"`
+
+	// Winner and Loser name the columns holding the preferred and the other
+	// choice, for exports that record the outcome directly.
+	Winner string `json:"winner,omitempty"`
+	Loser  string `json:"loser,omitempty"`
+
+	// Otherwise, First and Second name the columns holding the two choices
+	// shown, and Choice the column recording which was chosen: either that
+	// choice's ID or, if set, FirstValue or SecondValue, such as "A" and "B"
+	// or "left" and "right". Rows whose Choice is one of Ties, such as
+	// "no preference", are skipped.
+	First       string   `json:"first,omitempty"`
+	Second      string   `json:"second,omitempty"`
+	Choice      string   `json:"choice,omitempty"`
+	FirstValue  string   `json:"first_value,omitempty"`
+	SecondValue string   `json:"second_value,omitempty"`
+	Ties        []string `json:"ties,omitempty"`
+
+	// Timestamp and Weight optionally name the columns holding the time and
+	// weight of each response; see Query. TimeLayout is the time.Parse layout
+	// of timestamps, by default RFC 3339.
+	Timestamp  string `json:"timestamp,omitempty"`
+	TimeLayout string `json:"time_layout,omitempty"`
+	Weight     string `json:"weight,omitempty"`
+
+	// Comma is the field delimiter, by default ','.
+	Comma rune `json:"comma,omitempty"`
+
+	// NoHeader means that the first row holds data rather than column names,
+	// and so every column must be named by position.
+	NoHeader bool `json:"no_header,omitempty"`
+}
+
+// Function ImportMapped reads a CSV export laid out as described by m and
+// trains the engine on it as ImportLog does, returning the number of
+// comparisons imported. Every row is validated before any is imported, so an
+// export with an invalid row leaves the engine unchanged.
+func ImportMapped(n *NamedEngine, r io.Reader, m Mapping) (int, error) {
+	if err := m.validate(); err != nil {
+		return 0, err
+	}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if m.Comma != 0 {
+		reader.Comma = m.Comma
+	}
+
+	var header []string
+	line := 1
+	if !m.NoHeader {
+		var err error
+		if header, err = reader.Read(); err != nil {
+			return 0, fmt.Errorf("could not read header: %v", err)
+		}
+		line++
+	}
+	columns := make(map[string]int)
+	for _, name := range m.columns() {
+		i, err := column(header, name)
+		if err != nil {
+			return 0, err
+		}
+		columns[name] = i
+	}
+
+	var rows []LogRow
+	for ; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		row, ok, err := m.parse(record, columns)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %v", line, err)
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+	return importRows(n, rows)
+}
+
+func (m Mapping) validate() error {
+	direct := m.Winner != "" || m.Loser != ""
+	shown := m.First != "" || m.Second != "" || m.Choice != ""
+	switch {
+	case m.User == "":
+		return fmt.Errorf("mapping must name a user column")
+	case direct && shown:
+		return fmt.Errorf("mapping must name either winner and loser " +
+			"columns or first, second, and choice columns, not both")
+	case direct && (m.Winner == "" || m.Loser == ""):
+		return fmt.Errorf("mapping must name both winner and loser columns")
+	case !direct && (m.First == "" || m.Second == "" || m.Choice == ""):
+		return fmt.Errorf("mapping must name first, second, and choice " +
+			"columns")
+	}
+	return nil
+}
+
+// columns returns the names of the columns the mapping uses.
+func (m Mapping) columns() []string {
+	var names []string
+	for _, name := range []string{m.User, m.Winner, m.Loser, m.First,
+		m.Second, m.Choice, m.Timestamp, m.Weight} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// column returns the index of the named column, given by header or by
+// position as "#3".
+func column(header []string, name string) (int, error) {
+	if strings.HasPrefix(name, "#") {
+		i, err := strconv.Atoi(name[1:])
+		if err != nil || i < 1 {
+			return 0, fmt.Errorf("invalid column position %q", name)
+		}
+		return i - 1, nil
+	}
+	if header == nil {
+		return 0, fmt.Errorf("column %q must be named by position, as "+
+			"there is no header", name)
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("header has no %q column", name)
+}
+
+// parse converts a record to a LogRow, returning false if it is a tie.
+func (m Mapping) parse(record []string, columns map[string]int) (LogRow,
+	bool, error) {
+	field := func(name string) (string, error) {
+		if name == "" {
+			return "", nil
+		}
+		i := columns[name]
+		if i >= len(record) {
+			return "", fmt.Errorf("missing column %q", name)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+	var row LogRow
+	var err error
+	if row.User, err = field(m.User); err != nil {
+		return row, false, err
+	}
+
+	if m.Winner != "" {
+		if row.Winner, err = field(m.Winner); err != nil {
+			return row, false, err
+		}
+		if row.Loser, err = field(m.Loser); err != nil {
+			return row, false, err
+		}
+	} else {
+		first, err := field(m.First)
+		if err != nil {
+			return row, false, err
+		}
+		second, err := field(m.Second)
+		if err != nil {
+			return row, false, err
+		}
+		choice, err := field(m.Choice)
+		if err != nil {
+			return row, false, err
+		}
+		for _, tie := range m.Ties {
+			if strings.EqualFold(choice, tie) {
+				return row, false, nil
+			}
+		}
+		switch {
+		case m.FirstValue != "" && strings.EqualFold(choice, m.FirstValue),
+			m.FirstValue == "" && choice == first:
+			row.Winner, row.Loser = first, second
+		case m.SecondValue != "" && strings.EqualFold(choice, m.SecondValue),
+			m.SecondValue == "" && choice == second:
+			row.Winner, row.Loser = second, first
+		default:
+			a, b := first, second
+			if m.FirstValue != "" {
+				a = m.FirstValue
+			}
+			if m.SecondValue != "" {
+				b = m.SecondValue
+			}
+			return row, false, fmt.Errorf("choice %q is neither %q nor %q",
+				choice, a, b)
+		}
+	}
+
+	if s, err := field(m.Timestamp); err != nil {
+		return row, false, err
+	} else if s != "" {
+		layout := m.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		if row.Timestamp, err = time.Parse(layout, s); err != nil {
+			return row, false, fmt.Errorf("invalid timestamp: %v", err)
+		}
+	}
+	if s, err := field(m.Weight); err != nil {
+		return row, false, err
+	} else if s != "" {
+		if row.Weight, err = strconv.ParseFloat(s, 64); err != nil {
+			return row, false, fmt.Errorf("invalid weight: %v", err)
+		}
+	}
+	return row, true, nil
+}
@@ -0,0 +1,83 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestImportMappedShown(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine(nil, nil)
+	export := "Respondent ID;Submitted;Option A;Option B;Selected\n" +
+		"r1;02/01/2024 15:04;tea;coffee;B\n" +
+		"r1;02/01/2024 15:05;tea;water;No preference\n" +
+		"r2;03/01/2024 09:00;water;tea;a\n"
+	n, err := ImportMapped(eng, strings.NewReader(export), Mapping{
+		User:        "respondent id",
+		First:       "Option A",
+		Second:      "Option B",
+		Choice:      "Selected",
+		FirstValue:  "A",
+		SecondValue: "B",
+		Ties:        []string{"no preference"},
+		Timestamp:   "Submitted",
+		TimeLayout:  "02/01/2006 15:04",
+		Comma:       ';',
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := eng.Engine.History
+	if n != 2 || len(h) != 2 || h[0].Time.Month() != 1 {
+		t.Fatalf("unexpected import of %d rows: %+v", n, h)
+	}
+	if ranking, _ := eng.Rank("r1"); ranking[0] != "coffee" {
+		t.Fatalf("expected r1 to prefer coffee, got %v", ranking)
+	}
+	if ranking, _ := eng.Rank("r2"); ranking[0] != "water" {
+		t.Fatalf("expected r2 to prefer water, got %v", ranking)
+	}
+}
+
+func TestImportMappedPositional(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewNamedEngine(nil, nil)
+	export := "ann,tea,coffee,2\nbob,water,tea,1.5\n"
+	n, err := ImportMapped(eng, strings.NewReader(export), Mapping{
+		User: "#1", Winner: "#2", Loser: "#3", Weight: "#4", NoHeader: true,
+	})
+	if err != nil || n != 2 || eng.Engine.History[1].Weight != 1.5 {
+		t.Fatalf("unexpected import of %d rows: %v", n, err)
+	}
+}
+
+func TestImportMappedErrors(t *testing.T) {
+	cases := []struct {
+		export  string
+		mapping Mapping
+	}{
+		{"u,a,b\n", Mapping{User: "u", Winner: "a"}},
+		{"u,a,b\n", Mapping{User: "u", Winner: "a", Loser: "b", First: "a"}},
+		{"u,a,b\n", Mapping{Winner: "a", Loser: "b"}},
+		{"u,a,b\n", Mapping{User: "u", Winner: "a", Loser: "c"}},
+		{"u,a\nann,tea\n", Mapping{User: "#1", Winner: "#2", Loser: "#3",
+			NoHeader: true}},
+		{"ann,tea,coffee\n", Mapping{User: "u", Winner: "#2", Loser: "#3",
+			NoHeader: true}},
+		{"u,a,b,c\nann,tea,coffee,water\n", Mapping{User: "u", First: "a",
+			Second: "b", Choice: "c"}},
+		{"u,a,b\nann,tea,coffee\nann,tea,tea\n", Mapping{User: "u",
+			Winner: "a", Loser: "b"}},
+	}
+	for i, c := range cases {
+		eng, _ := NewNamedEngine(nil, nil)
+		if _, err := ImportMapped(eng, strings.NewReader(c.export),
+			c.mapping); err == nil {
+			t.Fatalf("case %d: expected an error", i)
+		}
+		if len(eng.Engine.History) != 0 {
+			t.Fatalf("case %d: expected nothing to be imported", i)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package ingest feeds a collaborativepermute Engine from a stream of
+// responses, such as a message queue, so that the engine can be deployed
+// without a request/response API at all. Responses are learned in batches
+// with Engine.RespondBatch; subpackage kafka provides a ResponseSource for
+// Kafka topics.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"sync"
+	"time"
+)
+
+// Type ResponseSource delivers responses, one at a time, from some stream.
+type ResponseSource interface {
+	// Next blocks until a response is available or ctx is done. It returns
+	// io.EOF when the stream has ended; any other error stops the
+	// Consumer, so sources should skip messages they cannot decode.
+	Next(ctx context.Context) (collaborativepermute.Query, error)
+
+	// Commit acknowledges every response returned by Next so far, once the
+	// engine has learned from them. Responses that were never committed
+	// should be redelivered, so that none is lost if the process stops.
+	Commit(ctx context.Context) error
+}
+
+// Struct Consumer learns from the responses of a ResponseSource. Engines are
+// not safe for concurrent use, so the Consumer holds Lock while using it;
+// replace Lock before running to share the engine with other goroutines, such
+// as an httpapi.Server.
+type Consumer struct {
+	Lock sync.Locker
+
+	// OnInvalid, if set, is called with the error describing responses the
+	// engine rejected. They are committed, and so not redelivered.
+	OnInvalid func(error)
+
+	source  ResponseSource
+	engine  *collaborativepermute.Engine
+	size    int
+	maxWait time.Duration
+}
+
+// NewConsumer creates a Consumer that learns from batches of up to size
+// responses, waiting at most maxWait after the first response of a batch for
+// the rest.
+func NewConsumer(source ResponseSource, engine *collaborativepermute.Engine,
+	size int, maxWait time.Duration) (*Consumer, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("must have batch size [%d] >= 1", size)
+	}
+	if maxWait < 0 {
+		return nil, fmt.Errorf("must have maximum wait [%v] >= 0", maxWait)
+	}
+	return &Consumer{
+		Lock:    new(sync.Mutex),
+		source:  source,
+		engine:  engine,
+		size:    size,
+		maxWait: maxWait,
+	}, nil
+}
+
+// Method Run consumes responses until the source ends, when it returns nil,
+// or until ctx is done or the source fails, when it returns the error. A batch
+// interrupted by ctx is neither learned nor committed.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		batch, err := c.batch(ctx)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(batch) > 0 {
+			c.Lock.Lock()
+			invalid := c.engine.RespondBatch(batch)
+			c.Lock.Unlock()
+			if invalid != nil && c.OnInvalid != nil {
+				c.OnInvalid(invalid)
+			}
+			if err := c.source.Commit(ctx); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// batch reads the next batch of responses, returning io.EOF along with any
+// responses read before the source ended.
+func (c *Consumer) batch(ctx context.Context) ([]collaborativepermute.Query,
+	error) {
+	q, err := c.source.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := []collaborativepermute.Query{q}
+
+	wait, cancel := context.WithTimeout(ctx, c.maxWait)
+	defer cancel()
+	for len(batch) < c.size {
+		q, err := c.source.Next(wait)
+		switch {
+		case err == nil:
+			batch = append(batch, q)
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded) && wait.Err() != nil:
+			return batch, nil
+		case err == io.EOF:
+			return batch, io.EOF
+		default:
+			return nil, err
+		}
+	}
+	return batch, nil
+}
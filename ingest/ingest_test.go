@@ -0,0 +1,107 @@
+package ingest
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// channelSource delivers the responses sent on a channel, ending when it is
+// closed, and reports the number delivered on each commit.
+type channelSource struct {
+	responses chan collaborativepermute.Query
+	delivered int
+	commits   chan int
+}
+
+func (s *channelSource) Next(ctx context.Context) (collaborativepermute.Query,
+	error) {
+	select {
+	case <-ctx.Done():
+		return collaborativepermute.Query{}, ctx.Err()
+	case q, ok := <-s.responses:
+		if !ok {
+			return q, io.EOF
+		}
+		s.delivered++
+		return q, nil
+	}
+}
+
+func (s *channelSource) Commit(ctx context.Context) error {
+	s.commits <- s.delivered
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	rand.Seed(23)
+	source := &channelSource{
+		responses: make(chan collaborativepermute.Query, 5),
+		commits:   make(chan int, 5),
+	}
+	for _, q := range []collaborativepermute.Query{
+		{User: 0, Choices: []int{1, 0}},
+		{User: 1, Choices: []int{2, 0}},
+		{User: 9, Choices: []int{2, 0}},
+		{User: 1, Choices: []int{2, 1}},
+		{User: 0, Choices: []int{1, 2}},
+	} {
+		source.responses <- q
+	}
+	close(source.responses)
+
+	eng := collaborativepermute.NewEngine(2, 3)
+	c, err := NewConsumer(source, eng, 2, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalid := 0
+	c.OnInvalid = func(error) { invalid++ }
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	close(source.commits)
+	var commits []int
+	for n := range source.commits {
+		commits = append(commits, n)
+	}
+	if len(commits) != 3 || commits[2] != 5 {
+		t.Fatalf("expected batches of 2, 2, and 1, got commits %v", commits)
+	}
+	if len(eng.History) != 4 || invalid != 1 {
+		t.Fatalf("expected 4 responses learned and 1 rejected, got %d, %d",
+			len(eng.History), invalid)
+	}
+}
+
+func TestConsumerMaxWait(t *testing.T) {
+	source := &channelSource{
+		responses: make(chan collaborativepermute.Query, 1),
+		commits:   make(chan int, 1),
+	}
+	eng := collaborativepermute.NewEngine(1, 2)
+	c, err := NewConsumer(source, eng, 10, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Run(ctx) }()
+
+	source.responses <- collaborativepermute.Query{Choices: []int{1, 0}}
+	if n := <-source.commits; n != 1 {
+		t.Fatalf("expected a partial batch of 1, got %d", n)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("expected the consumer to stop when canceled, got %v", err)
+	}
+
+	if _, err := NewConsumer(source, eng, 0, 0); err == nil {
+		t.Fatalf("expected an error for an empty batch size")
+	}
+}
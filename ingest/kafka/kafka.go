@@ -0,0 +1,117 @@
+// Package kafka is an ingest.ResponseSource reading responses from a Kafka
+// topic as JSON-encoded collaborativepermute.Query values, such as
+//
+//	{"user": 3, "choices": [1, 4], "weight": 2}
+//
+// For example, to learn from the "responses" topic in batches of 100:
+//
+//	reader := kafkago.NewReader(kafkago.ReaderConfig{
+//		Brokers: []string{"localhost:9092"},
+//		GroupID: "permute",
+//		Topic:   "responses",
+//	})
+//	c, err := ingest.NewConsumer(kafka.New(reader), eng, 100, time.Second)
+//	...
+//	err = c.Run(ctx)
+//
+// Messages are committed only after the engine learns from them, so
+// responses are delivered at least once. Publisher writes questions in the
+// same format, for dispatch.Dispatcher.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/dispatch"
+	"github.com/fatlotus/collaborativepermute/ingest"
+	kafkago "github.com/segmentio/kafka-go"
+	"strconv"
+)
+
+// reader is the subset of *kafkago.Reader used by Source.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafkago.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafkago.Message) error
+}
+
+// Struct Source reads responses from a Kafka consumer group.
+type Source struct {
+	// OnMalformed, if set, is called with each message that is not a valid
+	// JSON response. Such messages are skipped, and committed with the rest.
+	OnMalformed func(kafkago.Message, error)
+
+	reader  reader
+	pending []kafkago.Message
+}
+
+var _ ingest.ResponseSource = (*Source)(nil)
+
+// New creates a Source reading from r, which should belong to a consumer
+// group so that commits are recorded.
+func New(r *kafkago.Reader) *Source {
+	return &Source{reader: r}
+}
+
+// Method Next fetches messages until one holds a response.
+func (s *Source) Next(ctx context.Context) (collaborativepermute.Query,
+	error) {
+	for {
+		m, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			return collaborativepermute.Query{}, err
+		}
+		s.pending = append(s.pending, m)
+		var q collaborativepermute.Query
+		if err := json.Unmarshal(m.Value, &q); err != nil {
+			if s.OnMalformed != nil {
+				s.OnMalformed(m, err)
+			}
+			continue
+		}
+		return q, nil
+	}
+}
+
+// Method Commit commits every message fetched so far.
+func (s *Source) Commit(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if err := s.reader.CommitMessages(ctx, s.pending...); err != nil {
+		return err
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// writer is the subset of *kafkago.Writer used by Publisher.
+type writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+}
+
+// Struct Publisher writes questions to a Kafka topic as JSON, keyed by the
+// decimal user, so that each user's questions share a partition.
+type Publisher struct {
+	writer writer
+}
+
+var _ dispatch.Publisher = (*Publisher)(nil)
+
+// NewPublisher creates a Publisher writing with w.
+func NewPublisher(w *kafkago.Writer) *Publisher {
+	return &Publisher{writer: w}
+}
+
+// Method Publish writes q.
+func (p *Publisher) Publish(ctx context.Context,
+	q collaborativepermute.Query) error {
+	value, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafkago.Message{
+		Key:   []byte(strconv.Itoa(q.User)),
+		Value: value,
+	})
+}
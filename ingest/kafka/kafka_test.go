@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/ingest"
+	kafkago "github.com/segmentio/kafka-go"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeReader delivers fixed messages, then io.EOF.
+type fakeReader struct {
+	messages  []kafkago.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafkago.Message,
+	error) {
+	if len(r.messages) == 0 {
+		return kafkago.Message{}, io.EOF
+	}
+	m := r.messages[0]
+	r.messages = r.messages[1:]
+	return m, nil
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context,
+	msgs ...kafkago.Message) error {
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+func TestSource(t *testing.T) {
+	r := &fakeReader{messages: []kafkago.Message{
+		{Offset: 0, Value: []byte(`{"user": 1, "choices": [1, 0]}`)},
+		{Offset: 1, Value: []byte(`not json`)},
+		{Offset: 2, Value: []byte(`{"user": 0, "choices": [0, 1]}`)},
+	}}
+	s := &Source{reader: r}
+	malformed := 0
+	s.OnMalformed = func(kafkago.Message, error) { malformed++ }
+
+	eng := collaborativepermute.NewEngine(2, 2)
+	c, err := ingest.NewConsumer(s, eng, 10, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(eng.History) != 2 || eng.History[0].User != 1 || malformed != 1 {
+		t.Fatalf("expected 2 responses and 1 malformed message")
+	}
+	if len(r.committed) != 3 {
+		t.Fatalf("expected every message to be committed, got %v",
+			r.committed)
+	}
+}
+
+// fakeWriter records the messages written.
+type fakeWriter struct {
+	messages []kafkago.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context,
+	msgs ...kafkago.Message) error {
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func TestPublisher(t *testing.T) {
+	w := &fakeWriter{}
+	p := &Publisher{writer: w}
+	q := collaborativepermute.Query{User: 12, Choices: []int{3, 1}}
+	if err := p.Publish(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.messages) != 1 || string(w.messages[0].Key) != "12" {
+		t.Fatalf("expected a message keyed by user, got %+v", w.messages)
+	}
+
+	// Frontends answer in the format questions are published in.
+	r := &fakeReader{messages: w.messages}
+	answer, err := (&Source{reader: r}).Next(context.Background())
+	if err != nil || answer.User != 12 || answer.Choices[0] != 3 {
+		t.Fatalf("unexpected round trip %+v, %v", answer, err)
+	}
+}
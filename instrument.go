@@ -0,0 +1,82 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"time"
+)
+
+// Struct UpdateMetrics measures one update, for exporting to a monitoring
+// system.
+type UpdateMetrics struct {
+	// Update counts the updates since the engine was created or refit, and
+	// Responses is the number of responses in History.
+	Update, Responses int
+
+	// Learned is the number of responses given to Respond or RespondBatch
+	// that this update learned from for the first time. It is zero for the
+	// updates replayed by a refit.
+	Learned int
+
+	// Duration is how long the update took, and SVD how much of it was spent
+	// in the proximal step, which for the default NuclearNorm is an SVD.
+	Duration, SVD time.Duration
+
+	// Loss is the mean loss over History after the update.
+	Loss float64
+}
+
+// Type MetricsHook receives UpdateMetrics after every update.
+type MetricsHook interface {
+	ObserveUpdate(m UpdateMetrics)
+}
+
+// WithMetrics reports UpdateMetrics to the hook after every update, as well
+// as to any hook added before it. Computing the loss costs as much as an
+// update, and refitting the engine replays its updates, reporting each of them
+// again.
+func WithMetrics(h MetricsHook) Option {
+	return func(p *Engine) error {
+		if h == nil {
+			return fmt.Errorf("must have a non-nil metrics hook")
+		}
+		p.addMetricsHook(h)
+		return nil
+	}
+}
+
+// Type MetricsHooks is a MetricsHook that reports to each hook in turn.
+type MetricsHooks []MetricsHook
+
+// Method ObserveUpdate reports m to each hook.
+func (hooks MetricsHooks) ObserveUpdate(m UpdateMetrics) {
+	for _, h := range hooks {
+		h.ObserveUpdate(m)
+	}
+}
+
+// addMetricsHook reports to h in addition to the current MetricsHook.
+func (p *Engine) addMetricsHook(h MetricsHook) {
+	switch hooks := p.MetricsHook.(type) {
+	case nil:
+		p.MetricsHook = h
+	case MetricsHooks:
+		p.MetricsHook = append(hooks[:len(hooks):len(hooks)], h)
+	default:
+		p.MetricsHook = MetricsHooks{hooks, h}
+	}
+}
+
+// observeUpdate reports the update just finished to the MetricsHook, if any.
+func (p *Engine) observeUpdate(samps []Query, loss float64) {
+	if p.MetricsHook == nil {
+		return
+	}
+	p.MetricsHook.ObserveUpdate(UpdateMetrics{
+		Update:    p.updates,
+		Responses: len(samps),
+		Learned:   p.learned,
+		Duration:  p.lastUpdate,
+		SVD:       p.lastProx,
+		Loss:      loss,
+	})
+}
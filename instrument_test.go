@@ -0,0 +1,37 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type metricsRecorder []UpdateMetrics
+
+func (r *metricsRecorder) ObserveUpdate(m UpdateMetrics) {
+	*r = append(*r, m)
+}
+
+func TestWithMetrics(t *testing.T) {
+	rand.Seed(23)
+	var observed metricsRecorder
+	eng := NewEngine(2, 3, WithMetrics(&observed))
+	eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	eng.Respond(Query{User: 1, Choices: []int{2, 1}})
+	eng.clone().Respond(Query{User: 1, Choices: []int{2, 0}})
+
+	if len(observed) != 2 {
+		t.Fatalf("expected 2 updates to be observed, got %d", len(observed))
+	}
+	m := observed[1]
+	if m.Update != 2 || m.Responses != 2 || m.Learned != 1 ||
+		m.Duration < m.SVD || m.Loss != eng.loss(eng.History) {
+		t.Fatalf("unexpected metrics %+v", m)
+	}
+	eng.Refit()
+	if m := observed[len(observed)-1]; m.Learned != 0 {
+		t.Fatalf("expected a refit to learn nothing new, got %+v", m)
+	}
+	if _, err := NewEngineSafe(1, 1, WithMetrics(nil)); err == nil {
+		t.Fatalf("expected an error for a nil hook")
+	}
+}
@@ -0,0 +1,34 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithLatencyWeight weights each response in the loss by f(Latency), so
+// that, for example, answers given too quickly to have been considered count
+// for less. Responses without a recorded Latency are weighted normally.
+func WithLatencyWeight(f func(latency time.Duration) float64) Option {
+	return func(p *Engine) error {
+		p.LatencyWeight = f
+		return nil
+	}
+}
+
+// LatencyRamp returns a latency weighting that ignores responses faster than
+// min, fully trusts responses slower than full, and increases linearly in
+// between.
+func LatencyRamp(min, full time.Duration) (func(time.Duration) float64, error) {
+	if min < 0 || full <= min {
+		return nil, fmt.Errorf("must have 0 <= min [%v] < full [%v]", min, full)
+	}
+	return func(latency time.Duration) float64 {
+		switch {
+		case latency <= min:
+			return 0
+		case latency >= full:
+			return 1
+		}
+		return float64(latency-min) / float64(full-min)
+	}, nil
+}
@@ -0,0 +1,33 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLatencyWeight(t *testing.T) {
+	rand.Seed(23)
+	ramp, err := LatencyRamp(time.Second, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := ramp(2 * time.Second); w != 0.5 {
+		t.Fatalf("expected a weight of 0.5 halfway up the ramp, got %v", w)
+	}
+
+	eng := NewEngine(1, 3, WithLatencyWeight(ramp))
+	for i := 0; i < 10; i++ {
+		// Careful answers prefer 0 over 1; random clicks say otherwise.
+		eng.Respond(Query{Choices: []int{0, 1}, Latency: 5 * time.Second})
+		eng.Respond(Query{Choices: []int{1, 0}, Latency: 200 * time.Millisecond})
+		eng.Respond(Query{Choices: []int{1, 0}, Latency: 300 * time.Millisecond})
+	}
+	if eng.Score(0, 0) <= eng.Score(0, 1) {
+		t.Fatalf("expected fast answers to be ignored, got %v", eng.X.Data)
+	}
+
+	if _, err := LatencyRamp(time.Second, time.Second); err == nil {
+		t.Fatalf("expected an error for an empty ramp")
+	}
+}
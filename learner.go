@@ -0,0 +1,82 @@
+package collaborativepermute
+
+import (
+	"sort"
+)
+
+// Interface Learner is implemented by every engine in this package, so that
+// they can be swapped for one another and compared on the same data.
+type Learner interface {
+	// Generate creates a new Query to display to the user. If user is
+	// negative, the learner may ask any user.
+	Generate(user int) Query
+
+	// Respond takes a completed Query and updates the learner's beliefs.
+	Respond(prompt Query) error
+
+	// Rank returns the choices ordered from most to least preferred by the
+	// given user.
+	Rank(user int) ([]int, error)
+}
+
+var _ Learner = (*Engine)(nil)
+
+// rankBy returns the indices 0..n-1 sorted by descending score, breaking ties
+// by index.
+func rankBy(n int, score func(int) float64) []int {
+	order := make([]int, n)
+	scores := make([]float64, n)
+	for i := range order {
+		order[i] = i
+		scores[i] = score(i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	return order
+}
+
+// sampleQuery draws a question for user, or for any of the given number of
+// users if user is negative, asking about each ordered pair of the choices
+// with probability proportional to weight(u, a, b); random returns a uniform
+// number in [0, 1). The choices are returned from highest to lowest score,
+// and ok is false if there is no pair to ask about.
+func sampleQuery(users, choices, user int, random func() float64,
+	weight func(u, a, b int) float64,
+	score func(u, c int) float64) (q Query, ok bool) {
+	candidates := make([]Query, 0)
+	sum := 0.0
+	for u := 0; u < users; u++ {
+		if user >= 0 && user != u {
+			continue
+		}
+		for a := 0; a < choices; a++ {
+			for b := 0; b < choices; b++ {
+				if a == b {
+					continue
+				}
+				w := weight(u, a, b)
+				sum += w
+				candidates = append(candidates, Query{
+					User:    u,
+					Choices: []int{a, b},
+					weight:  w,
+				})
+			}
+		}
+	}
+
+	offset := random() * sum
+	for _, option := range candidates {
+		if offset < option.weight {
+			if score(option.User, option.Choices[0]) <
+				score(option.User, option.Choices[1]) {
+				option.Choices[0], option.Choices[1] =
+					option.Choices[1], option.Choices[0]
+			}
+			return option, true
+		}
+		offset -= option.weight
+	}
+	return Query{}, false
+}
@@ -0,0 +1,40 @@
+package collaborativepermute
+
+import (
+	"testing"
+)
+
+func TestLearnerGenerateSafe(t *testing.T) {
+	btl, _ := NewBTLEngine(2, 1, 1)
+	elo, _ := NewEloEngine(2, 1)
+	gaussian, _ := NewGaussianEngine(2, 1)
+	variational, _ := NewVariationalEngine(2, 1, 1)
+	mixture, _ := NewMixtureEngine(2, 1, 1)
+	ensemble, _ := NewEnsemble(NewEngine(2, 1))
+	learners := map[string]interface {
+		GenerateSafe(user int) (Query, error)
+	}{
+		"Engine":            NewEngine(2, 1),
+		"BTLEngine":         btl,
+		"EloEngine":         elo,
+		"GaussianEngine":    gaussian,
+		"VariationalEngine": variational,
+		"MixtureEngine":     mixture,
+		"Ensemble":          ensemble,
+	}
+
+	for name, l := range learners {
+		if _, err := l.GenerateSafe(-1); err != ErrNoQuestion {
+			t.Fatalf("%s: expected ErrNoQuestion with one choice, got %v",
+				name, err)
+		}
+		if _, err := l.GenerateSafe(1); err != ErrNoQuestion {
+			t.Fatalf("%s: expected ErrNoQuestion for the user, got %v",
+				name, err)
+		}
+		if _, err := l.GenerateSafe(2); err == nil || err == ErrNoQuestion {
+			t.Fatalf("%s: expected an error for an unknown user, got %v",
+				name, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package collaborativepermute
+
+// Responses may rank more than two choices at once, listing them from most to
+// least preferred. By default, a ranking of k choices is trained as the
+// k(k-1)/2 pairwise responses it implies, each under the engine's Loss. A
+// ListLoss instead scores the whole ranking at once, which uses the
+// information in an ordered list more efficiently and typically converges in
+// fewer responses.
+
+// Type ListLoss is a listwise loss, measuring how badly the model disagrees
+// with a ranking given the scores of its choices, listed from most to least
+// preferred.
+type ListLoss interface {
+	// Value returns the loss of the ranking.
+	Value(scores []float64) float64
+
+	// Gradient returns the gradient of Value with respect to each score.
+	Gradient(scores []float64) []float64
+}
+
+type listMLE struct{}
+
+func (listMLE) Value(scores []float64) float64 {
+	return -PlackettLuce(scores)
+}
+
+func (listMLE) Gradient(scores []float64) []float64 {
+	gradient := plackettLuceGradient(scores)
+	for i := range gradient {
+		gradient[i] = -gradient[i]
+	}
+	return gradient
+}
+
+// ListMLE is the negative log-likelihood of the ranking under the
+// Plackett-Luce model (Xia et al., ICML '08). For two choices it is the same
+// as Logistic.
+var ListMLE ListLoss = listMLE{}
+
+// WithListLoss trains each response with the given listwise loss, in place
+// of decomposing it into pairs under the engine's Loss.
+func WithListLoss(l ListLoss) Option {
+	return func(p *Engine) error {
+		p.ListLoss = l
+		return nil
+	}
+}
+
+// pairs returns each pair (a, b) implied by the ranking, in which a is
+// preferred over b.
+func pairs(choices []int) [][2]int {
+	result := make([][2]int, 0, len(choices)*(len(choices)-1)/2)
+	for i, a := range choices {
+		for _, b := range choices[i+1:] {
+			result = append(result, [2]int{a, b})
+		}
+	}
+	return result
+}
+
+// scores returns the engine's scores for the choices of the query, in order.
+func (p *Engine) scores(q Query) []float64 {
+	result := make([]float64, len(q.Choices))
+	for i, c := range q.Choices {
+		result[i] = p.scoreIn(q.User, c, q.Context)
+	}
+	return result
+}
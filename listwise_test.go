@@ -0,0 +1,37 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestListwise(t *testing.T) {
+	rand.Seed(23)
+	pairwise := NewEngine(2, 6)
+	listwise := NewEngine(2, 6, WithListLoss(ListMLE))
+	for i := 0; i < 10; i++ {
+		ranking := rand.Perm(6)[:4]
+		sort.Ints(ranking)
+		q := Query{User: i % 2, Choices: ranking}
+		if err := pairwise.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+		if err := listwise.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, eng := range []*Engine{pairwise, listwise} {
+		if acc := eng.accuracy(eng.History); acc < 0.9 {
+			t.Fatalf("expected the rankings to be fit, got accuracy %v", acc)
+		}
+	}
+
+	if err := listwise.Respond(Query{Choices: []int{1, 2, 1}}); err == nil {
+		t.Fatalf("expected an error for a repeated choice")
+	}
+	if err := listwise.Respond(Query{Choices: []int{1}}); err == nil {
+		t.Fatalf("expected an error for a single choice")
+	}
+}
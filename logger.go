@@ -0,0 +1,63 @@
+package collaborativepermute
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+)
+
+// Type Logger receives the engine's notable events. A *slog.Logger satisfies
+// it.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// WithLogger reports notable events to l:
+//
+//   - at debug level, each completed update;
+//   - at info level, whenever the effective rank of X (see Stats) changes by
+//     more than rankThreshold since it was last reported;
+//   - at warning level, whenever an update leaves X with NaN or infinite
+//     scores, as happens when the step size is too large.
+//
+// Tracking the rank costs an SVD per update.
+func WithLogger(l Logger, rankThreshold int) Option {
+	return func(p *Engine) error {
+		if l == nil {
+			return fmt.Errorf("must have a non-nil logger")
+		}
+		if rankThreshold < 0 {
+			return fmt.Errorf("must have rank threshold [%d] >= 0",
+				rankThreshold)
+		}
+		p.Logger, p.RankThreshold = l, rankThreshold
+		return nil
+	}
+}
+
+// logUpdate reports the update just finished to the Logger, if any.
+func (p *Engine) logUpdate(ctx context.Context, samps []Query) {
+	if p.Logger == nil {
+		return
+	}
+	p.Logger.Log(ctx, slog.LevelDebug, "update completed",
+		"update", p.updates, "responses", len(samps),
+		"duration", p.lastUpdate)
+
+	for _, x := range p.X.Data {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			p.Logger.Log(ctx, slog.LevelWarn, "scores are not finite",
+				"update", p.updates, "nu", p.Nu)
+			return
+		}
+	}
+
+	rank := effectiveRank(p.backend(), p.X)
+	if change := rank - p.lastRank; change > p.RankThreshold ||
+		-change > p.RankThreshold {
+		p.Logger.Log(ctx, slog.LevelInfo, "rank changed",
+			"update", p.updates, "from", p.lastRank, "to", rank)
+		p.lastRank = rank
+	}
+}
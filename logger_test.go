@@ -0,0 +1,35 @@
+package collaborativepermute
+
+import (
+	"bytes"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	rand.Seed(23)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))
+	eng := NewEngine(2, 3, WithLogger(logger, 0))
+	eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	eng.Respond(Query{User: 1, Choices: []int{0, 1}})
+
+	logs := buf.String()
+	if strings.Count(logs, `msg="update completed"`) != 2 ||
+		strings.Count(logs, `msg="rank changed" update=1 from=0 to=1`) != 1 ||
+		strings.Count(logs, `msg="rank changed"`) != 1 {
+		t.Fatalf("unexpected logs:\n%s", logs)
+	}
+
+	buf.Reset()
+	unstable := NewEngine(1, 2, WithLogger(logger, 0), WithNu(1e308))
+	unstable.Respond(Query{Choices: []int{0, 1}})
+	unstable.Respond(Query{Choices: []int{1, 0}})
+	if !strings.Contains(buf.String(), "level=WARN") {
+		t.Fatalf("expected a warning about non-finite scores:\n%s",
+			buf.String())
+	}
+}
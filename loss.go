@@ -0,0 +1,100 @@
+package collaborativepermute
+
+import (
+	"math"
+)
+
+// Type Loss is a pairwise loss, measuring how badly the model disagrees with a
+// single response in terms of how far the preferred choice's score leads the
+// other's.
+type Loss interface {
+	// Value returns the loss when the preferred choice leads by diff, given
+	// the margin demanded for the response.
+	Value(diff, margin float64) float64
+
+	// Derivative returns a subgradient of Value with respect to diff.
+	Derivative(diff, margin float64) float64
+}
+
+type hinge struct{}
+
+func (hinge) Value(diff, margin float64) float64 {
+	return math.Max(margin-diff, 0)
+}
+
+func (hinge) Derivative(diff, margin float64) float64 {
+	if diff < margin {
+		return -1
+	}
+	return 0
+}
+
+type squaredHinge struct{}
+
+func (squaredHinge) Value(diff, margin float64) float64 {
+	h := math.Max(margin-diff, 0)
+	return h * h
+}
+
+func (squaredHinge) Derivative(diff, margin float64) float64 {
+	return -2 * math.Max(margin-diff, 0)
+}
+
+type logistic struct{}
+
+func (logistic) Value(diff, margin float64) float64 {
+	// log(1 + e^-diff), rearranged to avoid overflow.
+	if diff < 0 {
+		return -diff + math.Log1p(math.Exp(diff))
+	}
+	return math.Log1p(math.Exp(-diff))
+}
+
+func (logistic) Derivative(diff, margin float64) float64 {
+	return -sigmoid(-diff)
+}
+
+var (
+	// Hinge is the loss from (Wang KDD '14), which only penalizes responses
+	// whose scores are separated by less than the margin. This is the default.
+	Hinge Loss = hinge{}
+
+	// SquaredHinge is the square of Hinge, which penalizes large violations
+	// more heavily and is smooth at the margin.
+	SquaredHinge Loss = squaredHinge{}
+
+	// Logistic is the negative log-likelihood of the Bradley-Terry model, in
+	// which a user prefers a over b with probability σ(Score(a) - Score(b)).
+	// It ignores the margin, but makes Probability calibrated.
+	Logistic Loss = logistic{}
+)
+
+// WithLoss sets the pairwise loss function that the engine minimizes.
+func WithLoss(l Loss) Option {
+	return func(p *Engine) error {
+		p.Loss = l
+		return nil
+	}
+}
+
+// Method Probability returns the modeled probability that the user prefers
+// choice a over choice b, that is, σ(Score(a) - Score(b)). The result is only
+// calibrated when the engine is trained with the Logistic loss.
+func (p *Engine) Probability(user, a, b int) float64 {
+	return sigmoid(p.Score(user, a) - p.Score(user, b))
+}
+
+func (p *Engine) lossFunc() Loss {
+	if p.Loss == nil {
+		return Hinge
+	}
+	return p.Loss
+}
+
+func sigmoid(x float64) float64 {
+	if x < 0 {
+		e := math.Exp(x)
+		return e / (1 + e)
+	}
+	return 1 / (1 + math.Exp(-x))
+}
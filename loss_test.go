@@ -0,0 +1,39 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLossDerivatives(t *testing.T) {
+	for _, l := range []Loss{Hinge, SquaredHinge, Logistic} {
+		for _, diff := range []float64{-2, -0.3, 0.4, 0.9, 1.7} {
+			h := 1e-6
+			numeric := (l.Value(diff+h, 1) - l.Value(diff-h, 1)) / (2 * h)
+			if math.Abs(numeric-l.Derivative(diff, 1)) > 1e-4 {
+				t.Errorf("%T: derivative at %v is %v, expected %v",
+					l, diff, l.Derivative(diff, 1), numeric)
+			}
+		}
+	}
+}
+
+func TestLogisticProbability(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(1, 2, WithLoss(Logistic))
+	for i := 0; i < 30; i++ {
+		if i%4 == 0 {
+			eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		} else {
+			eng.Respond(Query{User: 0, Choices: []int{1, 0}})
+		}
+	}
+
+	if p := eng.Probability(0, 1, 0); p <= 0.5 || p >= 1 {
+		t.Fatalf("expected a probability above one half, got %v", p)
+	}
+	if math.Abs(eng.Probability(0, 1, 0)+eng.Probability(0, 0, 1)-1) > 1e-9 {
+		t.Fatalf("expected complementary probabilities to sum to one")
+	}
+}
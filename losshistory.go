@@ -0,0 +1,56 @@
+package collaborativepermute
+
+// Struct LossRecord holds the training loss after one update.
+type LossRecord struct {
+	// Update counts the updates since the engine was created or refit,
+	// starting from one; it equals the number of responses in History at the
+	// time.
+	Update int
+
+	// Loss is the mean loss over History, and Objective adds the
+	// regularization penalty on X, which is what each update minimizes.
+	Loss, Objective float64
+}
+
+// WithLossHistory records a LossRecord in LossHistory after every update, for
+// plotting convergence. Computing the loss costs as much as an update, so it
+// is off by default.
+func WithLossHistory() Option {
+	return func(p *Engine) error {
+		p.RecordLoss = true
+		return nil
+	}
+}
+
+// WithLossCallback calls f with a LossRecord after every update, for
+// reporting convergence to a dashboard as it happens. Refitting the engine
+// replays its updates, and so calls f again for each of them.
+func WithLossCallback(f func(LossRecord)) Option {
+	return func(p *Engine) error {
+		p.OnLoss = f
+		return nil
+	}
+}
+
+// recordLoss computes and reports the loss after an update on the responses.
+func (p *Engine) recordLoss(samps []Query) {
+	if !p.RecordLoss && p.OnLoss == nil && p.MetricsHook == nil {
+		return
+	}
+	loss := p.loss(samps)
+	p.observeUpdate(samps, loss)
+	if !p.RecordLoss && p.OnLoss == nil {
+		return
+	}
+	record := LossRecord{
+		Update:    p.updates,
+		Loss:      loss,
+		Objective: loss + p.regularizer().Penalty(p.X, p.lambda(len(samps))),
+	}
+	if p.RecordLoss {
+		p.LossHistory = append(p.LossHistory, record)
+	}
+	if p.OnLoss != nil {
+		p.OnLoss(record)
+	}
+}
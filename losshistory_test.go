@@ -0,0 +1,36 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLossHistory(t *testing.T) {
+	rand.Seed(23)
+	calls := 0
+	eng := NewEngine(2, 3, WithLossHistory(),
+		WithLossCallback(func(LossRecord) { calls++ }))
+	for i := 0; i < 10; i++ {
+		eng.Respond(Query{User: i % 2, Choices: []int{0, 2}})
+	}
+
+	if len(eng.LossHistory) != 10 || calls != 10 {
+		t.Fatalf("expected 10 records, got %d and %d calls",
+			len(eng.LossHistory), calls)
+	}
+	for i, record := range eng.LossHistory {
+		if record.Update != i+1 || record.Loss < 0 {
+			t.Fatalf("expected record %d to be numbered, got %+v", i, record)
+		}
+	}
+	last := eng.LossHistory[9]
+	if last.Objective <= last.Loss {
+		t.Fatalf("expected the objective to include the penalty, got %+v", last)
+	}
+
+	eng.Refit()
+	if len(eng.LossHistory) != 10 {
+		t.Fatalf("expected a refit to start over, got %d records",
+			len(eng.LossHistory))
+	}
+}
@@ -0,0 +1,125 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+	"math"
+)
+
+// The belief matrices are stored densely in row-major order. The helpers below
+// reuse the spare capacity of a matrix's backing slice wherever possible, so
+// that an engine created WithCapacity can grow without reallocating.
+
+// reserve returns a copy of a whose backing slice has room for rows×cols
+// entries.
+func reserve(a gauss.Array, rows, cols int) gauss.Array {
+	if cap(a.Data) >= rows*cols {
+		return a
+	}
+	data := make([]float64, len(a.Data), rows*cols)
+	copy(data, a.Data)
+	a.Data = data
+	return a
+}
+
+// resize copies the overlapping region of a into a matrix of the given
+// dimensions, filling any new entries with zero.
+func resize(a gauss.Array, rows, cols int) gauss.Array {
+	oldRows, oldCols := a.Shape[0], a.Shape[1]
+	if cap(a.Data) < rows*cols || rows < oldRows || cols < oldCols {
+		result := gauss.Zero(rows, cols)
+		for i := 0; i < rows && i < oldRows; i++ {
+			for j := 0; j < cols && j < oldCols; j++ {
+				*result.I(i, j) = *a.I(i, j)
+			}
+		}
+		return result
+	}
+
+	// Grow in place, moving rows from the back so that nothing is overwritten
+	// before it has been copied.
+	a.Data = a.Data[:rows*cols]
+	for i := oldRows - 1; i >= 0 && cols != oldCols; i-- {
+		copy(a.Data[i*cols:i*cols+oldCols], a.Data[i*oldCols:(i+1)*oldCols])
+	}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if i >= oldRows || j >= oldCols {
+				a.Data[i*cols+j] = 0
+			}
+		}
+	}
+	a.Shape = []int{rows, cols}
+	return a
+}
+
+// clone returns a copy of a that shares no storage with it.
+func clone(a gauss.Array) gauss.Array {
+	result := gauss.Zero(a.Shape...)
+	copy(result.Data, a.Data)
+	return result
+}
+
+// zero returns a zero matrix of the given dimensions, reusing a's storage.
+func zero(a gauss.Array, rows, cols int) gauss.Array {
+	if cap(a.Data) < rows*cols {
+		return gauss.Zero(rows, cols)
+	}
+	a.Data = a.Data[:rows*cols]
+	for i := range a.Data {
+		a.Data[i] = 0
+	}
+	a.Shape = []int{rows, cols}
+	return a
+}
+
+// assign copies the contents of src into dst's storage, returning src itself
+// if dst is too small to hold it.
+func assign(dst, src gauss.Array) gauss.Array {
+	if cap(dst.Data) < len(src.Data) {
+		return src
+	}
+	dst.Data = dst.Data[:len(src.Data)]
+	copy(dst.Data, src.Data)
+	dst.Shape = append([]int(nil), src.Shape...)
+	return dst
+}
+
+// solve returns the solution X to A·X = B by Gaussian elimination with partial
+// pivoting, where A is square. Neither argument is modified.
+func solve(a, b [][]float64) ([][]float64, error) {
+	n := len(a)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = append(append([]float64(nil), a[i]...), b[i]...)
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("singular system")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := 0; row < n; row++ {
+			if row == col {
+				continue
+			}
+			f := m[row][col] / m[col][col]
+			for k := col; k < len(m[row]); k++ {
+				m[row][k] -= f * m[col][k]
+			}
+		}
+	}
+	result := make([][]float64, n)
+	for i := range result {
+		result[i] = m[i][n:]
+		for k := range result[i] {
+			result[i][k] /= m[i][i]
+		}
+	}
+	return result, nil
+}
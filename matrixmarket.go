@@ -0,0 +1,48 @@
+package collaborativepermute
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Method WriteMatrixMarket writes X, the users×choices matrix of learned
+// preferences, to w in the MatrixMarket exchange format read by MATLAB's
+// mmread and SciPy's scipy.io.mmread. If sparse is set, it uses the
+// coordinate format, listing only the non-zero entries; otherwise it uses the
+// array format, listing every entry in column-major order.
+func (p *Engine) WriteMatrixMarket(w io.Writer, sparse bool) error {
+	users, choices := p.X.Shape[0], p.X.Shape[1]
+	out := bufio.NewWriter(w)
+	format := func(x float64) string {
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+
+	if sparse {
+		nonzero := 0
+		for _, x := range p.X.Data {
+			if x != 0 {
+				nonzero++
+			}
+		}
+		fmt.Fprintf(out, "%%%%MatrixMarket matrix coordinate real general\n")
+		fmt.Fprintf(out, "%d %d %d\n", users, choices, nonzero)
+		for u := 0; u < users; u++ {
+			for c := 0; c < choices; c++ {
+				if x := *p.X.I(u, c); x != 0 {
+					fmt.Fprintf(out, "%d %d %s\n", u+1, c+1, format(x))
+				}
+			}
+		}
+	} else {
+		fmt.Fprintf(out, "%%%%MatrixMarket matrix array real general\n")
+		fmt.Fprintf(out, "%d %d\n", users, choices)
+		for c := 0; c < choices; c++ {
+			for u := 0; u < users; u++ {
+				fmt.Fprintf(out, "%s\n", format(*p.X.I(u, c)))
+			}
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,32 @@
+package collaborativepermute
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestWriteMatrixMarket(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	*eng.X.I(0, 1) = 1.5
+	*eng.X.I(1, 0) = -2
+
+	var buf bytes.Buffer
+	if err := eng.WriteMatrixMarket(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	dense := "%%MatrixMarket matrix array real general\n2 3\n0\n-2\n1.5\n0\n0\n0\n"
+	if buf.String() != dense {
+		t.Fatalf("unexpected dense matrix:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := eng.WriteMatrixMarket(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	sparse := "%%MatrixMarket matrix coordinate real general\n2 3 2\n1 2 1.5\n2 1 -2\n"
+	if buf.String() != sparse {
+		t.Fatalf("unexpected sparse matrix:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,141 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// The functions below compare a predicted ranking against a reference, where
+// each ranking is a permutation of the choices 0..n-1 listed from most to
+// least preferred, as returned by Rank.
+
+// Function Discordant returns the number of pairs of choices that the two
+// rankings order differently, also known as the Kendall tau distance.
+func Discordant(predicted, reference []int) (int, error) {
+	pos, err := positions(predicted, reference)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i, a := range reference {
+		for _, b := range reference[i+1:] {
+			if pos[a] > pos[b] {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// Function KendallTau returns the Kendall rank correlation between the two
+// rankings: 1 if they agree on every pair, -1 if they disagree on every pair.
+func KendallTau(predicted, reference []int) (float64, error) {
+	d, err := Discordant(predicted, reference)
+	if err != nil {
+		return 0, err
+	}
+	n := len(reference)
+	if n < 2 {
+		return 1, nil
+	}
+	pairs := n * (n - 1) / 2
+	return 1 - 2*float64(d)/float64(pairs), nil
+}
+
+// Function Spearman returns the Spearman rank correlation between the two
+// rankings, which, unlike KendallTau, penalizes a choice more the further it
+// is from its reference position.
+func Spearman(predicted, reference []int) (float64, error) {
+	pos, err := positions(predicted, reference)
+	if err != nil {
+		return 0, err
+	}
+	n := len(reference)
+	if n < 2 {
+		return 1, nil
+	}
+	sum := 0.0
+	for i, c := range reference {
+		d := float64(pos[c] - i)
+		sum += d * d
+	}
+	return 1 - 6*sum/float64(n*(n*n-1)), nil
+}
+
+// Function NDCG returns the normalized discounted cumulative gain of the top
+// k choices of the predicted ranking, given the graded relevance of each
+// choice: 1 if the top k are the k most relevant choices in order, and less
+// the more relevant choices are missing or placed too low. A choice at
+// position i (from zero) gains (2^relevance - 1)/log₂(i + 2).
+func NDCG(predicted []int, relevance []float64, k int) (float64, error) {
+	if k <= 0 {
+		return 0, fmt.Errorf("must have k [%d] > 0", k)
+	}
+	if len(relevance) != len(predicted) {
+		return 0, fmt.Errorf("must have len(relevance) [%d] == %d",
+			len(relevance), len(predicted))
+	}
+	if _, err := positions(predicted, predicted); err != nil {
+		return 0, err
+	}
+	ideal := append([]float64(nil), relevance...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+
+	dcg, idcg := 0.0, 0.0
+	for i := 0; i < k && i < len(predicted); i++ {
+		discount := math.Log2(float64(i + 2))
+		dcg += (math.Exp2(relevance[predicted[i]]) - 1) / discount
+		idcg += (math.Exp2(ideal[i]) - 1) / discount
+	}
+	if idcg == 0 {
+		return 1, nil
+	}
+	return dcg / idcg, nil
+}
+
+// Function PrecisionAtK returns the fraction of the top k choices of the
+// predicted ranking that are in the relevant set.
+func PrecisionAtK(predicted, relevant []int, k int) (float64, error) {
+	if k <= 0 || k > len(predicted) {
+		return 0, fmt.Errorf("must have 0 < k [%d] <= %d", k, len(predicted))
+	}
+	set := make(map[int]bool, len(relevant))
+	for _, c := range relevant {
+		set[c] = true
+	}
+	hits := 0
+	for _, c := range predicted[:k] {
+		if set[c] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(k), nil
+}
+
+// positions checks that both rankings are permutations of the same choices,
+// and returns the position of each choice in the predicted ranking.
+func positions(predicted, reference []int) ([]int, error) {
+	if len(predicted) != len(reference) {
+		return nil, fmt.Errorf("must have len(predicted) [%d] == %d",
+			len(predicted), len(reference))
+	}
+	pos := make([]int, len(predicted))
+	for i := range pos {
+		pos[i] = -1
+	}
+	for i, c := range predicted {
+		if c < 0 || c >= len(pos) || pos[c] >= 0 {
+			return nil, fmt.Errorf("predicted ranking is not a permutation")
+		}
+		pos[c] = i
+	}
+	seen := make([]bool, len(reference))
+	for _, c := range reference {
+		if c < 0 || c >= len(seen) || seen[c] {
+			return nil, fmt.Errorf("reference ranking is not a permutation")
+		}
+		seen[c] = true
+	}
+	return pos, nil
+}
@@ -0,0 +1,59 @@
+package collaborativepermute
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRankCorrelations(t *testing.T) {
+	reference := []int{0, 1, 2, 3}
+	cases := []struct {
+		predicted     []int
+		discordant    int
+		tau, spearman float64
+	}{
+		{[]int{0, 1, 2, 3}, 0, 1, 1},
+		{[]int{3, 2, 1, 0}, 6, -1, -1},
+		{[]int{1, 0, 2, 3}, 1, 2.0 / 3, 0.8},
+	}
+	for _, c := range cases {
+		d, _ := Discordant(c.predicted, reference)
+		tau, _ := KendallTau(c.predicted, reference)
+		rho, _ := Spearman(c.predicted, reference)
+		if d != c.discordant || math.Abs(tau-c.tau) > 1e-9 ||
+			math.Abs(rho-c.spearman) > 1e-9 {
+			t.Fatalf("%v: expected %d, %v, %v, got %d, %v, %v", c.predicted,
+				c.discordant, c.tau, c.spearman, d, tau, rho)
+		}
+	}
+
+	if _, err := KendallTau([]int{0, 0, 1, 2}, reference); err == nil {
+		t.Fatalf("expected an error for a repeated choice")
+	}
+	if _, err := Spearman([]int{0, 1}, reference); err == nil {
+		t.Fatalf("expected an error for mismatched lengths")
+	}
+}
+
+func TestTopKMetrics(t *testing.T) {
+	relevance := []float64{3, 2, 0, 1}
+	perfect, _ := NDCG([]int{0, 1, 3, 2}, relevance, 4)
+	swapped, _ := NDCG([]int{1, 0, 3, 2}, relevance, 4)
+	tail, _ := NDCG([]int{0, 1, 2, 3}, relevance, 2)
+	if perfect != 1 || swapped >= 1 || tail != 1 {
+		t.Fatalf("expected 1, less than 1, and 1, got %v, %v, and %v",
+			perfect, swapped, tail)
+	}
+
+	precision, _ := PrecisionAtK([]int{0, 2, 1, 3}, []int{0, 1}, 2)
+	if precision != 0.5 {
+		t.Fatalf("expected precision 0.5, got %v", precision)
+	}
+
+	if _, err := NDCG([]int{0, 1}, relevance, 2); err == nil {
+		t.Fatalf("expected an error for mismatched relevance")
+	}
+	if _, err := PrecisionAtK([]int{0, 1}, nil, 3); err == nil {
+		t.Fatalf("expected an error for k past the end")
+	}
+}
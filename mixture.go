@@ -0,0 +1,206 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Struct MixtureEngine models each user as a soft combination of a small
+// number of archetype rankings. Archetype k gives choice j the strength
+// Archetypes[k][j], and Weights[u][k] is the posterior probability that user
+// u answers like archetype k, so that
+//
+//	P(u prefers a over b) = Σ_k Weights[u][k]·σ(Archetypes[k][a] - Archetypes[k][b]).
+//
+// When many users share tastes, each archetype pools the responses of all of
+// its users, and a user's Weights make an interpretable taste profile. The
+// model is fit by expectation-maximization over the History, with gradient
+// ascent (step size Rate, ridge penalty Lambda) in the maximization step.
+type MixtureEngine struct {
+	Archetypes, Weights [][]float64
+	Rate, Lambda, T     float64
+	Iterations          int
+	History             []Query
+}
+
+// NewMixtureEngine allocates a mixture learner with k archetypes for the
+// given corpus size.
+func NewMixtureEngine(users, choices, k int) (*MixtureEngine, error) {
+	if users < 0 || choices < 0 || k < 1 {
+		return nil, fmt.Errorf("must have users [%d] >= 0, choices [%d] >= 0 "+
+			"and k [%d] >= 1", users, choices, k)
+	}
+
+	// Archetypes start out small and random so that they can specialize.
+	init := rand.New(rand.NewSource(1))
+	archetypes := make([][]float64, k)
+	for i := range archetypes {
+		archetypes[i] = make([]float64, choices)
+		for j := range archetypes[i] {
+			archetypes[i][j] = 0.01 * init.NormFloat64()
+		}
+	}
+	weights := make([][]float64, users)
+	for u := range weights {
+		weights[u] = make([]float64, k)
+		for i := range weights[u] {
+			weights[u][i] = 1 / float64(k)
+		}
+	}
+	return &MixtureEngine{
+		Archetypes: archetypes,
+		Weights:    weights,
+		Rate:       0.5,
+		Lambda:     0.01,
+		T:          1,
+		Iterations: 5,
+		History:    make([]Query, 0),
+	}, nil
+}
+
+var _ Learner = (*MixtureEngine)(nil)
+
+// Method TasteProfile returns the user's weight on each archetype.
+func (p *MixtureEngine) TasteProfile(user int) []float64 {
+	return append([]float64(nil), p.Weights[user]...)
+}
+
+// Method Score returns the user's expected strength for the choice, averaged
+// over archetypes.
+func (p *MixtureEngine) Score(user, choice int) float64 {
+	sum := 0.0
+	for k, w := range p.Weights[user] {
+		sum += w * p.Archetypes[k][choice]
+	}
+	return sum
+}
+
+// Method Probability returns the modeled probability that the user prefers
+// choice a over choice b.
+func (p *MixtureEngine) Probability(user, a, b int) float64 {
+	sum := 0.0
+	for k, w := range p.Weights[user] {
+		sum += w * sigmoid(p.Archetypes[k][a]-p.Archetypes[k][b])
+	}
+	return sum
+}
+
+// Method Respond takes a completed Query and refits the mixture.
+func (p *MixtureEngine) Respond(prompt Query) error {
+	if len(prompt.Choices) != 2 {
+		return fmt.Errorf("can only handle binary rankings")
+	}
+	if prompt.User < 0 || prompt.User >= len(p.Weights) {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			prompt.User, len(p.Weights))
+	}
+	for _, choice := range prompt.Choices {
+		if choice < 0 || choice >= len(p.Archetypes[0]) {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d",
+				choice, len(p.Archetypes[0]))
+		}
+	}
+	if prompt.Choices[0] == prompt.Choices[1] {
+		return fmt.Errorf("choice %d is ranked twice", prompt.Choices[0])
+	}
+	p.History = append(p.History, prompt)
+	for i := 0; i < p.Iterations; i++ {
+		p.expectation()
+		p.maximization()
+	}
+	return nil
+}
+
+// expectation sets each user's Weights to the posterior over archetypes given
+// their responses, under a prior equal to the archetypes' overall popularity.
+func (p *MixtureEngine) expectation() {
+	k := len(p.Archetypes)
+	prior := make([]float64, k)
+	for _, w := range p.Weights {
+		for i := range prior {
+			prior[i] += w[i] / float64(len(p.Weights))
+		}
+	}
+
+	logLikelihood := make([][]float64, len(p.Weights))
+	for u := range logLikelihood {
+		logLikelihood[u] = make([]float64, k)
+		for i := range logLikelihood[u] {
+			logLikelihood[u][i] = math.Log(math.Max(prior[i], 1e-12))
+		}
+	}
+	for _, q := range p.History {
+		for i, theta := range p.Archetypes {
+			diff := theta[q.Choices[0]] - theta[q.Choices[1]]
+			logLikelihood[q.User][i] -= Logistic.Value(diff, 0)
+		}
+	}
+	for u, ll := range logLikelihood {
+		norm := logSumExp(ll)
+		for i := range ll {
+			p.Weights[u][i] = math.Exp(ll[i] - norm)
+		}
+	}
+}
+
+// maximization takes a gradient step on each archetype, weighting every
+// response by its user's membership.
+func (p *MixtureEngine) maximization() {
+	for i, theta := range p.Archetypes {
+		gradient := make([]float64, len(theta))
+		for j := range gradient {
+			gradient[j] = -p.Lambda * theta[j]
+		}
+		for _, q := range p.History {
+			a, b := q.Choices[0], q.Choices[1]
+			g := p.Weights[q.User][i] * sigmoid(theta[b]-theta[a])
+			gradient[a] += g
+			gradient[b] -= g
+		}
+		for j := range theta {
+			theta[j] += p.Rate * gradient[j]
+		}
+	}
+}
+
+// Method Generate creates a new Query to display to the user, preferring the
+// pairs whose outcome is least certain. If user is non-negative, only return
+// queries for that user. Generate panics if there is no question to ask;
+// GenerateSafe returns an error instead.
+func (p *MixtureEngine) Generate(user int) Query {
+	q, err := p.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (p *MixtureEngine) GenerateSafe(user int) (Query, error) {
+	if user >= len(p.Weights) {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, len(p.Weights))
+	}
+	q, ok := sampleQuery(len(p.Weights), len(p.Archetypes[0]), user, rand.Float64,
+		func(u, a, b int) float64 {
+			return math.Exp(-math.Abs(p.Score(u, a)-p.Score(u, b)) / p.T)
+		}, p.Score)
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	return q, nil
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user.
+func (p *MixtureEngine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= len(p.Weights) {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, len(p.Weights))
+	}
+	return rankBy(len(p.Archetypes[0]), func(choice int) float64 {
+		return p.Score(user, choice)
+	}), nil
+}
@@ -0,0 +1,41 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMixtureArchetypes(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewMixtureEngine(6, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 48; i++ {
+		u := i % 6
+		a, b := rand.Intn(4), rand.Intn(4)
+		if a == b {
+			continue
+		}
+		// Half the users prefer low-numbered choices; the rest, high.
+		if (a < b) != (u < 3) {
+			a, b = b, a
+		}
+		eng.Respond(Query{User: u, Choices: []int{a, b}})
+	}
+
+	low, high := eng.TasteProfile(0), eng.TasteProfile(5)
+	if (low[0] > 0.5) == (high[0] > 0.5) {
+		t.Fatalf("expected users 0 and 5 to follow different archetypes, "+
+			"got %v and %v", low, high)
+	}
+	if ranking, _ := eng.Rank(1); ranking[0] != 0 {
+		t.Fatalf("expected user 1 to prefer choice 0, got %v", ranking)
+	}
+	if ranking, _ := eng.Rank(4); ranking[0] != 3 {
+		t.Fatalf("expected user 4 to prefer choice 3, got %v", ranking)
+	}
+	if err := eng.Respond(Query{Choices: []int{1, 1}}); err == nil {
+		t.Fatalf("expected an error for a choice compared with itself")
+	}
+}
@@ -0,0 +1,135 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct MultiEngine learns preferences along several criteria at once, such
+// as the quality, value, and style of each choice.
+//
+// Internally, the criteria are the slices of a users × choices × criteria
+// tensor, unfolded side by side into a single Engine with choices·criteria
+// columns. The nuclear norm of the unfolding couples the criteria through
+// shared user factors, so a user's answers about one criterion inform the
+// others, while each criterion keeps its own ranking.
+type MultiEngine struct {
+	// Engine holds the unfolded tensor; choice c of criterion k is column
+	// k·choices + c. Options passed to NewMultiEngine apply to it directly.
+	Engine *Engine
+
+	criteria, choices int
+}
+
+// NewMultiEngine allocates a learning engine for the given corpus size, in
+// which every choice is scored along the given number of criteria.
+func NewMultiEngine(users, choices, criteria int,
+	opts ...Option) (*MultiEngine, error) {
+	if criteria <= 0 {
+		return nil, fmt.Errorf("must have criteria [%d] > 0", criteria)
+	}
+	if choices < 0 {
+		return nil, fmt.Errorf("must have choices [%d] >= 0", choices)
+	}
+	eng, err := NewEngineSafe(users, choices*criteria, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiEngine{Engine: eng, criteria: criteria, choices: choices}, nil
+}
+
+// Method AddUser appends a new user to the engine, returning its index.
+func (m *MultiEngine) AddUser() int {
+	return m.Engine.AddUser()
+}
+
+// Method Score returns the engine's belief about how strongly the given user
+// prefers the given choice along the given criterion.
+func (m *MultiEngine) Score(criterion, user, choice int) float64 {
+	return m.Engine.Score(user, m.column(criterion, choice))
+}
+
+// Method Respond takes a completed Query about the given criterion and
+// updates the engine's beliefs.
+func (m *MultiEngine) Respond(criterion int, prompt Query) error {
+	if err := m.validate(criterion); err != nil {
+		return err
+	}
+	columns := make([]int, len(prompt.Choices))
+	for i, c := range prompt.Choices {
+		if c < 0 || c >= m.choices {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d", c, m.choices)
+		}
+		columns[i] = m.column(criterion, c)
+	}
+	prompt.Choices = columns
+	return m.Engine.Respond(prompt)
+}
+
+// Method Generate creates a new Query about the given criterion to display to
+// the user. As with Engine.Generate, a negative user means any user, and
+// Generate panics if there is no question to ask; GenerateSafe returns an
+// error instead.
+func (m *MultiEngine) Generate(criterion, user int) Query {
+	q, err := m.GenerateSafe(criterion, user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if criterion or
+// user is out of range, or ErrNoQuestion if there is no question to ask. The
+// Engine's audit log and OnQuery callbacks see the query with choices
+// numbered by column, as Respond passes them to the Engine.
+func (m *MultiEngine) GenerateSafe(criterion, user int) (Query, error) {
+	if err := m.validate(criterion); err != nil {
+		return Query{}, err
+	}
+	p := m.Engine
+	if user >= p.X.Shape[0] {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	q, ok := sampleQuery(p.X.Shape[0], m.choices, user, p.random,
+		func(u, a, b int) float64 {
+			return p.strategy().Weight(p, u,
+				m.column(criterion, a), m.column(criterion, b))
+		},
+		func(u, c int) float64 { return m.Score(criterion, u, c) })
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	p.generated(Query{
+		User: q.User,
+		Choices: []int{m.column(criterion, q.Choices[0]),
+			m.column(criterion, q.Choices[1])},
+	})
+	return q, nil
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user along the given criterion.
+func (m *MultiEngine) Rank(criterion, user int) ([]int, error) {
+	if err := m.validate(criterion); err != nil {
+		return nil, err
+	}
+	if user < 0 || user >= m.Engine.X.Shape[0] {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, m.Engine.X.Shape[0])
+	}
+	return rankBy(m.choices, func(c int) float64 {
+		return m.Score(criterion, user, c)
+	}), nil
+}
+
+func (m *MultiEngine) validate(criterion int) error {
+	if criterion < 0 || criterion >= m.criteria {
+		return fmt.Errorf("must have 0 <= criterion [%d] < %d",
+			criterion, m.criteria)
+	}
+	return nil
+}
+
+func (m *MultiEngine) column(criterion, choice int) int {
+	return criterion*m.choices + choice
+}
@@ -0,0 +1,59 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMultiEngine(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewMultiEngine(3, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Everyone prefers lower choices on criterion 0 and higher choices on
+	// criterion 1.
+	for i := 0; i < 200; i++ {
+		criterion := i % 2
+		q := eng.Generate(criterion, -1)
+		if (q.Choices[0] > q.Choices[1]) == (criterion == 0) {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		if err := eng.Respond(criterion, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for u := 0; u < 3; u++ {
+		first, _ := eng.Rank(0, u)
+		second, _ := eng.Rank(1, u)
+		if first[0] != 0 || second[0] != 3 {
+			t.Fatalf("user %d: expected 0 and 3 to lead, got %v and %v",
+				u, first, second)
+		}
+	}
+
+	if err := eng.Respond(2, Query{Choices: []int{0, 1}}); err == nil {
+		t.Fatalf("expected an error for an unknown criterion")
+	}
+	if err := eng.Respond(0, Query{Choices: []int{0, 4}}); err == nil {
+		t.Fatalf("expected an error for a choice in another criterion")
+	}
+	if _, err := eng.GenerateSafe(2, 0); err == nil {
+		t.Fatalf("expected an error for an unknown criterion")
+	}
+	if _, err := eng.GenerateSafe(0, 3); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	var asked []Query
+	eng.Engine.OnQuery(func(q Query, _ Snapshot) { asked = append(asked, q) })
+	q := eng.Generate(1, 0)
+	if len(asked) != 1 || asked[0].Choices[0] != q.Choices[0]+4 ||
+		asked[0].Choices[1] != q.Choices[1]+4 {
+		t.Fatalf("expected OnQuery to see %v by column, got %v", q, asked)
+	}
+	if _, err := NewMultiEngine(3, 4, 0); err == nil {
+		t.Fatalf("expected an error for no criteria")
+	}
+}
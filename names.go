@@ -0,0 +1,25 @@
+package collaborativepermute
+
+// Type NamedEngine is a TypedEngine keyed by string identifiers, such as
+// UUIDs or database keys.
+type NamedEngine = TypedEngine[string, string]
+
+// Type NamedQuery is a Query whose user and choices are string identifiers.
+type NamedQuery = TypedQuery[string, string]
+
+// NewNamedEngine allocates a learning engine over the given user and choice
+// identifiers, which must be unique.
+func NewNamedEngine(users, choices []string) (*NamedEngine, error) {
+	n := NewTypedEngine[string, string]()
+	for _, id := range users {
+		if err := n.AddUser(id); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range choices {
+		if err := n.AddItem(id); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,47 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNamedEngine(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewNamedEngine(
+		[]string{"alice", "bob"},
+		[]string{"brazil", "casablanca", "metropolis"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		q, err := eng.GenerateFor("alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Choices[0] == "brazil" {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		if err := eng.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ranking, err := eng.Rank("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranking[2] != "brazil" {
+		t.Fatalf("expected brazil to be ranked last, got %v", ranking)
+	}
+
+	if err := eng.RemoveUser("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.Rank("alice"); err == nil {
+		t.Fatalf("expected an error ranking a removed user")
+	}
+	if _, err := NewNamedEngine([]string{"a", "a"}, nil); err == nil {
+		t.Fatalf("expected an error for duplicate users")
+	}
+}
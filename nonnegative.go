@@ -0,0 +1,28 @@
+package collaborativepermute
+
+import (
+	"github.com/fatlotus/gauss"
+)
+
+// WithNonNegative constrains every entry of X to be non-negative, for
+// applications where scores feed into systems that assume non-negative
+// utilities. After each proximal step, negative entries are set to zero,
+// which is the projection onto the non-negative orthant.
+//
+// The constraint applies to X alone; Bias and feature terms may still make
+// Score negative.
+func WithNonNegative() Option {
+	return func(p *Engine) error {
+		p.NonNegative = true
+		return nil
+	}
+}
+
+// project sets every negative entry of x to zero, in place.
+func project(x gauss.Array) {
+	for i, v := range x.Data {
+		if v < 0 {
+			x.Data[i] = 0
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNonNegative(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(3, 4, WithNonNegative())
+	for i := 0; i < 30; i++ {
+		a, b := rand.Intn(4), rand.Intn(3)
+		if b >= a {
+			a, b = b+1, a
+		}
+		eng.Respond(Query{User: i % 3, Choices: []int{b, a}})
+	}
+
+	for _, v := range eng.X.Data {
+		if v < 0 {
+			t.Fatalf("expected non-negative scores, got %v", eng.X.Data)
+		}
+	}
+	ranking, _ := eng.Rank(0)
+	if ranking[0] != 0 {
+		t.Fatalf("expected choice 0 to rank first, got %v", ranking)
+	}
+}
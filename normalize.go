@@ -0,0 +1,121 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+	"math"
+)
+
+// minUserScale keeps learned scales positive, as a negative scale would
+// reverse the user's ranking.
+const minUserScale = 1e-3
+
+// WithUserNormalization learns an offset and a scale for each user, so that
+// Score = UserOffsets + UserScales × (the score of the other terms). Users who
+// answer emphatically then learn a larger scale rather than a more extreme
+// row of the low-rank matrix, and NormalizedScore, which removes both terms,
+// is comparable across users for consensus ranking and analytics.
+// Comparisons only constrain differences in score, so they leave a user's
+// offset where it is; the scale is learned from every response, and is most
+// informative for Likert responses, where emphatic users answer at the ends
+// of the scale.
+func WithUserNormalization() Option {
+	return func(p *Engine) error {
+		p.UserOffsets = make([]float64, p.X.Shape[0])
+		p.UserScales = ones(p.X.Shape[0])
+		return nil
+	}
+}
+
+// Method UserOffset returns the user's learned offset, or 0 if the engine is
+// not configured WithUserNormalization.
+func (p *Engine) UserOffset(user int) (float64, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return 0, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if p.UserOffsets == nil {
+		return 0, nil
+	}
+	return p.UserOffsets[user], nil
+}
+
+// Method UserScale returns the user's learned scale, or 1 if the engine is
+// not configured WithUserNormalization.
+func (p *Engine) UserScale(user int) (float64, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return 0, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if p.UserScales == nil {
+		return 1, nil
+	}
+	return p.UserScales[user], nil
+}
+
+// Method NormalizedScore returns the user's score for the choice without
+// their learned offset and scale. Like Score, it panics if user or choice is
+// out of range.
+func (p *Engine) NormalizedScore(user, choice int) float64 {
+	return p.baseScore(user, choice)
+}
+
+// updateUserNormalization takes a gradient step of size nu on UserOffsets and
+// UserScales, given the gradient of the loss with respect to the scores, with
+// a ridge penalty of Lambda toward an offset of 0 and a scale of 1. The
+// scale's step is divided by one plus the squared norm of the user's unscaled
+// scores, which would otherwise make it overshoot for users with large
+// scores. It then multiplies each user's row of the gradient by their scale,
+// which is the gradient with respect to the terms the scale multiplies.
+func (p *Engine) updateUserNormalization(gradient gauss.Array, nu float64) {
+	if p.UserScales == nil {
+		return
+	}
+	for u := 0; u < gradient.Shape[0]; u++ {
+		scale := p.UserScales[u]
+		offsetStep := p.Lambda * p.UserOffsets[u]
+		scaleStep, norm := p.Lambda*(scale-1), 1.0
+		for j := 0; j < gradient.Shape[1]; j++ {
+			g, base := *gradient.I(u, j), p.baseScore(u, j)
+			offsetStep += g
+			scaleStep += g * base
+			norm += base * base
+			*gradient.I(u, j) = g * scale
+		}
+		p.UserOffsets[u] -= nu * offsetStep
+		p.UserScales[u] = math.Max(scale-nu*scaleStep/norm, minUserScale)
+	}
+}
+
+// standardization returns the mean of the user's scores and their
+// root-mean-square deviation from it, or 1 if the user is indifferent to
+// every choice, for z-scoring the scores of engines that were not configured
+// alike.
+func (p *Engine) standardization(user int) (float64, float64) {
+	n := p.X.Shape[1]
+	if n == 0 {
+		return 0, 1
+	}
+	sum := 0.0
+	for j := 0; j < n; j++ {
+		sum += p.Score(user, j)
+	}
+	offset := sum / float64(n)
+	sum = 0.0
+	for j := 0; j < n; j++ {
+		d := p.Score(user, j) - offset
+		sum += d * d
+	}
+	scale := math.Sqrt(sum / float64(n))
+	if scale < 1e-12 {
+		return offset, 1
+	}
+	return offset, scale
+}
+
+// standardizedScore returns the user's score for the choice, z-scored as by
+// standardization.
+func (p *Engine) standardizedScore(user, choice int) float64 {
+	offset, scale := p.standardization(user)
+	return (p.Score(user, choice) - offset) / scale
+}
@@ -0,0 +1,60 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestUserNormalization(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3, WithLikert(LikertThresholds(5)),
+		WithUserNormalization())
+	for i := 0; i < 30; i++ {
+		// Both users rank 0 above 1 above 2, but user 0 says so strongly
+		// and user 1 only mildly.
+		eng.Respond(Query{User: 0, Choices: []int{0, 2}, Likert: 5})
+		eng.Respond(Query{User: 0, Choices: []int{1, 2}, Likert: 5})
+		eng.Respond(Query{User: 1, Choices: []int{0, 2}, Likert: 4})
+		eng.Respond(Query{User: 1, Choices: []int{1, 2}, Likert: 4})
+	}
+
+	emphatic, _ := eng.UserScale(0)
+	hesitant, _ := eng.UserScale(1)
+	if emphatic <= hesitant {
+		t.Fatalf("expected the emphatic user to learn the larger scale, "+
+			"got %v and %v", emphatic, hesitant)
+	}
+	raw := (eng.Score(0, 0) - eng.Score(0, 2)) /
+		(eng.Score(1, 0) - eng.Score(1, 2))
+	normalized := (eng.NormalizedScore(0, 0) - eng.NormalizedScore(0, 2)) /
+		(eng.NormalizedScore(1, 0) - eng.NormalizedScore(1, 2))
+	if !(normalized > 0 && normalized < raw) {
+		t.Fatalf("expected normalized scores to be more comparable, got "+
+			"ratios %v and %v", normalized, raw)
+	}
+	for u := 0; u < 2; u++ {
+		offset, _ := eng.UserOffset(u)
+		scale, _ := eng.UserScale(u)
+		if s := offset + scale*eng.NormalizedScore(u, 1); s != eng.Score(u, 1) {
+			t.Fatalf("user %d: expected the score to be %v, got %v", u, s,
+				eng.Score(u, 1))
+		}
+	}
+
+	eng.AddUser()
+	if s, _ := eng.UserScale(2); s != 1 || len(eng.UserOffsets) != 3 {
+		t.Fatalf("expected a new user to start with scale 1, got %v", s)
+	}
+	if c := eng.clone(); &c.UserScales[0] == &eng.UserScales[0] {
+		t.Fatalf("expected the clone not to share the scales")
+	}
+	if _, err := eng.UserOffset(3); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	if _, err := eng.UserScale(-1); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	if s, _ := NewEngine(1, 2).UserScale(0); s != 1 {
+		t.Fatalf("expected scale 1 without WithUserNormalization, got %v", s)
+	}
+}
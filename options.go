@@ -0,0 +1,109 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Type Option configures an Engine as it is constructed.
+type Option func(*Engine) error
+
+// WithLambda sets the strength of the trace norm regularization, which
+// controls how strongly users are assumed to share preferences.
+func WithLambda(lambda float64) Option {
+	return func(p *Engine) error {
+		p.Lambda = lambda
+		return nil
+	}
+}
+
+// WithNu sets the step size of each update.
+func WithNu(nu float64) Option {
+	return func(p *Engine) error {
+		p.Nu = nu
+		return nil
+	}
+}
+
+// WithTemperature sets how sharply the default strategy focuses on the most
+// uncertain pairs; higher temperatures explore more.
+func WithTemperature(t float64) Option {
+	return func(p *Engine) error {
+		p.T = t
+		return nil
+	}
+}
+
+// WithMargin sets the separation in score that the hinge loss demands between
+// a preferred choice and the other. Individual responses may override it.
+func WithMargin(margin float64) Option {
+	return func(p *Engine) error {
+		p.Margin = margin
+		return nil
+	}
+}
+
+// WithRNG sets the source of randomness used by Generate. By default, the
+// engine uses the top-level functions of math/rand.
+func WithRNG(rng *rand.Rand) Option {
+	return func(p *Engine) error {
+		p.rng = rng
+		return nil
+	}
+}
+
+// WithStrategy sets how Generate chooses which queries to ask.
+func WithStrategy(s Strategy) Option {
+	return func(p *Engine) error {
+		p.Strategy = s
+		return nil
+	}
+}
+
+// WithCapacity reserves room for up to maxUsers users and maxItems choices, so
+// that AddUser and AddItem do not reallocate the belief matrices until the
+// engine grows past that size.
+func WithCapacity(maxUsers, maxItems int) Option {
+	return func(p *Engine) error {
+		if maxUsers < p.X.Shape[0] || maxItems < p.X.Shape[1] {
+			return fmt.Errorf("capacity %dx%d is smaller than %dx%d",
+				maxUsers, maxItems, p.X.Shape[0], p.X.Shape[1])
+		}
+		p.X = reserve(p.X, maxUsers, maxItems)
+		p.Xp = reserve(p.Xp, maxUsers, maxItems)
+		p.Z = reserve(p.Z, maxUsers, maxItems)
+		return nil
+	}
+}
+
+// validateParams checks that the hyperparameters are usable together.
+func (p *Engine) validateParams() error {
+	if !(p.Nu > 0) {
+		return fmt.Errorf("must have Nu [%v] > 0", p.Nu)
+	}
+	if !(p.Lambda >= 0) {
+		return fmt.Errorf("must have Lambda [%v] >= 0", p.Lambda)
+	}
+	if !(p.Margin > 0) {
+		return fmt.Errorf("must have Margin [%v] > 0", p.Margin)
+	}
+	if !(p.T > 0) {
+		return fmt.Errorf("must have T [%v] > 0", p.T)
+	}
+	if p.ItemFeatures != nil && len(p.ItemFeatures) != p.X.Shape[1] {
+		return fmt.Errorf("must have len(ItemFeatures) [%d] == %d",
+			len(p.ItemFeatures), p.X.Shape[1])
+	}
+	if p.UserFeatures != nil && len(p.UserFeatures) != p.X.Shape[0] {
+		return fmt.Errorf("must have len(UserFeatures) [%d] == %d",
+			len(p.UserFeatures), p.X.Shape[0])
+	}
+	return nil
+}
+
+func (p *Engine) random() float64 {
+	if p.rng != nil {
+		return p.rng.Float64()
+	}
+	return rand.Float64()
+}
@@ -0,0 +1,78 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOptionsValidated(t *testing.T) {
+	invalid := map[string]Option{
+		"lambda":      WithLambda(-1),
+		"nu":          WithNu(0),
+		"temperature": WithTemperature(0),
+		"margin":      WithMargin(0),
+		"capacity":    WithCapacity(1, 1),
+		"features":    WithItemFeatures([][]float64{{1}}),
+	}
+	for name, opt := range invalid {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected NewEngine to panic", name)
+				}
+			}()
+			NewEngine(2, 2, opt)
+		}()
+	}
+}
+
+func TestWithRNG(t *testing.T) {
+	generate := func() []Query {
+		eng := NewEngine(3, 4, WithRNG(rand.New(rand.NewSource(7))))
+		result := make([]Query, 0)
+		for i := 0; i < 5; i++ {
+			q := eng.Generate(-1)
+			result = append(result, q)
+			eng.Respond(q)
+		}
+		return result
+	}
+
+	first, second := generate(), generate()
+	for i := range first {
+		if first[i].User != second[i].User ||
+			first[i].Choices[0] != second[i].Choices[0] ||
+			first[i].Choices[1] != second[i].Choices[1] {
+			t.Fatalf("engines with the same seed diverged at query %d", i)
+		}
+	}
+}
+
+func TestWithStrategy(t *testing.T) {
+	only := StrategyFunc(func(p *Engine, user, a, b int) float64 {
+		if a+b == 3 {
+			return 1
+		}
+		return 0
+	})
+	eng := NewEngine(2, 4, WithStrategy(only))
+	for i := 0; i < 10; i++ {
+		if q := eng.Generate(-1); q.Choices[0]+q.Choices[1] != 3 {
+			t.Fatalf("strategy was not consulted, got %v", q.Choices)
+		}
+	}
+}
+
+func TestNewEngineSafe(t *testing.T) {
+	for _, size := range [][2]int{{-1, 3}, {3, -1}} {
+		if _, err := NewEngineSafe(size[0], size[1]); err == nil {
+			t.Errorf("expected an error for a %dx%d engine", size[0], size[1])
+		}
+	}
+	if _, err := NewEngineSafe(2, 2, WithNu(-1)); err == nil {
+		t.Errorf("expected an error for an invalid option")
+	}
+	if eng, err := NewEngineSafe(0, 0); err != nil || eng == nil {
+		t.Errorf("expected an empty engine to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Likert-scale responses ("strongly prefer A", "prefer A", "neutral", ...)
+// carry more information than a binary preference. Rather than binarizing
+// them, the engine can learn from them with the all-threshold ordinal loss
+// (Rennie and Srebro '05): the Thresholds cut the difference in score between
+// the two choices into one interval per level, and a response is penalized,
+// under the engine's Loss, for every threshold on the wrong side of it.
+
+// WithLikert accepts Likert responses on a scale with len(thresholds)+1
+// levels, where the increasing thresholds separate adjacent levels in terms
+// of Score(Choices[0]) - Score(Choices[1]). See LikertThresholds.
+func WithLikert(thresholds []float64) Option {
+	return func(p *Engine) error {
+		if len(thresholds) == 0 {
+			return fmt.Errorf("must have at least one threshold")
+		}
+		for i := 1; i < len(thresholds); i++ {
+			if !(thresholds[i] > thresholds[i-1]) {
+				return fmt.Errorf("thresholds must be increasing")
+			}
+		}
+		p.Thresholds = thresholds
+		return nil
+	}
+}
+
+// LikertThresholds returns evenly spaced thresholds for a symmetric scale
+// with the given number of levels, two units apart and centered on zero. With
+// the default Margin of one, the middle of a five-point scale then asks for
+// equal scores, and each step away from it for two more units of difference.
+// A scale of fewer than two levels has no thresholds, so LikertThresholds
+// returns none, which WithLikert rejects.
+func LikertThresholds(levels int) []float64 {
+	if levels < 2 {
+		return nil
+	}
+	thresholds := make([]float64, levels-1)
+	for i := range thresholds {
+		thresholds[i] = float64(2*i - (levels - 2))
+	}
+	return thresholds
+}
+
+// ordinalLoss returns the all-threshold loss of a Likert response.
+func (p *Engine) ordinalLoss(q Query) float64 {
+	diff := p.scoreIn(q.User, q.Choices[0], q.Context) -
+		p.scoreIn(q.User, q.Choices[1], q.Context)
+	sum := 0.0
+	for k, threshold := range p.Thresholds {
+		if k < q.Likert-1 {
+			sum += p.lossFunc().Value(diff-threshold, p.margin(q))
+		} else {
+			sum += p.lossFunc().Value(threshold-diff, p.margin(q))
+		}
+	}
+	return sum
+}
+
+// ordinalDerivative returns the derivative of ordinalLoss with respect to
+// Score(Choices[0]), which is the negative of that with respect to
+// Score(Choices[1]).
+func (p *Engine) ordinalDerivative(q Query) float64 {
+	diff := p.scoreIn(q.User, q.Choices[0], q.Context) -
+		p.scoreIn(q.User, q.Choices[1], q.Context)
+	d := 0.0
+	for k, threshold := range p.Thresholds {
+		if k < q.Likert-1 {
+			d += p.lossFunc().Derivative(diff-threshold, p.margin(q))
+		} else {
+			d -= p.lossFunc().Derivative(threshold-diff, p.margin(q))
+		}
+	}
+	return d
+}
+
+// preferences returns each pair (a, b) implied by the response in which a is
+// preferred over b. A Likert response implies one pair, or none if it is
+// neutral; see pairs for other responses.
+func (p *Engine) preferences(q Query) [][2]int {
+	if q.Likert == 0 {
+		return pairs(q.Choices)
+	}
+	levels := len(p.Thresholds) + 1
+	switch {
+	case 2*q.Likert > levels+1:
+		return [][2]int{{q.Choices[0], q.Choices[1]}}
+	case 2*q.Likert < levels+1:
+		return [][2]int{{q.Choices[1], q.Choices[0]}}
+	}
+	return nil
+}
+
+func (p *Engine) validateLikert(q Query) error {
+	if q.Likert == 0 {
+		return nil
+	}
+	if p.Thresholds == nil {
+		return fmt.Errorf("engine was not configured WithLikert")
+	}
+	if q.Likert < 0 || q.Likert > len(p.Thresholds)+1 {
+		return fmt.Errorf("must have 1 <= Likert [%d] <= %d",
+			q.Likert, len(p.Thresholds)+1)
+	}
+	if len(q.Choices) != 2 {
+		return fmt.Errorf("Likert responses must compare two choices")
+	}
+	return nil
+}
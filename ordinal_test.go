@@ -0,0 +1,53 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLikert(t *testing.T) {
+	rand.Seed(23)
+	if got := LikertThresholds(5); len(got) != 4 || got[0] != -3 || got[3] != 3 {
+		t.Fatalf("expected thresholds -3, -1, 1, 3, got %v", got)
+	}
+	for _, levels := range []int{-1, 0, 1} {
+		if got := LikertThresholds(levels); len(got) != 0 {
+			t.Fatalf("expected no thresholds for %d levels, got %v",
+				levels, got)
+		}
+		_, err := NewEngineSafe(1, 3, WithLikert(LikertThresholds(levels)))
+		if err == nil {
+			t.Fatalf("expected an error for a scale of %d levels", levels)
+		}
+	}
+
+	eng := NewEngine(1, 3, WithLikert(LikertThresholds(5)))
+	for i := 0; i < 30; i++ {
+		// Strongly prefer 0 over 2, mildly prefer 1 over 2, and are neutral
+		// between 0 and 1.
+		eng.Respond(Query{Choices: []int{0, 2}, Likert: 5})
+		eng.Respond(Query{Choices: []int{2, 1}, Likert: 2})
+		eng.Respond(Query{Choices: []int{1, 0}, Likert: 3})
+	}
+
+	strong := eng.Score(0, 0) - eng.Score(0, 2)
+	mild := eng.Score(0, 1) - eng.Score(0, 2)
+	if !(strong > mild && mild > 0) {
+		t.Fatalf("expected a strong and a mild preference, got %v and %v",
+			strong, mild)
+	}
+	if acc := eng.accuracy(eng.History); acc != 1 {
+		t.Fatalf("expected neutral answers to be ignored by accuracy, got %v", acc)
+	}
+
+	if err := eng.Respond(Query{Choices: []int{0, 1}, Likert: 6}); err == nil {
+		t.Fatalf("expected an error for an out-of-range level")
+	}
+	if err := eng.Respond(Query{Choices: []int{0, 1, 2}, Likert: 4}); err == nil {
+		t.Fatalf("expected an error for a Likert ranking")
+	}
+	plain := NewEngine(1, 2)
+	if err := plain.Respond(Query{Choices: []int{0, 1}, Likert: 1}); err == nil {
+		t.Fatalf("expected an error without WithLikert")
+	}
+}
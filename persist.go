@@ -0,0 +1,56 @@
+package collaborativepermute
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// savedEngine is the state written by Save: everything needed to refit the
+// engine, but none of its configured functions, which cannot be serialized.
+type savedEngine struct {
+	Users, Choices int
+	Params         Params
+	History        []Query
+}
+
+// Method Save writes the engine's size, hyperparameters, and History to w,
+// from which Load can restore it.
+func (p *Engine) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(savedEngine{
+		Users:   p.X.Shape[0],
+		Choices: p.X.Shape[1],
+		Params:  p.Params(),
+		History: p.History,
+	})
+}
+
+// Function Load restores an engine written by Save, by refitting it on the
+// saved History. Options such as strategies and losses are not saved, and so
+// must be passed again; options that set hyperparameters take precedence over
+// the saved values.
+func Load(r io.Reader, opts ...Option) (*Engine, error) {
+	var saved savedEngine
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("could not read saved engine: %v", err)
+	}
+	params := func(p *Engine) error {
+		p.Nu, p.Lambda, p.T, p.Margin =
+			saved.Params.Nu, saved.Params.Lambda, saved.Params.T,
+			saved.Params.Margin
+		return nil
+	}
+	p, err := NewEngineSafe(saved.Users, saved.Choices,
+		append([]Option{params}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	for i, q := range saved.History {
+		if err := p.validate(q); err != nil {
+			return nil, fmt.Errorf("saved response %d: %v", i, err)
+		}
+	}
+	p.History = saved.History
+	p.Refit()
+	return p, nil
+}
@@ -0,0 +1,41 @@
+package collaborativepermute
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3, WithLambda(0.1))
+	eng.Respond(Query{User: 0, Choices: []int{2, 0}})
+	eng.Respond(Query{User: 1, Choices: []int{1, 0, 2}})
+
+	var buf bytes.Buffer
+	if err := eng.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Lambda != 0.1 || len(loaded.History) != 2 {
+		t.Fatalf("unexpected engine %+v", loaded.Params())
+	}
+	for i := range eng.X.Data {
+		if math.Abs(eng.X.Data[i]-loaded.X.Data[i]) > 1e-9 {
+			t.Fatalf("loaded engine differs at %d", i)
+		}
+	}
+
+	margin, err := Load(bytes.NewReader(buf.Bytes()), WithMargin(2))
+	if err != nil || margin.Margin != 2 || margin.Lambda != 0.1 {
+		t.Fatalf("expected options to override saved values, got %v, %v",
+			margin.Params(), err)
+	}
+	if _, err := Load(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Fatalf("expected an error for a corrupt file")
+	}
+}
@@ -0,0 +1,85 @@
+package collaborativepermute
+
+import (
+	"math"
+	"sort"
+)
+
+// Under the Plackett-Luce model, a ranking of k choices is built by
+// repeatedly picking the most preferred of the choices that remain, each with
+// probability proportional to e^θ. A full slate then contributes all of its
+// information at once, rather than being decomposed into k(k-1)/2 pairwise
+// responses that are wrongly treated as independent. For k = 2 it reduces to
+// the Bradley-Terry-Luce model.
+
+// Function PlackettLuce returns the log-likelihood of the given ranking
+// (scores listed from most to least preferred) under the Plackett-Luce model.
+func PlackettLuce(scores []float64) float64 {
+	sum := 0.0
+	for s := 0; s < len(scores)-1; s++ {
+		sum += scores[s] - logSumExp(scores[s:])
+	}
+	return sum
+}
+
+// plackettLuceGradient returns the gradient of PlackettLuce with respect to
+// each score.
+func plackettLuceGradient(scores []float64) []float64 {
+	k := len(scores)
+	gradient := make([]float64, k)
+	for s := 0; s < k-1; s++ {
+		gradient[s]++
+		norm := logSumExp(scores[s:])
+		for i := s; i < k; i++ {
+			gradient[i] -= math.Exp(scores[i] - norm)
+		}
+	}
+	return gradient
+}
+
+func logSumExp(xs []float64) float64 {
+	max := math.Inf(-1)
+	for _, x := range xs {
+		max = math.Max(max, x)
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += math.Exp(x - max)
+	}
+	return max + math.Log(sum)
+}
+
+// Method GenerateSlate creates a Query asking the user to rank k choices at
+// once. It starts from the pair that Generate would ask about, and adds the
+// choices whose scores are closest to that pair's, since those are the ones
+// whose positions are least certain. The choices are listed in the order the
+// engine currently believes in.
+func (p *BTLEngine) GenerateSlate(user, k int) Query {
+	q := p.Generate(user)
+	if k > len(p.V) {
+		k = len(p.V)
+	}
+	center := (p.Score(q.User, q.Choices[0]) + p.Score(q.User, q.Choices[1])) / 2
+
+	rest := make([]int, 0, len(p.V))
+	for c := range p.V {
+		if c != q.Choices[0] && c != q.Choices[1] {
+			rest = append(rest, c)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return math.Abs(p.Score(q.User, rest[i])-center) <
+			math.Abs(p.Score(q.User, rest[j])-center)
+	})
+	for _, c := range rest {
+		if len(q.Choices) >= k {
+			break
+		}
+		q.Choices = append(q.Choices, c)
+	}
+
+	sort.SliceStable(q.Choices, func(i, j int) bool {
+		return p.Score(q.User, q.Choices[i]) > p.Score(q.User, q.Choices[j])
+	})
+	return q
+}
@@ -0,0 +1,52 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPlackettLuceGradient(t *testing.T) {
+	scores := []float64{0.3, -1, 2, 0.5}
+	gradient := plackettLuceGradient(scores)
+	for i := range scores {
+		h := 1e-6
+		scores[i] += h
+		up := PlackettLuce(scores)
+		scores[i] -= 2 * h
+		down := PlackettLuce(scores)
+		scores[i] += h
+		if numeric := (up - down) / (2 * h); math.Abs(numeric-gradient[i]) > 1e-5 {
+			t.Fatalf("gradient %d is %v, expected %v", i, gradient[i], numeric)
+		}
+	}
+
+	if pair := PlackettLuce([]float64{1, 0}); math.Abs(pair-math.Log(sigmoid(1))) > 1e-12 {
+		t.Fatalf("expected pairs to match Bradley-Terry-Luce")
+	}
+}
+
+func TestSlates(t *testing.T) {
+	rand.Seed(23)
+	eng, _ := NewBTLEngine(1, 5, 1)
+	for i := 0; i < 30; i++ {
+		q := eng.GenerateSlate(0, 3)
+		if len(q.Choices) != 3 {
+			t.Fatalf("expected a slate of 3, got %v", q.Choices)
+		}
+		sort.Ints(q.Choices)
+		if err := eng.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ranking, _ := eng.Rank(0)
+	for i, c := range ranking {
+		if c != i {
+			t.Fatalf("expected ranking [0 1 2 3 4], got %v", ranking)
+		}
+	}
+	if err := eng.Respond(Query{Choices: []int{1, 1, 2}}); err == nil {
+		t.Fatalf("expected an error for a repeated choice")
+	}
+}
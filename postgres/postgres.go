@@ -0,0 +1,275 @@
+// Package postgres is an httpapi.Store keeping surveys in PostgreSQL:
+//
+//	db, err := sql.Open("pgx", "postgres://localhost/permute")
+//	...
+//	store, err := postgres.Open(ctx, db)
+//	...
+//	defer store.Close()
+//	r := httpapi.NewRegistry()
+//	err = store.Load(r)
+//	r.Store = store
+//
+// Any database/sql driver for PostgreSQL may be used. Each survey's responses
+// are appended to the permute_history table as they are learned, and every
+// SnapshotEvery responses, the engine as written by Engine.Save is stored in
+// the permute_snapshots table. Loading restores the latest snapshot and
+// replays the responses after it.
+//
+// Only one process may write at a time: Open takes a PostgreSQL advisory
+// lock, which is held until Close, and fails with ErrLocked if another Store
+// holds it.
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/httpapi"
+	"sync"
+)
+
+// ErrLocked is returned by Open when another Store is writing to the
+// database.
+var ErrLocked = errors.New("another writer holds the permute lock")
+
+// lockKey identifies the advisory lock taken by Open.
+const lockKey = "collaborativepermute"
+
+const schema = `
+CREATE TABLE IF NOT EXISTS permute_surveys (
+	id text PRIMARY KEY,
+	survey jsonb NOT NULL
+);
+CREATE TABLE IF NOT EXISTS permute_history (
+	survey_id text NOT NULL REFERENCES permute_surveys ON DELETE CASCADE,
+	seq integer NOT NULL,
+	response jsonb NOT NULL,
+	PRIMARY KEY (survey_id, seq)
+);
+CREATE TABLE IF NOT EXISTS permute_snapshots (
+	survey_id text PRIMARY KEY REFERENCES permute_surveys ON DELETE CASCADE,
+	responses integer NOT NULL,
+	state bytea NOT NULL,
+	saved_at timestamptz NOT NULL DEFAULT now()
+);`
+
+// Struct Store persists surveys in PostgreSQL; see the package documentation.
+type Store struct {
+	// SnapshotEvery is the number of responses between snapshots.
+	SnapshotEvery int
+
+	// mu serializes the use of conn, since a PostgreSQL session can have
+	// only one transaction open and the Registry saves surveys in parallel.
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+var _ httpapi.Store = (*Store)(nil)
+
+// Open takes the writer lock, creates the tables if necessary, and returns a
+// Store using a connection from db.
+func Open(ctx context.Context, db *sql.DB) (*Store, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx,
+		`SELECT pg_try_advisory_lock(hashtext($1))`, lockKey).Scan(&locked)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrLocked
+	}
+	s := &Store{SnapshotEvery: 100, conn: conn}
+	if _, err := conn.ExecContext(ctx, schema); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("could not create tables: %v", err)
+	}
+	return s, nil
+}
+
+// Method Close releases the writer lock and the connection.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, unlockErr := s.conn.ExecContext(context.Background(),
+		`SELECT pg_advisory_unlock(hashtext($1))`, lockKey)
+	if err := s.conn.Close(); err != nil {
+		return err
+	}
+	return unlockErr
+}
+
+// Method Save records the survey, appends any responses in the engine's
+// History not yet stored, and takes a snapshot if SnapshotEvery responses
+// have been learned since the last. If the History has shrunk, as after
+// Engine.RemoveUser, it is stored afresh.
+func (s *Store) Save(sv httpapi.Survey,
+	eng *collaborativepermute.Engine) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx := context.Background()
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	survey, err := json.Marshal(sv)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO permute_surveys (id, survey)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET survey = excluded.survey`,
+		sv.ID, survey); err != nil {
+		return err
+	}
+
+	var stored, snapshot int
+	if err := tx.QueryRowContext(ctx, `SELECT
+		(SELECT count(*) FROM permute_history WHERE survey_id = $1),
+		COALESCE((SELECT responses FROM permute_snapshots
+			WHERE survey_id = $1), -1)`,
+		sv.ID).Scan(&stored, &snapshot); err != nil {
+		return err
+	}
+	if stored > len(eng.History) {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM permute_history WHERE survey_id = $1`,
+			sv.ID); err != nil {
+			return err
+		}
+		stored, snapshot = 0, -1
+	}
+	for i := stored; i < len(eng.History); i++ {
+		response, err := json.Marshal(eng.History[i])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO permute_history
+			(survey_id, seq, response) VALUES ($1, $2, $3)`,
+			sv.ID, i, response); err != nil {
+			return err
+		}
+	}
+
+	if snapshot < 0 || len(eng.History)-snapshot >= s.SnapshotEvery {
+		var state bytes.Buffer
+		if err := eng.Save(&state); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO permute_snapshots
+			(survey_id, responses, state) VALUES ($1, $2, $3)
+			ON CONFLICT (survey_id) DO UPDATE SET
+			responses = excluded.responses, state = excluded.state,
+			saved_at = now()`,
+			sv.ID, len(eng.History), state.Bytes()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Method Delete removes the survey, its history, and its snapshot.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.conn.ExecContext(context.Background(),
+		`DELETE FROM permute_surveys WHERE id = $1`, id)
+	return err
+}
+
+// Method Load adds every stored survey to r, restoring each engine with opts.
+func (s *Store) Load(r *httpapi.Registry,
+	opts ...collaborativepermute.Option) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx := context.Background()
+	rows, err := s.conn.QueryContext(ctx,
+		`SELECT survey FROM permute_surveys ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	var surveys []httpapi.Survey
+	for rows.Next() {
+		var data []byte
+		var sv httpapi.Survey
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal(data, &sv); err != nil {
+			rows.Close()
+			return err
+		}
+		surveys = append(surveys, sv)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, sv := range surveys {
+		eng, err := s.load(ctx, sv.ID, opts)
+		if err != nil {
+			return fmt.Errorf("survey %q: %v", sv.ID, err)
+		}
+		if err := r.Add(sv, eng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load restores the survey's snapshot and replays the responses after it.
+func (s *Store) load(ctx context.Context, id string,
+	opts []collaborativepermute.Option) (*collaborativepermute.Engine, error) {
+	var responses int
+	var state []byte
+	if err := s.conn.QueryRowContext(ctx, `SELECT responses, state
+		FROM permute_snapshots WHERE survey_id = $1`,
+		id).Scan(&responses, &state); err != nil {
+		return nil, err
+	}
+	eng, err := collaborativepermute.Load(bytes.NewReader(state), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn.QueryContext(ctx, `SELECT response FROM
+		permute_history WHERE survey_id = $1 AND seq >= $2 ORDER BY seq`,
+		id, responses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	replay := false
+	for rows.Next() {
+		var data []byte
+		var q collaborativepermute.Query
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &q); err != nil {
+			return nil, err
+		}
+		eng.History = append(eng.History, q)
+		replay = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if replay {
+		eng.Refit()
+	}
+	return eng, nil
+}
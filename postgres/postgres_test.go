@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/fatlotus/collaborativepermute/httpapi"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// open connects to the database named by $PERMUTE_POSTGRES_DSN, skipping the
+// test if it is unset, and drops any tables left by earlier runs.
+func open(t *testing.T) *sql.DB {
+	dsn := os.Getenv("PERMUTE_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("PERMUTE_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`DROP TABLE IF EXISTS permute_history,
+		permute_snapshots, permute_surveys`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestStore(t *testing.T) {
+	rand.Seed(23)
+	db := open(t)
+	ctx := context.Background()
+	store, err := Open(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(ctx, db); err != ErrLocked {
+		t.Fatalf("expected a second writer to be locked out, got %v", err)
+	}
+	store.SnapshotEvery = 2
+
+	sv := httpapi.Survey{ID: "films", Users: 2, Items: []string{"a", "b", "c"}}
+	eng := collaborativepermute.NewEngine(2, 3)
+	for _, q := range []collaborativepermute.Query{
+		{User: 0, Choices: []int{1, 0}},
+		{User: 1, Choices: []int{2, 0}},
+		{User: 0, Choices: []int{1, 2}},
+	} {
+		eng.Respond(q)
+		if err := store.Save(sv, eng); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	r := httpapi.NewRegistry()
+	if err := store.Load(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(sv, eng); err == nil {
+		t.Fatalf("expected the survey to have been loaded")
+	}
+	if err := store.Delete("films"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Load(httpapi.NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeDriver is a database/sql driver standing in for PostgreSQL. Where a real
+// session would silently merge a transaction begun while another is open,
+// its connections fail.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct {
+	mu   sync.Mutex
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt(query), nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inTx {
+		return nil, errors.New("there is already a transaction in progress")
+	}
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error { return c.Rollback() }
+func (c *fakeConn) Rollback() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inTx = false
+	return nil
+}
+
+type fakeStmt string
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	runtime.Gosched()
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	switch {
+	case strings.Contains(string(s), "pg_try_advisory_lock"):
+		return &fakeRows{values: [][]driver.Value{{true}}}, nil
+	case strings.Contains(string(s), "count(*)"):
+		return &fakeRows{values: [][]driver.Value{{int64(0), int64(-1)}}}, nil
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ values [][]driver.Value }
+
+func (r *fakeRows) Columns() []string {
+	if len(r.values) == 0 {
+		return []string{"survey"}
+	}
+	return make([]string, len(r.values[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("permute-fake", fakeDriver{})
+}
+
+func TestStoreConcurrentSave(t *testing.T) {
+	rand.Seed(23)
+	db, err := sql.Open("permute-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := Open(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for _, id := range []string{"films", "books"} {
+		sv := httpapi.Survey{ID: id, Users: 1, Items: []string{"a", "b"}}
+		eng := collaborativepermute.NewEngine(1, 2)
+		eng.Respond(collaborativepermute.Query{User: 0, Choices: []int{1, 0}})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if err := store.Save(sv, eng); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("expected saves of different surveys not to overlap: %v",
+			err)
+	}
+}
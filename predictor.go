@@ -26,13 +26,19 @@
 // Currently, the implementation will only ever ask about two items at a time.
 // If you cannot decide when each user is prompted (such as for an online form),
 // pass the current user's ID to .Generate to restrict the queries generated.
+//
+// Hyperparameters are set by passing options to the constructor, such as
+// NewEngine(3, 5, WithLambda(0.1), WithStrategy(Uniform)).
 package collaborativepermute
 
 import (
+	"context"
+	"errors"
 	"github.com/fatlotus/gauss"
 	"math"
 	"fmt"
 	"math/rand"
+	"time"
 )
 
 // Struct predictor implements a basic learning engine.
@@ -40,19 +46,219 @@ type Engine struct {
 	X, Xp, Z gauss.Array
 	Nu, Alpha, Lambda, T float64
 	History []Query
+
+	// Margin is the separation in score that the hinge loss demands between
+	// a preferred choice and the other.
+	Margin float64
+
+	ColdStart ColdStart
+
+	// ItemFeatures optionally holds a feature vector for each choice, or nil
+	// where none is known.
+	ItemFeatures [][]float64
+
+	// UserFeatures optionally holds a covariate vector for each user, or nil
+	// where none is known.
+	UserFeatures [][]float64
+
+	// A holds each user's learned weights over ItemFeatures when the engine
+	// is configured WithItemFeatures, so that Score = X + A·ItemFeatures'.
+	// Likewise, B holds each choice's learned weights over UserFeatures when
+	// configured WithUserFeatures, adding UserFeatures·B to the score.
+	A, B gauss.Array
+
+	// C holds each choice's learned weights over the Context of a query when
+	// the engine is configured WithContext; see ScoreIn.
+	C gauss.Array
+
+	// ItemCategories optionally assigns each choice to a category, or -1 for
+	// none, and CategoryOffsets holds each user's learned offset for each
+	// category, when the engine is configured WithCategories.
+	ItemCategories []int
+	CategoryOffsets gauss.Array
+
+	// Bias holds a learned popularity offset shared by all users for each
+	// choice, when the engine is configured WithItemBias.
+	Bias []float64
+
+	// UserOffsets and UserScales hold each user's learned offset and scale,
+	// when the engine is configured WithUserNormalization.
+	UserOffsets, UserScales []float64
+
+	// Prior holds known scores that X is initialized and regularized toward
+	// with weight PriorStrength; NaN entries are unknown. See SetPrior.
+	Prior [][]float64
+	PriorStrength float64
+
+	// Strategy decides which queries Generate prefers; nil means Uncertainty.
+	Strategy Strategy
+
+	// Loss penalizes responses the model disagrees with; nil means Hinge.
+	Loss Loss
+
+	// ListLoss, if set, replaces Loss with a loss over whole rankings.
+	ListLoss ListLoss
+
+	// Regularizer penalizes complex belief matrices; nil means NuclearNorm.
+	Regularizer Regularizer
+
+	// Backend computes SVDs; nil means Gauss.
+	Backend Backend
+
+	// StepSize chooses the step size of each update; nil means ConstantStep.
+	StepSize StepSize
+
+	// LambdaSchedule varies Lambda as responses accumulate; nil means the
+	// regularization strength is always Lambda.
+	LambdaSchedule LambdaSchedule
+
+	// AutoLambda, if set, periodically retunes Lambda; see TuneLambda.
+	AutoLambda *AutoLambda
+
+	// Convergence, if set, observes the engine after every response; see
+	// Converged.
+	Convergence *ConvergenceDetector
+
+	// Reliability holds the estimated probability that each user's responses
+	// agree with the model, when configured WithReliability. Responses are
+	// weighted by it in the loss, and users with low reliability are worth
+	// reviewing for quality control.
+	Reliability []float64
+
+	// LatencyWeight, if set, weights each response in the loss by a function
+	// of its Latency; see WithLatencyWeight.
+	LatencyWeight func(latency time.Duration) float64
+
+	// HalfLife, if positive, is how long it takes a response's weight in the
+	// loss to halve relative to the newest response; see WithTimeDecay.
+	HalfLife time.Duration
+
+	// Shrinkage, if positive, pulls users with few responses toward the
+	// average user; see WithUserShrinkage.
+	Shrinkage float64
+
+	// Thresholds divide differences in score into the levels of a Likert
+	// scale; see WithLikert.
+	Thresholds []float64
+
+	// Quarantined marks users whose responses are excluded from training;
+	// see Quarantine.
+	Quarantined []bool
+
+	// Privacy, if set, limits the ε spent by PrivateRanking.
+	Privacy *Privacy
+
+	// LossHistory holds the loss after each update since the engine was
+	// created or refit, if RecordLoss is set; OnLoss, if set, is called with
+	// each record as well. See WithLossHistory and WithLossCallback.
+	LossHistory []LossRecord
+	RecordLoss  bool
+	OnLoss      func(LossRecord)
+
+	// NonNegative, if set, projects X onto the non-negative orthant after
+	// every update; see WithNonNegative.
+	NonNegative bool
+
+	// Audit, if set, records every generated query and every response; see
+	// WithAudit. Generate cannot fail, so the first error recording a query
+	// is kept in AuditErr instead.
+	Audit    AuditSink
+	AuditErr error
+
+	// Logger, if set, is told of notable events, such as when the effective
+	// rank of X changes by more than RankThreshold; see WithLogger.
+	Logger        Logger
+	RankThreshold int
+
+	// Tracer, if set, traces the phases of RespondContext and
+	// GenerateContext; see WithTracer.
+	Tracer Tracer
+
+	// MetricsHook, if set, receives measurements of every update; see
+	// WithMetrics.
+	MetricsHook MetricsHook
+
+	// ExpvarName is the name under which WithExpvar published the engine's
+	// metrics, or empty if they are not published.
+	ExpvarName string
+
+	onUpdate []func(Snapshot)
+	onQuery  []func(Query, Snapshot)
+
+	// publish holds the effects of options that must wait until every
+	// option has been applied, as NewEngineSafe may yet fail before then.
+	publish []func()
+
+	rng *rand.Rand
+	updates int
+	learned int
+	lastUpdate, lastProx time.Duration
+	lastRank int
+	newest time.Time
 }
 
 // Struct Query represents a prompt to the user.
 type Query struct {
 	User int
+
+	// Choices lists the choices from most to least preferred. Generate asks
+	// about pairs, but responses may rank any number of choices.
 	Choices []int
+
+	// Margin, if positive, overrides the engine's Margin for this response.
+	Margin float64
+
+	// Latency, if positive, records how long the user took to respond; see
+	// WithLatencyWeight.
+	Latency time.Duration
+
+	// Weight, if positive, scales the response's contribution to the loss,
+	// for example to express confidence or to stand for several duplicate
+	// responses. Zero means a weight of one.
+	Weight float64
+
+	// Likert, if positive, records an ordinal response on the scale set by
+	// WithLikert, from 1 (strongly prefer Choices[1]) to the number of levels
+	// (strongly prefer Choices[0]), rather than a plain preference.
+	Likert int
+
+	// Context optionally describes the circumstances of the response, such
+	// as the device, time of day, or occasion, as a feature vector; see
+	// WithContext.
+	Context []float64
+
+	// Time records when the response was given. Respond sets it to the
+	// current time if it is zero.
+	Time time.Time
+
 	weight float64
 }
 
 // NewEngine allocates and initializes a learning engine for the given corpus
-// size. By default, users consider all elements equally.
-func NewEngine(users, choices int) *Engine {
-	return &Engine{
+// size. By default, users consider all elements equally. NewEngine panics if
+// the size or any of the options are invalid; see NewEngineSafe.
+func NewEngine(users, choices int, opts ...Option) *Engine {
+	p, err := NewEngineSafe(users, choices, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// NewEngineSafe is like NewEngine, but returns an error rather than panicking
+// if the size or any of the options are invalid. Either size may be zero, for
+// engines that are grown with AddUser and AddItem, such as TypedEngine. Until
+// such an engine has a user and two choices there is nothing to ask:
+// Generate panics, GenerateSafe returns ErrNoQuestion, and the servers in
+// this module reply with an error.
+func NewEngineSafe(users, choices int, opts ...Option) (*Engine, error) {
+	if users < 0 {
+		return nil, fmt.Errorf("must have users [%d] >= 0", users)
+	}
+	if choices < 0 {
+		return nil, fmt.Errorf("must have choices [%d] >= 0", choices)
+	}
+	p := &Engine{
 		X: gauss.Zero(users, choices),
 		Xp: gauss.Zero(users, choices),
 		Z: gauss.Zero(users, choices),
@@ -61,64 +267,446 @@ func NewEngine(users, choices int) *Engine {
 		Lambda: 0.04,
 		Alpha: 1,
 		T: 1,
+		Margin: 1,
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.validateParams(); err != nil {
+		return nil, err
+	}
+	for _, publish := range p.publish {
+		publish()
+	}
+	p.publish = nil
+	return p, nil
+}
+
+// Method AddUser appends a new user to the engine, returning its index. The
+// new user starts with a neutral (all-zero) preference row, unless the engine
+// is configured to cold-start from the existing users.
+func (p *Engine) AddUser() int {
+	users, choices := p.X.Shape[0]+1, p.X.Shape[1]
+	p.X = resize(p.X, users, choices)
+	p.Xp = resize(p.Xp, users, choices)
+	p.Z = resize(p.Z, users, choices)
+	if p.A.Shape != nil {
+		p.A = resize(p.A, users, p.A.Shape[1])
+	}
+	if p.CategoryOffsets.Shape != nil {
+		p.CategoryOffsets = resize(p.CategoryOffsets, users,
+			p.CategoryOffsets.Shape[1])
+	}
+	if p.UserFeatures != nil {
+		p.UserFeatures = append(p.UserFeatures, nil)
+	}
+	if p.Prior != nil {
+		p.Prior = append(p.Prior, unknownRow(choices))
+	}
+	if p.Reliability != nil {
+		p.Reliability = append(p.Reliability, 1)
+	}
+	if p.UserScales != nil {
+		p.UserOffsets = append(p.UserOffsets, 0)
+		p.UserScales = append(p.UserScales, 1)
+	}
+	if p.Quarantined != nil {
+		p.Quarantined = append(p.Quarantined, false)
+	}
+	if p.ColdStart == MeanStart && users > 1 {
+		p.initUser(users-1, p.meanUser(users-1))
+	}
+	return users - 1
+}
+
+// Method AddItem appends a new choice to the engine, returning its index. The
+// learned scores for existing choices are left untouched; the new choice ties
+// with everything until it has been compared.
+func (p *Engine) AddItem() int {
+	users, choices := p.X.Shape[0], p.X.Shape[1]+1
+	p.X = resize(p.X, users, choices)
+	p.Xp = resize(p.Xp, users, choices)
+	p.Z = resize(p.Z, users, choices)
+	if p.ItemFeatures != nil {
+		p.ItemFeatures = append(p.ItemFeatures, nil)
+	}
+	if p.ItemCategories != nil {
+		p.ItemCategories = append(p.ItemCategories, -1)
+	}
+	if p.B.Shape != nil {
+		p.B = resize(p.B, p.B.Shape[0], choices)
+	}
+	if p.C.Shape != nil {
+		p.C = resize(p.C, p.C.Shape[0], choices)
+	}
+	if p.Bias != nil {
+		p.Bias = append(p.Bias, 0)
+	}
+	for u := range p.Prior {
+		p.Prior[u] = append(p.Prior[u], math.NaN())
+	}
+	return choices - 1
+}
+
+// Method RemoveUser deletes a user from the engine. Every response from that
+// user is dropped from History, later users are renumbered down by one, and
+// the model is refit so that nothing learned from the removed user remains.
+func (p *Engine) RemoveUser(user int) error {
+	if user < 0 || user >= p.X.Shape[0] {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	history := make([]Query, 0, len(p.History))
+	for _, q := range p.History {
+		if q.User == user {
+			continue
+		}
+		if q.User > user {
+			q.User--
+		}
+		history = append(history, q)
+	}
+	p.History = history
+	if p.UserFeatures != nil {
+		p.UserFeatures = append(p.UserFeatures[:user:user],
+			p.UserFeatures[user+1:]...)
+	}
+	if p.Prior != nil {
+		p.Prior = append(p.Prior[:user:user], p.Prior[user+1:]...)
+	}
+	if p.Quarantined != nil {
+		p.Quarantined = append(p.Quarantined[:user:user],
+			p.Quarantined[user+1:]...)
+	}
+	p.refit(p.X.Shape[0]-1, p.X.Shape[1])
+	return nil
+}
+
+// Method RemoveItem deletes a choice from the engine. Any response comparing
+// that choice is dropped from History, later choices are renumbered down by
+// one, and the model is refit without them.
+func (p *Engine) RemoveItem(item int) error {
+	if item < 0 || item >= p.X.Shape[1] {
+		return fmt.Errorf("must have 0 <= choice [%d] < %d",
+			item, p.X.Shape[1])
+	}
+	history := make([]Query, 0, len(p.History))
+outer:
+	for _, q := range p.History {
+		choices := make([]int, len(q.Choices))
+		for i, choice := range q.Choices {
+			if choice == item {
+				continue outer
+			}
+			if choice > item {
+				choice--
+			}
+			choices[i] = choice
+		}
+		q.Choices = choices
+		history = append(history, q)
+	}
+	p.History = history
+	if p.ItemFeatures != nil {
+		p.ItemFeatures = append(p.ItemFeatures[:item:item],
+			p.ItemFeatures[item+1:]...)
+	}
+	if p.ItemCategories != nil {
+		p.ItemCategories = append(p.ItemCategories[:item:item],
+			p.ItemCategories[item+1:]...)
+	}
+	for u, row := range p.Prior {
+		p.Prior[u] = append(row[:item:item], row[item+1:]...)
+	}
+	p.refit(p.X.Shape[0], p.X.Shape[1]-1)
+	return nil
+}
+
+// Method Refit discards the learned state and replays History from scratch,
+// leaving the engine as though each response had just been given in order.
+func (p *Engine) Refit() {
+	p.refit(p.X.Shape[0], p.X.Shape[1])
+}
+
+func (p *Engine) refit(users, choices int) {
+	p.X = zero(p.X, users, choices)
+	p.Xp = zero(p.Xp, users, choices)
+	p.Z = zero(p.Z, users, choices)
+	if p.A.Shape != nil {
+		p.A = zero(p.A, users, p.A.Shape[1])
+	}
+	if p.B.Shape != nil {
+		p.B = zero(p.B, p.B.Shape[0], choices)
+	}
+	if p.C.Shape != nil {
+		p.C = zero(p.C, p.C.Shape[0], choices)
+	}
+	if p.CategoryOffsets.Shape != nil {
+		p.CategoryOffsets = zero(p.CategoryOffsets, users,
+			p.CategoryOffsets.Shape[1])
+	}
+	if p.Bias != nil {
+		p.Bias = make([]float64, choices)
+	}
+	if p.Reliability != nil {
+		p.Reliability = ones(users)
 	}
+	if p.UserScales != nil {
+		p.UserOffsets = make([]float64, users)
+		p.UserScales = ones(users)
+	}
+	p.applyPrior()
+	p.LossHistory = nil
+	p.Alpha = 1
+	p.updates, p.lastRank = 0, 0
+	for i := range p.History {
+		p.update(p.History[:i+1])
+	}
+	p.notifyUpdate()
 }
 
-func (p *Engine) hingeLoss(samps []Query) float64 {
+func (p *Engine) loss(samps []Query) float64 {
 	sum := 0.0
 	for _, x := range samps {
-		diff := *p.X.I(x.User, x.Choices[0]) - *p.X.I(x.User, x.Choices[1])
-		sum += math.Max(1 - diff, 0)
+		w := p.weight(x)
+		if x.Likert > 0 {
+			sum += w * p.ordinalLoss(x)
+			continue
+		}
+		if p.ListLoss != nil {
+			sum += w * p.ListLoss.Value(p.scores(x))
+			continue
+		}
+		for _, pair := range p.preferences(x) {
+			diff := p.scoreIn(x.User, pair[0], x.Context) -
+				p.scoreIn(x.User, pair[1], x.Context)
+			sum += w * p.lossFunc().Value(diff, p.margin(x))
+		}
 	}
 	return sum / float64(len(samps))
 }
 
+func (p *Engine) margin(q Query) float64 {
+	if q.Margin > 0 {
+		return q.Margin
+	}
+	return p.Margin
+}
+
+// weight returns the weight of the response in the loss: the product of its
+// own Weight and any reliability, latency, and time decay weighting.
+func (p *Engine) weight(q Query) float64 {
+	w := p.reliability(q.User)
+	if q.Weight > 0 {
+		w *= q.Weight
+	}
+	if p.LatencyWeight != nil && q.Latency > 0 {
+		w *= p.LatencyWeight(q.Latency)
+	}
+	if p.HalfLife > 0 && !q.Time.IsZero() {
+		w *= decay(p.newest.Sub(q.Time), p.HalfLife)
+	}
+	return w
+}
+
 func (p *Engine) gradientLoss(samps []Query) gauss.Array {
 	result := gauss.Zero(p.X.Shape...)
-	before := p.hingeLoss(samps)
-	for i := range result.Data {
-		p.X.Data[i] += 0.0001
-		result.Data[i] = (p.hingeLoss(samps) - before) / 0.0001
-		p.X.Data[i] -= 0.0001
+	p.responseGradients(samps, func(q Query, choice int, d float64) {
+		*result.I(q.User, choice) += d
+	})
+	return result
+}
+
+// responseGradients calls visit with the derivative of the loss with respect
+// to the score of each choice in each of the responses.
+func (p *Engine) responseGradients(samps []Query,
+	visit func(q Query, choice int, d float64)) {
+	n := float64(len(samps))
+	for _, x := range samps {
+		w := p.weight(x) / n
+		if x.Likert > 0 {
+			d := w * p.ordinalDerivative(x)
+			visit(x, x.Choices[0], d)
+			visit(x, x.Choices[1], -d)
+			continue
+		}
+		if p.ListLoss != nil {
+			for i, d := range p.ListLoss.Gradient(p.scores(x)) {
+				visit(x, x.Choices[i], w*d)
+			}
+			continue
+		}
+		for _, pair := range p.preferences(x) {
+			a, b := pair[0], pair[1]
+			diff := p.scoreIn(x.User, a, x.Context) -
+				p.scoreIn(x.User, b, x.Context)
+			da, db := p.pairDerivatives(diff, p.margin(x))
+			visit(x, a, w*da)
+			visit(x, b, w*db)
+		}
 	}
+}
 
-	return result
+// pairDerivatives returns the derivatives of the loss of a pair with respect
+// to the scores of its preferred and other choice. Hinge is differentiated by
+// forward differences, as the engine always has: at the margin, this takes
+// the one-sided derivative in the direction of each score, which converges
+// in fewer questions than a symmetric subgradient.
+func (p *Engine) pairDerivatives(diff, margin float64) (float64, float64) {
+	l := p.lossFunc()
+	if l != Hinge {
+		d := l.Derivative(diff, margin)
+		return d, -d
+	}
+	const h = 0.0001
+	v := l.Value(diff, margin)
+	return (l.Value(diff+h, margin) - v) / h, (l.Value(diff-h, margin) - v) / h
 }
 
 func (p *Engine) update(samps []Query) {
+	p.updateContext(context.Background(), samps)
+}
+
+func (p *Engine) updateContext(ctx context.Context, samps []Query) {
 	alphaP := (1 + math.Sqrt(1 + 4*p.Alpha*p.Alpha)) / 2
 
-	U, S, V := gauss.SVD(gauss.Sum(p.Z,
-		p.gradientLoss(samps).Scale(-p.Nu)))
-	for i := range S.Data {
-		S.Data[i] = math.Max(0, S.Data[i] - p.Lambda)
-	}
+	start := time.Now()
+	p.updates++
+	p.newest = newest(samps)
+	p.updateReliability(samps)
+	end := p.span(ctx, "collaborativepermute.gradient")
+	gradient := p.gradientLoss(samps)
+	end()
+	lambda := p.lambda(len(samps))
+	nu := p.stepSize(samps, gradient, lambda)
+	p.updateContextWeights(samps, nu)
+	p.updateUserNormalization(gradient, nu)
+	p.updateFeatureWeights(gradient, nu)
+	p.updateBias(gradient, nu)
+	p.updateCategoryOffsets(gradient, nu)
+	p.addPriorGradient(gradient)
+	p.addShrinkageGradient(gradient, samps)
 
+	next := p.Xp
 	p.Xp = p.X
-	p.X = gauss.Product(gauss.Product(U, gauss.Diagonal(S.Data)), V.Transpose())
-	p.Z = gauss.Sum(p.X, 
-		gauss.Sum(p.X, p.Xp.Scale(-1)).Scale((p.Alpha - 1) / alphaP))
+	prox, end := time.Now(), p.span(ctx, "collaborativepermute.svd")
+	p.X = assign(next, p.proximalStep(gradient, nu, lambda))
+	p.lastProx = time.Since(prox)
+	end()
+	p.Z = assign(p.Z, gauss.Sum(p.X,
+		gauss.Sum(p.X, p.Xp.Scale(-1)).Scale((p.Alpha - 1) / alphaP)))
 	p.Alpha = alphaP
+	p.lastUpdate = time.Since(start)
+	p.recordLoss(samps)
+	p.logUpdate(ctx, samps)
 }
 
 // Method Respond takes a completed Prompt and updates the engine's 
 // belief matrix.
 func (p *Engine) Respond(prompt Query) error {
-	if len(prompt.Choices) != 2{
-		return fmt.Errorf("can only handle binary rankings")
+	return p.RespondContext(context.Background(), prompt)
+}
+
+// Method RespondContext is like Respond, but traces the update as part of
+// ctx; see WithTracer.
+func (p *Engine) RespondContext(ctx context.Context, prompt Query) error {
+	ctx, end := p.startSpan(ctx, "collaborativepermute.Respond")
+	defer end()
+	if err := p.validate(prompt); err != nil {
+		return err
+	}
+	if prompt.Time.IsZero() {
+		prompt.Time = time.Now()
+	}
+	if err := p.audit(Responded, prompt); err != nil {
+		return err
+	}
+	p.History = append(p.History, prompt)
+	p.learn(ctx, len(p.History)-1)
+	return nil
+}
+
+// Method RespondBatch learns from several responses at once, taking a single
+// update step for all of them rather than one per response, as when
+// consuming a busy stream of responses. Invalid responses are skipped, and
+// described by the returned error; the rest are learned regardless.
+func (p *Engine) RespondBatch(prompts []Query) error {
+	var errs []error
+	before := len(p.History)
+	now := time.Now()
+	for i, prompt := range prompts {
+		if err := p.validate(prompt); err != nil {
+			errs = append(errs, fmt.Errorf("response %d: %v", i, err))
+			continue
+		}
+		if prompt.Time.IsZero() {
+			prompt.Time = now
+		}
+		if err := p.audit(Responded, prompt); err != nil {
+			errs = append(errs, fmt.Errorf("response %d: %v", i, err))
+			continue
+		}
+		p.History = append(p.History, prompt)
+	}
+	if len(p.History) > before {
+		p.learn(context.Background(), before)
+	}
+	return errors.Join(errs...)
+}
+
+// learn takes an update step for the responses appended to History after the
+// first before.
+func (p *Engine) learn(ctx context.Context, before int) {
+	p.learned = len(p.History) - before
+	p.updateContext(ctx, p.History)
+	p.learned = 0
+	if p.AutoLambda != nil &&
+		len(p.History)/p.AutoLambda.Every > before/p.AutoLambda.Every {
+		p.TuneLambda()
+	}
+	if p.Convergence != nil {
+		p.Convergence.Observe(p)
+	}
+	p.notifyUpdate()
+}
+
+func (p *Engine) validate(prompt Query) error {
+	if prompt.Margin < 0 {
+		return fmt.Errorf("must have Margin [%v] >= 0", prompt.Margin)
+	}
+	if prompt.Weight < 0 || math.IsNaN(prompt.Weight) {
+		return fmt.Errorf("must have Weight [%v] >= 0", prompt.Weight)
+	}
+	if err := p.validateContext(prompt.Context); err != nil {
+		return err
+	}
+	if err := p.validateLikert(prompt); err != nil {
+		return err
 	}
 	if prompt.User < 0 || prompt.User >= p.X.Shape[0] {
 		return fmt.Errorf("must have 0 <= user [%d] < %d",
 			prompt.User, p.X.Shape[0])
 	}
-	for _, choice := range prompt.Choices {
+	return p.validateChoices(prompt.Choices)
+}
+
+func (p *Engine) validateChoices(choices []int) error {
+	if len(choices) < 2 {
+		return fmt.Errorf("must rank at least two choices")
+	}
+	for i, choice := range choices {
 		if choice < 0 || choice >= p.X.Shape[1] {
 			return fmt.Errorf("must have 0 <= choice [%d] < %d",
 				choice, p.X.Shape[1])
 		}
+		for _, other := range choices[:i] {
+			if other == choice {
+				return fmt.Errorf("choice %d is ranked twice", choice)
+			}
+		}
 	}
-	p.History = append(p.History, prompt)
-	p.update(p.History)
 	return nil
 }
 
@@ -127,42 +715,104 @@ func (p *Engine) Respond(prompt Query) error {
 // If user is non-negative, only return queries for that user. Otherwise, return
 // the query that would be the most helpful.
 func (p *Engine) Generate(user int) Query {
-	candidates := make([]Query, 0)
-	sum := 0.0
-	for u := 0; u < p.X.Shape[0]; u++ {
-		if user >= 0 && user != u {
-			continue
-		}
+	return p.GenerateContext(context.Background(), user)
+}
 
-		for a := 0; a < p.X.Shape[1]; a++ {
-			for b := 0; b < p.X.Shape[1]; b++ {
-				if a == b {
-					continue
-				}
-
-				diff := math.Abs(*p.X.I(u, a) - *p.X.I(u, b))
-				weight := math.Exp(-diff / p.T)
-				sum += weight
-				candidates = append(candidates, Query{
-					User: u,
-					Choices: []int{ a, b },
-					weight: weight,
-				})
-			}
+// Method GenerateContext is like Generate, but traces the choice of query as
+// part of ctx; see WithTracer.
+func (p *Engine) GenerateContext(ctx context.Context, user int) Query {
+	q, err := p.generate(ctx, user)
+	if err != nil {
+		panic("Could not find another question")
+	}
+	return q
+}
+
+// ErrNoQuestion is returned by GenerateSafe when there is no question to
+// ask, as when the engine has no users or fewer than two choices.
+var ErrNoQuestion = errors.New("could not find another question")
+
+// Method GenerateSafe is like Generate, but returns an error rather than
+// panicking if user is out of range, or ErrNoQuestion if there is no question
+// to ask. Servers should use it, so that a request cannot crash them.
+func (p *Engine) GenerateSafe(user int) (Query, error) {
+	return p.GenerateSafeContext(context.Background(), user)
+}
+
+// Method GenerateSafeContext is like GenerateSafe, but traces the choice of
+// query as part of ctx; see WithTracer.
+func (p *Engine) GenerateSafeContext(ctx context.Context, user int) (Query,
+	error) {
+	if user >= p.X.Shape[0] {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	return p.generate(ctx, user)
+}
+
+func (p *Engine) generate(ctx context.Context, user int) (Query, error) {
+	ctx, end := p.startSpan(ctx, "collaborativepermute.Generate")
+	defer end()
+	endCandidates := p.span(ctx, "collaborativepermute.candidates")
+	option, ok := sampleQuery(p.X.Shape[0], p.X.Shape[1], user, p.random,
+		func(u, a, b int) float64 { return p.strategy().Weight(p, u, a, b) },
+		p.Score)
+	endCandidates()
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	p.generated(option)
+	return option, nil
+}
+
+// generated audits a query about to be asked and passes it to the OnQuery
+// callbacks.
+func (p *Engine) generated(q Query) {
+	if err := p.audit(Generated, q); err != nil && p.AuditErr == nil {
+		p.AuditErr = err
+	}
+	p.notifyQuery(q)
+}
+
+// Method Score returns the engine's belief about how strongly the given user
+// prefers the given choice. Only the relative order of a user's scores is
+// meaningful. Like indexing a slice, Score panics if user or choice is out of
+// range; use Rank to check untrusted indices.
+func (p *Engine) Score(user, choice int) float64 {
+	score := p.baseScore(user, choice)
+	if p.UserScales != nil {
+		score = p.UserOffsets[user] + p.UserScales[user]*score
+	}
+	return score
+}
+
+// baseScore is Score without the user's learned offset and scale.
+func (p *Engine) baseScore(user, choice int) float64 {
+	score := *p.X.I(user, choice) + p.categoryOffset(user, choice)
+	if p.Bias != nil {
+		score += p.Bias[choice]
+	}
+	if p.A.Shape != nil && choice < len(p.ItemFeatures) {
+		for k, f := range p.ItemFeatures[choice] {
+			score += *p.A.I(user, k) * f
 		}
 	}
-	
-	offset := rand.Float64() * sum
-	for _, option := range candidates {
-		if offset < option.weight {
-			if *p.X.I(option.User, option.Choices[0]) <
-			   *p.X.I(option.User, option.Choices[1]) {
-				option.Choices[0], option.Choices[1] = option.Choices[1], option.Choices[0]
-			}
-			return option
+	if p.B.Shape != nil && user < len(p.UserFeatures) {
+		for k, f := range p.UserFeatures[user] {
+			score += f * *p.B.I(k, choice)
 		}
-		offset -= option.weight
 	}
-	
-	panic("Could not find another question")
-}
\ No newline at end of file
+	return score
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user, according to the current beliefs.
+func (p *Engine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	return rankBy(p.X.Shape[1], func(choice int) float64 {
+		return p.Score(user, choice)
+	}), nil
+}
@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"testing"
 	"fmt"
+	"math"
 )
 
 // In this example we are allowing the predictor to select which user to query.
@@ -61,4 +62,207 @@ func TestConvergence(t *testing.T) {
 	if incorrect > 40 {
 		t.Fatalf("needed %v mistakes for a 10x10 matrix", incorrect)
 	}
-}
\ No newline at end of file
+}
+
+func TestAddUser(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	eng.Respond(Query{User: 1, Choices: []int{2, 0}})
+	before := *eng.X.I(1, 2)
+
+	if u := eng.AddUser(); u != 2 {
+		t.Fatalf("expected new user 2, got %d", u)
+	}
+	if after := *eng.X.I(1, 2); after != before {
+		t.Fatalf("existing score changed from %v to %v", before, after)
+	}
+	for j := 0; j < 3; j++ {
+		if *eng.X.I(2, j) != 0 {
+			t.Fatalf("new user has non-zero score for %d", j)
+		}
+	}
+	if err := eng.Respond(Query{User: 2, Choices: []int{0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddItem(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 2)
+	eng.Respond(Query{User: 0, Choices: []int{1, 0}})
+	before := *eng.X.I(0, 1)
+
+	if c := eng.AddItem(); c != 2 {
+		t.Fatalf("expected new item 2, got %d", c)
+	}
+	if after := *eng.X.I(0, 1); after != before {
+		t.Fatalf("existing score changed from %v to %v", before, after)
+	}
+	if err := eng.Respond(Query{User: 0, Choices: []int{2, 1}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoveUser(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(3, 3)
+	ref := NewEngine(2, 3)
+	responses := []Query{
+		{User: 0, Choices: []int{0, 1}},
+		{User: 1, Choices: []int{2, 1}},
+		{User: 2, Choices: []int{1, 2}},
+		{User: 2, Choices: []int{0, 2}},
+	}
+	for _, q := range responses {
+		eng.Respond(q)
+		if q.User != 1 {
+			if q.User > 1 {
+				q.User--
+			}
+			ref.Respond(q)
+		}
+	}
+
+	if err := eng.RemoveUser(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(eng.History) != 3 {
+		t.Fatalf("expected 3 responses to remain, got %d", len(eng.History))
+	}
+	for i := range ref.X.Data {
+		if math.Abs(eng.X.Data[i]-ref.X.Data[i]) > 1e-9 {
+			t.Fatalf("refit model differs from one never given user 1")
+		}
+	}
+	if err := eng.RemoveUser(2); err == nil {
+		t.Fatalf("expected an error removing a nonexistent user")
+	}
+}
+
+func TestRemoveItem(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	eng.Respond(Query{User: 1, Choices: []int{2, 0}})
+
+	if err := eng.RemoveItem(1); err != nil {
+		t.Fatal(err)
+	}
+	if eng.X.Shape[1] != 2 {
+		t.Fatalf("expected 2 choices to remain, got %d", eng.X.Shape[1])
+	}
+	if len(eng.History) != 1 {
+		t.Fatalf("expected 1 response to remain, got %d", len(eng.History))
+	}
+	if c := eng.History[0].Choices; c[0] != 1 || c[1] != 0 {
+		t.Fatalf("expected choices to be renumbered to [1 0], got %v", c)
+	}
+}
+
+func TestWithCapacity(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 2, WithCapacity(4, 4))
+	ref := NewEngine(2, 2)
+	for _, e := range []*Engine{eng, ref} {
+		e.Respond(Query{User: 0, Choices: []int{1, 0}})
+		e.Respond(Query{User: 1, Choices: []int{0, 1}})
+	}
+	data := &eng.X.Data[:1][0]
+
+	for _, e := range []*Engine{eng, ref} {
+		e.AddUser()
+		e.AddItem()
+		e.AddItem()
+		e.Respond(Query{User: 2, Choices: []int{3, 1}})
+	}
+	if &eng.X.Data[:1][0] != data && &eng.Xp.Data[:1][0] != data {
+		t.Fatalf("growth within capacity reallocated the belief matrix")
+	}
+	for i := range ref.X.Data {
+		if math.Abs(eng.X.Data[i]-ref.X.Data[i]) > 1e-9 {
+			t.Fatalf("preallocated engine diverged at %d", i)
+		}
+	}
+}
+
+func TestMargin(t *testing.T) {
+	rand.Seed(23)
+	wide := NewEngine(1, 2, WithMargin(4))
+	narrow := NewEngine(1, 2)
+	perResponse := NewEngine(1, 2)
+	for i := 0; i < 3; i++ {
+		wide.Respond(Query{User: 0, Choices: []int{1, 0}})
+		narrow.Respond(Query{User: 0, Choices: []int{1, 0}})
+		perResponse.Respond(Query{User: 0, Choices: []int{1, 0}, Margin: 4})
+	}
+
+	gap := func(p *Engine) float64 { return p.Score(0, 1) - p.Score(0, 0) }
+	if gap(wide) <= gap(narrow) {
+		t.Fatalf("expected a wider margin to separate scores further")
+	}
+	if math.Abs(gap(wide)-gap(perResponse)) > 1e-9 {
+		t.Fatalf("expected per-response margin to match engine margin")
+	}
+	if err := narrow.Respond(Query{Choices: []int{0, 1}, Margin: -1}); err == nil {
+		t.Fatalf("expected an error for a negative margin")
+	}
+}
+
+func TestWeight(t *testing.T) {
+	rand.Seed(23)
+	heavy := NewEngine(1, 3)
+	light := NewEngine(1, 3)
+	for i := 0; i < 3; i++ {
+		heavy.Respond(Query{Choices: []int{0, 1}})
+		heavy.Respond(Query{Choices: []int{1, 0}, Weight: 3})
+		light.Respond(Query{Choices: []int{0, 1}})
+		light.Respond(Query{Choices: []int{1, 0}, Weight: 0.2})
+	}
+
+	if heavy.Score(0, 1) <= heavy.Score(0, 0) {
+		t.Fatalf("expected the heavier response to win, got %v", heavy.X.Data)
+	}
+	if light.Score(0, 0) <= light.Score(0, 1) {
+		t.Fatalf("expected the lighter response to lose, got %v", light.X.Data)
+	}
+	if err := light.Respond(Query{Choices: []int{0, 1}, Weight: -1}); err == nil {
+		t.Fatalf("expected an error for a negative weight")
+	}
+}
+
+func TestGenerateSafe(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 2)
+	if q, err := eng.GenerateSafe(1); err != nil || q.User != 1 {
+		t.Fatalf("expected a question for user 1, got %v, %v", q, err)
+	}
+	if _, err := eng.GenerateSafe(2); err == nil {
+		t.Fatalf("expected an error for a nonexistent user")
+	}
+	if _, err := NewEngine(1, 1).GenerateSafe(-1); err != ErrNoQuestion {
+		t.Fatalf("expected ErrNoQuestion with only one choice, got %v", err)
+	}
+	if _, err := NewEngine(0, 3).GenerateSafe(-1); err != ErrNoQuestion {
+		t.Fatalf("expected ErrNoQuestion with no users, got %v", err)
+	}
+}
+
+func TestRespondBatch(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	err := eng.RespondBatch([]Query{
+		{User: 0, Choices: []int{2, 0}},
+		{User: 4, Choices: []int{0, 1}},
+		{User: 1, Choices: []int{1, 0}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error for the invalid response")
+	}
+	if len(eng.History) != 2 || eng.History[1].User != 1 {
+		t.Fatalf("expected the two valid responses to be learned")
+	}
+	if eng.Score(0, 2) <= eng.Score(0, 0) || eng.Score(1, 1) <= eng.Score(1, 0) {
+		t.Fatalf("expected one update to learn both responses, got %v",
+			eng.X.Data)
+	}
+}
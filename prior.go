@@ -0,0 +1,91 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+	"math"
+)
+
+// Method SetPrior seeds the engine with known scores, such as historical
+// ratings. The matrix must be users×choices; NaN entries mark scores that are
+// unknown. X is initialized to the known scores, and every later update is
+// regularized toward them with the given strength, so that questions are
+// spent on what the prior does not already say.
+func (p *Engine) SetPrior(matrix [][]float64, strength float64) error {
+	if strength < 0 {
+		return fmt.Errorf("must have strength [%v] >= 0", strength)
+	}
+	if len(matrix) != p.X.Shape[0] {
+		return fmt.Errorf("must have len(matrix) [%d] == %d",
+			len(matrix), p.X.Shape[0])
+	}
+	prior := make([][]float64, len(matrix))
+	for u, row := range matrix {
+		if len(row) != p.X.Shape[1] {
+			return fmt.Errorf("must have len(matrix[%d]) [%d] == %d",
+				u, len(row), p.X.Shape[1])
+		}
+		prior[u] = append([]float64(nil), row...)
+	}
+	p.Prior = prior
+	p.PriorStrength = strength
+	p.applyPrior()
+	return nil
+}
+
+// applyPrior overwrites the known entries of every belief matrix with the
+// prior, so that the accelerated scheme carries no momentum for them.
+func (p *Engine) applyPrior() {
+	for u, row := range p.Prior {
+		for j, value := range row {
+			if math.IsNaN(value) {
+				continue
+			}
+			*p.X.I(u, j) = value
+			*p.Xp.I(u, j) = value
+			*p.Z.I(u, j) = value
+		}
+	}
+}
+
+// addPriorGradient adds the gradient of the penalty
+// (PriorStrength/2)·‖X - Prior‖² over the known entries.
+func (p *Engine) addPriorGradient(gradient gauss.Array) {
+	for u, row := range p.Prior {
+		for j, value := range row {
+			if math.IsNaN(value) {
+				continue
+			}
+			*gradient.I(u, j) += p.PriorStrength * (*p.X.I(u, j) - value)
+		}
+	}
+}
+
+func unknownRow(n int) []float64 {
+	row := make([]float64, n)
+	for i := range row {
+		row[i] = math.NaN()
+	}
+	return row
+}
+
+// Type Matrix is a read-only two-dimensional matrix. Every gonum mat.Matrix
+// satisfies it.
+type Matrix interface {
+	Dims() (rows, cols int)
+	At(i, j int) float64
+}
+
+// Method SetPriorMatrix is like SetPrior, but takes the known scores as a
+// Matrix, such as a gonum mat.Dense.
+func (p *Engine) SetPriorMatrix(m Matrix, strength float64) error {
+	rows, cols := m.Dims()
+	matrix := make([][]float64, rows)
+	for u := range matrix {
+		matrix[u] = make([]float64, cols)
+		for j := range matrix[u] {
+			matrix[u][j] = m.At(u, j)
+		}
+	}
+	return p.SetPrior(matrix, strength)
+}
@@ -0,0 +1,66 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSetPrior(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	nan := math.NaN()
+	err := eng.SetPrior([][]float64{
+		{3, 2, 1},
+		{nan, nan, nan},
+	}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranking, _ := eng.Rank(0); ranking[0] != 0 || ranking[2] != 2 {
+		t.Fatalf("expected prior ranking, got %v", ranking)
+	}
+
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 1, Choices: []int{2, 0}})
+	}
+	if ranking, _ := eng.Rank(0); ranking[0] != 0 {
+		t.Fatalf("expected prior to persist through updates, got %v", ranking)
+	}
+
+	eng.Refit()
+	if ranking, _ := eng.Rank(0); ranking[0] != 0 {
+		t.Fatalf("expected prior to persist through refit, got %v", ranking)
+	}
+
+	eng.AddItem()
+	if !math.IsNaN(eng.Prior[0][3]) {
+		t.Fatalf("expected new choice to have an unknown prior")
+	}
+	if err := eng.SetPrior([][]float64{{1}}, 1); err == nil {
+		t.Fatalf("expected an error for a mis-sized prior")
+	}
+}
+
+// constant is a Matrix whose entries are all the same.
+type constant struct {
+	rows, cols int
+	value      float64
+}
+
+func (c constant) Dims() (int, int)    { return c.rows, c.cols }
+func (c constant) At(i, j int) float64 { return c.value }
+
+func TestSetPriorMatrix(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	if err := eng.SetPriorMatrix(constant{2, 3, 4}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if eng.Prior[1][2] != 4 || *eng.X.I(1, 2) != 4 {
+		t.Fatalf("expected the prior to be set, got %v", eng.Prior)
+	}
+	if err := eng.SetPriorMatrix(constant{3, 2, 4}, 1); err == nil {
+		t.Fatalf("expected an error for a matrix of the wrong size")
+	}
+}
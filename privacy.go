@@ -0,0 +1,72 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Struct Privacy tracks a differential privacy budget. Each private release
+// spends part of the Budget, and by sequential composition the releases
+// together are Spent-differentially private.
+type Privacy struct {
+	Budget, Spent float64
+}
+
+// WithPrivacyBudget limits the total ε that PrivateRanking may spend over the
+// life of the engine.
+func WithPrivacyBudget(epsilon float64) Option {
+	return func(p *Engine) error {
+		if !(epsilon > 0) {
+			return fmt.Errorf("must have epsilon [%v] > 0", epsilon)
+		}
+		p.Privacy = &Privacy{Budget: epsilon}
+		return nil
+	}
+}
+
+// Method PrivateRanking returns a consensus ranking of the choices across all
+// users that is ε-differentially private with respect to each response in
+// History, so that it can be published without revealing any individual
+// comparison.
+//
+// Each response awards its preferred choices a total of one point, split
+// evenly among the pairs it implies, so adding or removing a response
+// changes the points by at most one in L1 norm. Laplace noise of scale 1/ε is
+// added to each choice's points (the Laplace mechanism), and the choices are
+// ranked by their noisy points.
+//
+// If the engine has a privacy budget, epsilon is deducted from it, and an
+// error is returned instead if the budget would be exceeded.
+func (p *Engine) PrivateRanking(epsilon float64) ([]int, error) {
+	if !(epsilon > 0) {
+		return nil, fmt.Errorf("must have epsilon [%v] > 0", epsilon)
+	}
+	if p.Privacy != nil {
+		if p.Privacy.Spent+epsilon > p.Privacy.Budget {
+			return nil, fmt.Errorf("privacy budget exhausted: spent %v of %v",
+				p.Privacy.Spent, p.Privacy.Budget)
+		}
+		p.Privacy.Spent += epsilon
+	}
+
+	points := make([]float64, p.X.Shape[1])
+	for _, q := range p.History {
+		implied := p.preferences(q)
+		for _, pair := range implied {
+			points[pair[0]] += 1 / float64(len(implied))
+		}
+	}
+	for c := range points {
+		points[c] += p.laplace(1 / epsilon)
+	}
+	return rankBy(len(points), func(c int) float64 { return points[c] }), nil
+}
+
+// laplace returns a sample from the Laplace distribution with the given scale.
+func (p *Engine) laplace(scale float64) float64 {
+	u := p.random() - 0.5
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
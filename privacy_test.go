@@ -0,0 +1,50 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPrivateRanking(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(50, 4, WithPrivacyBudget(2))
+	for u := 0; u < 50; u++ {
+		for a := 0; a < 4; a++ {
+			for b := a + 1; b < 4; b++ {
+				eng.Respond(Query{User: u, Choices: []int{a, b}})
+			}
+		}
+	}
+
+	ranking, err := eng.PrivateRanking(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranking[0] != 0 || ranking[3] != 3 {
+		t.Fatalf("expected the consensus to survive the noise, got %v", ranking)
+	}
+
+	if _, err := eng.PrivateRanking(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.PrivateRanking(0.5); err == nil {
+		t.Fatalf("expected an error once the budget is exhausted")
+	}
+	if eng.Privacy.Spent != 2 {
+		t.Fatalf("expected to have spent 2, got %v", eng.Privacy.Spent)
+	}
+	if _, err := NewEngine(1, 2).PrivateRanking(0); err == nil {
+		t.Fatalf("expected an error for a zero epsilon")
+	}
+}
+
+func TestPrivacyClone(t *testing.T) {
+	eng := NewEngine(2, 3, WithPrivacyBudget(1))
+	c := eng.clone()
+	if _, err := c.PrivateRanking(1); err != nil {
+		t.Fatal(err)
+	}
+	if eng.Privacy.Spent != 0 {
+		t.Fatalf("expected the clone not to spend the original's budget")
+	}
+}
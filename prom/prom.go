@@ -0,0 +1,97 @@
+// Package prom exports measurements of a collaborativepermute engine to
+// Prometheus.
+//
+//	c := prom.NewCollector("recommender")
+//	prometheus.MustRegister(c)
+//	eng := collaborativepermute.NewEngine(users, choices,
+//		collaborativepermute.WithMetrics(c))
+package prom
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Struct Collector is a prometheus.Collector fed by an engine's MetricsHook.
+// It exports:
+//
+//	<namespace>_responses_total         counter of responses learned from
+//	<namespace>_update_seconds          histogram of update latency
+//	<namespace>_svd_seconds             histogram of time spent in the SVD
+//	<namespace>_history_size            gauge of responses in History
+//	<namespace>_loss                    gauge of the mean loss over History
+//
+// Refitting an engine replays its updates, which are observed again by the
+// histograms but not counted again as responses.
+type Collector struct {
+	responses prometheus.Counter
+	update    prometheus.Histogram
+	svd       prometheus.Histogram
+	history   prometheus.Gauge
+	loss      prometheus.Gauge
+}
+
+var _ collaborativepermute.MetricsHook = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a Collector whose metrics are prefixed by namespace.
+func NewCollector(namespace string) *Collector {
+	latency := prometheus.ExponentialBuckets(1e-5, 4, 10)
+	return &Collector{
+		responses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "responses_total",
+			Help:      "Number of responses the engine has learned from.",
+		}),
+		update: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "update_seconds",
+			Help:      "Time taken by each update of the engine.",
+			Buckets:   latency,
+		}),
+		svd: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "svd_seconds",
+			Help:      "Time spent in the SVD of each update.",
+			Buckets:   latency,
+		}),
+		history: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "history_size",
+			Help:      "Number of responses in the engine's History.",
+		}),
+		loss: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "loss",
+			Help:      "Mean loss over the engine's History.",
+		}),
+	}
+}
+
+// Method ObserveUpdate records the measurements of one update.
+func (c *Collector) ObserveUpdate(m collaborativepermute.UpdateMetrics) {
+	c.responses.Add(float64(m.Learned))
+	c.update.Observe(m.Duration.Seconds())
+	c.svd.Observe(m.SVD.Seconds())
+	c.history.Set(float64(m.Responses))
+	c.loss.Set(m.Loss)
+}
+
+// Method Describe sends the descriptors of the exported metrics.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Method Collect sends the current value of the exported metrics.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.responses, c.update, c.svd, c.history,
+		c.loss}
+}
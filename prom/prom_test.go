@@ -0,0 +1,39 @@
+package prom
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"math/rand"
+	"testing"
+)
+
+func TestCollector(t *testing.T) {
+	rand.Seed(23)
+	c := NewCollector("test")
+	eng := collaborativepermute.NewEngine(2, 3, collaborativepermute.WithMetrics(c))
+	eng.Respond(collaborativepermute.Query{User: 0, Choices: []int{0, 1}})
+	eng.Respond(collaborativepermute.Query{User: 1, Choices: []int{2, 1}})
+	eng.Refit()
+
+	if n := testutil.ToFloat64(c.responses); n != 2 {
+		t.Fatalf("expected 2 responses, got %v", n)
+	}
+	if n := testutil.ToFloat64(c.history); n != 2 {
+		t.Fatalf("expected a history of 2, got %v", n)
+	}
+	if n := testutil.CollectAndCount(c); n != 5 {
+		t.Fatalf("expected 5 metrics, got %d", n)
+	}
+
+	// Removing a user shrinks the History, but responses learned afterward
+	// are still counted.
+	eng.RemoveUser(0)
+	eng.Respond(collaborativepermute.Query{User: 0, Choices: []int{0, 2}})
+	eng.RespondBatch([]collaborativepermute.Query{
+		{User: 0, Choices: []int{1, 0}},
+		{User: 0, Choices: []int{2, 0}},
+	})
+	if n := testutil.ToFloat64(c.responses); n != 5 {
+		t.Fatalf("expected 5 responses, got %v", n)
+	}
+}
@@ -0,0 +1,113 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Consensus is the User of a RankingChange in the consensus ranking, which
+// orders choices by their mean score over all users.
+const Consensus = -1
+
+// Struct RankingChange describes how the top of a ranking moved after an
+// update; see OnRankingChange.
+type RankingChange struct {
+	// User is the user whose ranking changed, or Consensus.
+	User int `json:"user"`
+
+	// Before is the top of the ranking when it was last reported, and After
+	// the top now, both from most to least preferred.
+	Before []int `json:"before"`
+	After  []int `json:"after"`
+
+	// Changed is the number of positions at which Before and After differ.
+	Changed int `json:"changed"`
+
+	// Responses is the number of responses learned at the time.
+	Responses int `json:"responses"`
+}
+
+// Method OnRankingChange calls f after an update whenever the top k choices of
+// some user, or of the consensus ranking, differ from those last reported in
+// more than threshold positions; a threshold of zero reports every change.
+// The first report for each ranking compares against the ranking when
+// OnRankingChange was called, or for users added later, when they were
+// added; after RemoveUser, every user's ranking is compared against the one
+// at the time of the removal. Package webhook sends these changes to an HTTP
+// endpoint.
+func (p *Engine) OnRankingChange(k, threshold int, f func(RankingChange)) error {
+	if k < 1 {
+		return fmt.Errorf("must have k [%d] >= 1", k)
+	}
+	if threshold < 0 || threshold >= k {
+		return fmt.Errorf("must have 0 <= threshold [%d] < k [%d]",
+			threshold, k)
+	}
+	s := Snapshot{p}
+	consensus := s.topK(Consensus, k)
+	var users [][]int
+	for u := 0; u < s.Users(); u++ {
+		users = append(users, s.topK(u, k))
+	}
+
+	p.OnUpdate(func(s Snapshot) {
+		report := func(user int, before []int) []int {
+			after := s.topK(user, k)
+			changed := 0
+			for i := 0; i < len(before) || i < len(after); i++ {
+				if i >= len(before) || i >= len(after) ||
+					before[i] != after[i] {
+					changed++
+				}
+			}
+			if changed <= threshold {
+				return before
+			}
+			f(RankingChange{
+				User:      user,
+				Before:    before,
+				After:     after,
+				Changed:   changed,
+				Responses: s.Responses(),
+			})
+			return after
+		}
+		if len(users) > s.Users() {
+			// RemoveUser renumbers the users after the one removed, so
+			// the baselines no longer line up; start them afresh.
+			users = nil
+		}
+		for u := range users {
+			users[u] = report(u, users[u])
+		}
+		for u := len(users); u < s.Users(); u++ {
+			users = append(users, s.topK(u, k))
+		}
+		consensus = report(Consensus, consensus)
+	})
+	return nil
+}
+
+// topK returns the first k choices in the ranking of user, or of the
+// consensus, which is empty while there are no users.
+func (s Snapshot) topK(user, k int) []int {
+	if user == Consensus && s.Users() == 0 {
+		return []int{}
+	}
+	score := func(choice int) float64 {
+		return s.Score(user, choice)
+	}
+	if user == Consensus {
+		score = func(choice int) float64 {
+			sum := 0.0
+			for u := 0; u < s.Users(); u++ {
+				sum += s.Score(u, choice)
+			}
+			return sum / float64(s.Users())
+		}
+	}
+	ranking := rankBy(s.Choices(), score)
+	if len(ranking) > k {
+		ranking = ranking[:k]
+	}
+	return ranking
+}
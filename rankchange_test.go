@@ -0,0 +1,94 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOnRankingChange(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+	var changes []RankingChange
+	if err := eng.OnRankingChange(2, 0, func(c RankingChange) {
+		changes = append(changes, c)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	eng.Respond(Query{User: 1, Choices: []int{3, 2}})
+	if len(changes) != 2 {
+		t.Fatalf("expected changes for user 1 and the consensus, got %v",
+			changes)
+	}
+	if c := changes[0]; c.User != 1 || c.After[0] != 3 || c.Responses != 1 {
+		t.Fatalf("unexpected change %+v", c)
+	}
+	if changes[1].User != Consensus || changes[1].After[0] != 3 {
+		t.Fatalf("expected the consensus to change, got %+v", changes[1])
+	}
+
+	changes = nil
+	eng.Respond(Query{User: 1, Choices: []int{3, 2}})
+	for _, c := range changes {
+		if c.User == 0 {
+			t.Fatalf("expected user 0's ranking not to change, got %+v", c)
+		}
+	}
+
+	if err := eng.OnRankingChange(2, 2, func(RankingChange) {}); err == nil {
+		t.Fatalf("expected an error for a threshold that can never be met")
+	}
+}
+
+func TestOnRankingChangeThreshold(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(1, 3)
+	fired := 0
+	eng.OnRankingChange(3, 2, func(RankingChange) { fired++ })
+
+	eng.Respond(Query{Choices: []int{2, 1, 0}})
+	if fired != 0 {
+		t.Fatalf("expected a reversal, moving 2 of 3 choices, not to exceed 2")
+	}
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{Choices: []int{1, 2, 0}})
+	}
+	if rank, _ := eng.Rank(0); rank[0] != 1 || rank[1] != 2 {
+		t.Fatalf("expected ranking [1 2 0], got %v", rank)
+	}
+	if fired != 2 {
+		t.Fatalf("expected the user and consensus rotations to be reported, "+
+			"got %d", fired)
+	}
+}
+
+func TestOnRankingChangeRemoveUser(t *testing.T) {
+	rand.Seed(23)
+	empty := NewEngine(0, 3)
+	if err := empty.OnRankingChange(2, 0, func(RankingChange) {}); err != nil {
+		t.Fatal(err)
+	}
+	empty.AddUser()
+
+	eng := NewEngine(3, 4)
+	eng.Respond(Query{User: 0, Choices: []int{3, 2}})
+	eng.Respond(Query{User: 1, Choices: []int{0, 1}})
+	var changes []RankingChange
+	eng.OnRankingChange(2, 0, func(c RankingChange) {
+		changes = append(changes, c)
+	})
+	if err := eng.RemoveUser(0); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.User != Consensus {
+			t.Fatalf("expected no user to change when another is removed, "+
+				"got %+v", c)
+		}
+	}
+	for eng.X.Shape[0] > 0 {
+		if err := eng.RemoveUser(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+	"math"
+)
+
+// Type Regularizer is a penalty on the belief matrix X, applied through its
+// proximal operator after each gradient step.
+type Regularizer interface {
+	// Penalty returns lambda times the value of the regularizer at x.
+	Penalty(x gauss.Array, lambda float64) float64
+
+	// Prox returns the matrix X minimizing ½‖X - y‖² + Penalty(X, lambda).
+	Prox(y gauss.Array, lambda float64) gauss.Array
+}
+
+type nuclearNorm struct {
+	backend Backend
+}
+
+func (n nuclearNorm) Penalty(x gauss.Array, lambda float64) float64 {
+	_, S, _ := orGauss(n.backend).SVD(x)
+	sum := 0.0
+	for _, s := range S.Data {
+		sum += s
+	}
+	return lambda * sum
+}
+
+func (n nuclearNorm) Prox(y gauss.Array, lambda float64) gauss.Array {
+	U, S, V := orGauss(n.backend).SVD(y)
+	for i := range S.Data {
+		S.Data[i] = math.Max(0, S.Data[i]-lambda)
+	}
+	return recompose(U, S, V)
+}
+
+type frobenius struct{}
+
+func (frobenius) Penalty(x gauss.Array, lambda float64) float64 {
+	sum := 0.0
+	for _, v := range x.Data {
+		sum += v * v
+	}
+	return lambda * sum / 2
+}
+
+func (frobenius) Prox(y gauss.Array, lambda float64) gauss.Array {
+	return y.Scale(1 / (1 + lambda))
+}
+
+// Struct ElasticNet mixes the nuclear and Frobenius norms, weighting the
+// nuclear norm by Ratio and the Frobenius penalty by 1 - Ratio. Backend, if
+// set, computes the SVD of the nuclear norm; otherwise, the engine's does.
+type ElasticNet struct {
+	Ratio   float64
+	Backend Backend
+}
+
+// Method Penalty returns the weighted sum of the two penalties.
+func (e ElasticNet) Penalty(x gauss.Array, lambda float64) float64 {
+	return nuclearNorm{e.Backend}.Penalty(x, e.Ratio*lambda) +
+		Frobenius.Penalty(x, (1-e.Ratio)*lambda)
+}
+
+// Method Prox thresholds the singular values of y, then shrinks the result.
+func (e ElasticNet) Prox(y gauss.Array, lambda float64) gauss.Array {
+	return Frobenius.Prox(nuclearNorm{e.Backend}.Prox(y, e.Ratio*lambda),
+		(1-e.Ratio)*lambda)
+}
+
+var (
+	// NuclearNorm is the trace norm from (Wang KDD '14), whose proximal
+	// operator thresholds singular values and so favors low-rank matrices in
+	// which users share tastes. This is the default.
+	NuclearNorm Regularizer = nuclearNorm{}
+
+	// Frobenius is half the squared Frobenius (L2) norm. Its proximal
+	// operator is a simple rescaling, which avoids computing an SVD on every
+	// update, but it does not couple users together.
+	Frobenius Regularizer = frobenius{}
+)
+
+// WithRegularizer sets the penalty on the belief matrix.
+func WithRegularizer(r Regularizer) Option {
+	return func(p *Engine) error {
+		if e, ok := r.(ElasticNet); ok && (e.Ratio < 0 || e.Ratio > 1) {
+			return fmt.Errorf("must have 0 <= Ratio [%v] <= 1", e.Ratio)
+		}
+		p.Regularizer = r
+		return nil
+	}
+}
+
+// regularizer returns the engine's Regularizer, computing any SVDs with the
+// engine's Backend unless the regularizer was given its own.
+func (p *Engine) regularizer() Regularizer {
+	switch r := p.Regularizer.(type) {
+	case nil:
+		return nuclearNorm{p.backend()}
+	case nuclearNorm:
+		if r.backend == nil {
+			return nuclearNorm{p.backend()}
+		}
+	case ElasticNet:
+		if r.Backend == nil {
+			r.Backend = p.backend()
+			return r
+		}
+	}
+	return p.Regularizer
+}
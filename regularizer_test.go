@@ -0,0 +1,49 @@
+package collaborativepermute
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRegularizers(t *testing.T) {
+	for _, r := range []Regularizer{
+		NuclearNorm, Frobenius, ElasticNet{Ratio: 0.5},
+	} {
+		rand.Seed(23)
+		eng := NewEngine(3, 3, WithRegularizer(r))
+		for i := 0; i < 10; i++ {
+			eng.Respond(Query{User: i % 3, Choices: []int{2, 0}})
+		}
+		for u := 0; u < 3; u++ {
+			if ranking, _ := eng.Rank(u); ranking[0] != 2 {
+				t.Errorf("%T: user %d ranking %v", r, u, ranking)
+			}
+		}
+	}
+}
+
+func TestElasticNetEndpoints(t *testing.T) {
+	eng := NewEngine(2, 2)
+	eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	x := eng.X
+	for _, c := range []struct {
+		ratio float64
+		r     Regularizer
+	}{{1, NuclearNorm}, {0, Frobenius}} {
+		want := c.r.Prox(x, 0.3)
+		got := ElasticNet{Ratio: c.ratio}.Prox(x, 0.3)
+		for i := range want.Data {
+			if math.Abs(want.Data[i]-got.Data[i]) > 1e-9 {
+				t.Fatalf("ElasticNet{%v} does not match %T", c.ratio, c.r)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected an invalid ratio to be rejected")
+		}
+	}()
+	NewEngine(2, 2, WithRegularizer(ElasticNet{Ratio: 2}))
+}
@@ -0,0 +1,54 @@
+package collaborativepermute
+
+// WithReliability learns how reliable each user's responses are, and weights
+// each response in the loss by its user's reliability, so that inconsistent
+// respondents influence the model less. See Reliability.
+func WithReliability() Option {
+	return func(p *Engine) error {
+		p.Reliability = ones(p.X.Shape[0])
+		return nil
+	}
+}
+
+// updateReliability re-estimates each user's reliability as the fraction of
+// the pairs implied by their responses that the engine currently agrees with,
+// smoothed by one agreeing pseudo-response so that new users are trusted.
+func (p *Engine) updateReliability(samps []Query) {
+	if p.Reliability == nil {
+		return
+	}
+	correct := make([]int, len(p.Reliability))
+	total := make([]int, len(p.Reliability))
+	for _, q := range samps {
+		for _, pair := range p.preferences(q) {
+			if p.scoreIn(q.User, pair[0], q.Context) >
+				p.scoreIn(q.User, pair[1], q.Context) {
+				correct[q.User]++
+			}
+			total[q.User]++
+		}
+	}
+	for u := range p.Reliability {
+		p.Reliability[u] = float64(correct[u]+1) / float64(total[u]+1)
+	}
+}
+
+// reliability returns the weight of the user's responses in the loss, which
+// is zero if they are Quarantined.
+func (p *Engine) reliability(user int) float64 {
+	if p.Quarantined != nil && p.Quarantined[user] {
+		return 0
+	}
+	if p.Reliability == nil {
+		return 1
+	}
+	return p.Reliability[user]
+}
+
+func ones(n int) []float64 {
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = 1
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestReliability(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(4, 5, WithReliability())
+	for i := 0; i < 60; i++ {
+		user := i % 4
+		a, b := rand.Intn(5), rand.Intn(4)
+		if b >= a {
+			a, b = b+1, a
+		}
+		// Users 0-2 consistently prefer lower choices; user 3 answers at
+		// random.
+		if user == 3 && rand.Intn(2) == 0 {
+			a, b = b, a
+		}
+		eng.Respond(Query{User: user, Choices: []int{b, a}})
+	}
+
+	for u := 0; u < 3; u++ {
+		if eng.Reliability[u] <= eng.Reliability[3] {
+			t.Fatalf("expected user 3 to be least reliable, got %v",
+				eng.Reliability)
+		}
+	}
+
+	if eng.AddUser(); eng.Reliability[4] != 1 {
+		t.Fatalf("expected a new user to be trusted, got %v", eng.Reliability)
+	}
+}
@@ -0,0 +1,72 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+)
+
+// Method RemainingQuestions estimates how many more of the user's answers the
+// engine needs before it predicts their answers with the target accuracy,
+// such as 0.9, so that a survey can show "about 7 questions left."
+//
+// The estimate extrapolates the user's LearningCurve: the answers so far are
+// split into blocks, the error rate within each block is fit with a power law
+// error(n) = a·n^-b in the number of answers n, and the power law is solved
+// for the target. An error is returned if there are too few answers to fit,
+// or if the error rate is not falling.
+func (p *Engine) RemainingQuestions(user int, target float64) (int, error) {
+	if !(target > 0 && target < 1) {
+		return 0, fmt.Errorf("must have 0 < target [%v] < 1", target)
+	}
+	curve, err := p.LearningCurve(user)
+	if err != nil {
+		return 0, err
+	}
+	const blocks = 4
+	if len(curve) < blocks {
+		return 0, fmt.Errorf("need at least %d answers, have %d",
+			blocks, len(curve))
+	}
+
+	// Fit log(error) = log(a) - b·log(n) by least squares over the blocks,
+	// shrinking each block's error rate slightly away from zero.
+	var xs, ys []float64
+	size := len(curve) / blocks
+	for b := 0; b < blocks; b++ {
+		start, end := b*size, (b+1)*size
+		if b == blocks-1 {
+			end = len(curve)
+		}
+		wrong := 0.0
+		for _, point := range curve[start:end] {
+			wrong += 1 - point.Predicted
+		}
+		rate := (wrong + 0.5) / float64(end-start+1)
+		xs = append(xs, math.Log(float64(start+end)/2+0.5))
+		ys = append(ys, math.Log(rate))
+	}
+	if math.Exp(ys[blocks-1]) <= 1-target {
+		return 0, nil
+	}
+	slope, intercept := leastSquares(xs, ys)
+	if slope >= 0 {
+		return 0, fmt.Errorf("accuracy is not improving for user %d", user)
+	}
+	needed := math.Exp((math.Log(1-target) - intercept) / slope)
+	return int(math.Max(math.Ceil(needed)-float64(len(curve)), 0)), nil
+}
+
+// leastSquares returns the slope and intercept of the line best fitting the
+// points in the least-squares sense.
+func leastSquares(xs, ys []float64) (slope, intercept float64) {
+	n := float64(len(xs))
+	sx, sy, sxx, sxy := 0.0, 0.0, 0.0, 0.0
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return slope, (sy - slope*sx) / n
+}
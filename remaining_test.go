@@ -0,0 +1,43 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRemainingQuestions(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(1, 8)
+	respond := func(n int) {
+		for i := 0; i < n; i++ {
+			q := eng.Generate(0)
+			if q.Choices[0] > q.Choices[1] {
+				q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+			}
+			eng.Respond(q)
+		}
+	}
+
+	if _, err := eng.RemainingQuestions(0, 0.9); err == nil {
+		t.Fatalf("expected an error without answers")
+	}
+	respond(12)
+	early, err := eng.RemainingQuestions(0, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if early <= 0 {
+		t.Fatalf("expected more questions to be needed, got %d", early)
+	}
+	respond(60)
+	late, err := eng.RemainingQuestions(0, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late >= early {
+		t.Fatalf("expected fewer questions left, got %d then %d", early, late)
+	}
+	if _, err := eng.RemainingQuestions(0, 1); err == nil {
+		t.Fatalf("expected an error for a perfect target")
+	}
+}
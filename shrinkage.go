@@ -0,0 +1,61 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// A single Lambda regularizes every user equally, so users with hundreds of
+// responses are held back as much as users with three. With shrinkage, each
+// user's row of X is additionally pulled toward the average user with a
+// strength that falls off as they answer more questions: sparse users borrow
+// heavily from the shared structure, and prolific users are free to differ.
+
+// WithUserShrinkage shrinks each user's scores toward the average user with
+// strength Shrinkage/(1+n), where n is the number of responses from that
+// user; see UserLambda.
+func WithUserShrinkage(strength float64) Option {
+	return func(p *Engine) error {
+		if !(strength >= 0) {
+			return fmt.Errorf("must have strength [%v] >= 0", strength)
+		}
+		p.Shrinkage = strength
+		return nil
+	}
+}
+
+// Method UserLambda returns the strength with which the user's scores are
+// currently shrunk toward the average user.
+func (p *Engine) UserLambda(user int) (float64, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return 0, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	n := 0
+	for _, q := range p.History {
+		if q.User == user {
+			n++
+		}
+	}
+	return p.Shrinkage / float64(1+n), nil
+}
+
+// addShrinkageGradient adds the gradient of the penalty
+// Σ_u (λ_u/2)·‖X_u - mean‖², where λ_u is the user's strength given the
+// responses, and the mean row is held fixed.
+func (p *Engine) addShrinkageGradient(gradient gauss.Array, samps []Query) {
+	if p.Shrinkage == 0 || p.X.Shape[0] == 0 {
+		return
+	}
+	counts := make([]int, p.X.Shape[0])
+	for _, q := range samps {
+		counts[q.User]++
+	}
+	mean := p.meanUser(p.X.Shape[0])
+	for u, n := range counts {
+		strength := p.Shrinkage / float64(1+n)
+		for j, m := range mean {
+			*gradient.I(u, j) += strength * (*p.X.I(u, j) - m)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestUserShrinkage(t *testing.T) {
+	rand.Seed(23)
+	spread := func(shrinkage float64) (float64, *Engine) {
+		eng := NewEngine(3, 3, WithUserShrinkage(shrinkage))
+		// Users 0 and 1 answer often and agree; user 2 answered once, against
+		// them.
+		for i := 0; i < 20; i++ {
+			eng.Respond(Query{User: i % 2, Choices: []int{0, 2}})
+		}
+		eng.Respond(Query{User: 2, Choices: []int{2, 0}})
+		return eng.Score(2, 2) - eng.Score(2, 0), eng
+	}
+
+	free, _ := spread(0)
+	shrunk, eng := spread(1)
+	if shrunk >= free {
+		t.Fatalf("expected the sparse user to be shrunk, got %v and %v",
+			shrunk, free)
+	}
+	sparse, _ := eng.UserLambda(2)
+	prolific, _ := eng.UserLambda(0)
+	if sparse <= prolific {
+		t.Fatalf("expected the sparse user to be regularized harder")
+	}
+	if _, err := eng.UserLambda(eng.X.Shape[0]); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+	if _, err := NewEngineSafe(1, 2, WithUserShrinkage(-1)); err == nil {
+		t.Fatalf("expected an error for a negative strength")
+	}
+}
@@ -0,0 +1,95 @@
+package sim
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+)
+
+// Struct Comparison reports the outcome of running two learners on the same
+// simulated users.
+type Comparison struct {
+	A, B Result
+}
+
+// Method Winner returns 0 if learner A did better and 1 if B did, or -1 for a
+// tie. A learner that converged beats one that did not; between two that
+// converged, the one that needed fewer questions wins; and between two that
+// did not, the one with higher final accuracy wins.
+func (c Comparison) Winner() int {
+	a, b := c.A, c.B
+	switch {
+	case a.Converged != b.Converged:
+		if a.Converged {
+			return 0
+		}
+		return 1
+	case a.Converged && a.Questions != b.Questions:
+		if a.Questions < b.Questions {
+			return 0
+		}
+		return 1
+	case !a.Converged && a.Accuracy != b.Accuracy:
+		if a.Accuracy > b.Accuracy {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
+
+// Function Compare runs two learners, such as engines with different query
+// strategies, against the same Truth and Oracle with the same Config, and
+// reports which reached the target accuracy with fewer questions.
+func Compare(a, b collaborativepermute.Learner, truth Truth, oracle Oracle,
+	cfg Config) (Comparison, error) {
+	var c Comparison
+	var err error
+	if c.A, err = Run(a, truth, oracle, cfg); err != nil {
+		return c, err
+	}
+	c.B, err = Run(b, truth, oracle, cfg)
+	return c, err
+}
+
+// Struct ABTest routes live traffic between two learners. Questions for a
+// given user alternate between the arms, so each learner sees a mix of
+// users, and each response is sent to the arm that asked the question. The
+// arms' progress can then be compared on held-out responses.
+type ABTest struct {
+	Arms [2]collaborativepermute.Learner
+
+	// Questions counts the responses routed to each arm.
+	Questions [2]int
+
+	next    int
+	pending map[int]int
+}
+
+// Function NewABTest returns an ABTest between the two learners.
+func NewABTest(a, b collaborativepermute.Learner) *ABTest {
+	return &ABTest{
+		Arms:    [2]collaborativepermute.Learner{a, b},
+		pending: make(map[int]int),
+	}
+}
+
+// Method Generate asks the next arm in turn for a question, and remembers
+// which arm asked it.
+func (t *ABTest) Generate(user int) collaborativepermute.Query {
+	arm := t.next
+	t.next = 1 - t.next
+	q := t.Arms[arm].Generate(user)
+	t.pending[q.User] = arm
+	return q
+}
+
+// Method Respond sends the response to the arm that last asked its user a
+// question, or to arm A if none has.
+func (t *ABTest) Respond(q collaborativepermute.Query) error {
+	arm := t.pending[q.User]
+	if err := t.Arms[arm].Respond(q); err != nil {
+		return err
+	}
+	delete(t.pending, q.User)
+	t.Questions[arm]++
+	return nil
+}
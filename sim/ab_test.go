@@ -0,0 +1,48 @@
+package sim
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	rand.Seed(23)
+	truth := RandomTruth(8, 6, 1, rand.New(rand.NewSource(23)))
+	active := collaborativepermute.NewEngine(8, 6)
+	passive := collaborativepermute.NewEngine(8, 6,
+		collaborativepermute.WithStrategy(collaborativepermute.Uniform))
+
+	c, err := Compare(active, passive, truth, Perfect,
+		Config{MaxQuestions: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.A.Converged || !c.B.Converged {
+		t.Fatalf("expected both strategies to converge, got %+v", c)
+	}
+	if c.Winner() != 0 {
+		t.Fatalf("expected active learning to need fewer questions, got %+v", c)
+	}
+
+	tie := Comparison{A: Result{Accuracy: 0.5}, B: Result{Accuracy: 0.5}}
+	if tie.Winner() != -1 {
+		t.Fatalf("expected a tie")
+	}
+}
+
+func TestABTest(t *testing.T) {
+	rand.Seed(23)
+	truth := RandomTruth(4, 5, 1, rand.New(rand.NewSource(23)))
+	ab := NewABTest(collaborativepermute.NewEngine(4, 5),
+		collaborativepermute.NewEngine(4, 5))
+	for i := 0; i < 40; i++ {
+		q := ab.Generate(i % 4)
+		if err := ab.Respond(Perfect.Answer(q, truth)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ab.Questions[0] != 20 || ab.Questions[1] != 20 {
+		t.Fatalf("expected traffic to be split evenly, got %v", ab.Questions)
+	}
+}
@@ -0,0 +1,168 @@
+package sim
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+)
+
+// Struct Experiment declares a reproducible simulation study: a population of
+// synthetic users, how they answer, how the engine asks, and how many times
+// to repeat the run. A generator seeded with Seed draws an independent seed
+// for each source of randomness in each repetition, so the same Experiment
+// always gives the same Summary.
+type Experiment struct {
+	Name string `json:"name"`
+
+	// Users, Choices, and Rank size the random low-rank Truth.
+	Users   int `json:"users"`
+	Choices int `json:"choices"`
+	Rank    int `json:"rank"`
+
+	// Noise names the Oracle: "perfect" (the default), "logistic",
+	// "error", "lazy", or "random". NoiseLevel is the scale of logistic
+	// noise, which must be positive, or the rate of errors or lazy answers,
+	// which must be between 0 and 1.
+	Noise      string  `json:"noise"`
+	NoiseLevel float64 `json:"noise_level"`
+
+	// Strategy names the engine's query strategy: "uncertainty" (the
+	// default) or "uniform". Lambda, if positive, overrides the default
+	// regularization strength.
+	Strategy string  `json:"strategy"`
+	Lambda   float64 `json:"lambda"`
+
+	// MaxQuestions and Target are as in Config.
+	MaxQuestions int     `json:"max_questions"`
+	Target       float64 `json:"target"`
+
+	Seed        int64 `json:"seed"`
+	Repetitions int   `json:"repetitions"`
+}
+
+// Struct Stat summarizes a quantity over the repetitions of an Experiment.
+type Stat struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+}
+
+// Struct Summary aggregates the Results of an Experiment's repetitions.
+type Summary struct {
+	Experiment Experiment `json:"experiment"`
+
+	Questions Stat `json:"questions"`
+	Accuracy  Stat `json:"accuracy"`
+	Regret    Stat `json:"regret"`
+
+	// Converged counts the repetitions that reached the Target.
+	Converged int `json:"converged"`
+}
+
+// Function RunExperiment runs every repetition of the Experiment and returns
+// the mean and (sample) variance of the questions asked, the final accuracy,
+// and the cumulative regret.
+func RunExperiment(e Experiment) (Summary, error) {
+	if e.Repetitions <= 0 {
+		return Summary{}, fmt.Errorf("must have Repetitions [%d] > 0",
+			e.Repetitions)
+	}
+	if e.Users <= 0 || e.Choices < 2 || e.Rank <= 0 {
+		return Summary{}, fmt.Errorf("must have Users, Rank > 0 and Choices >= 2")
+	}
+	var questions, accuracy, regret []float64
+	summary := Summary{Experiment: e}
+	seeds := rand.New(rand.NewSource(e.Seed))
+	for r := 0; r < e.Repetitions; r++ {
+		truthSeed, oracleSeed, engineSeed :=
+			seeds.Int63(), seeds.Int63(), seeds.Int63()
+		truth := RandomTruth(e.Users, e.Choices, e.Rank,
+			rand.New(rand.NewSource(truthSeed)))
+		oracle, err := e.oracle(rand.New(rand.NewSource(oracleSeed)))
+		if err != nil {
+			return Summary{}, err
+		}
+		opts, err := e.options(rand.New(rand.NewSource(engineSeed)))
+		if err != nil {
+			return Summary{}, err
+		}
+		eng, err := collaborativepermute.NewEngineSafe(e.Users, e.Choices,
+			opts...)
+		if err != nil {
+			return Summary{}, err
+		}
+		result, err := Run(eng, truth, oracle, Config{
+			MaxQuestions: e.MaxQuestions,
+			Target:       e.Target,
+		})
+		if err != nil {
+			return Summary{}, err
+		}
+		questions = append(questions, float64(result.Questions))
+		accuracy = append(accuracy, result.Accuracy)
+		regret = append(regret, float64(result.Regret))
+		if result.Converged {
+			summary.Converged++
+		}
+	}
+	summary.Questions = stat(questions)
+	summary.Accuracy = stat(accuracy)
+	summary.Regret = stat(regret)
+	return summary, nil
+}
+
+func (e Experiment) oracle(rng *rand.Rand) (Oracle, error) {
+	switch e.Noise {
+	case "", "perfect":
+		return Perfect, nil
+	case "logistic":
+		if !(e.NoiseLevel > 0) {
+			return nil, fmt.Errorf("must have NoiseLevel [%v] > 0 for "+
+				"logistic noise", e.NoiseLevel)
+		}
+		return Logistic(e.NoiseLevel, rng), nil
+	case "error", "lazy":
+		if !(e.NoiseLevel >= 0 && e.NoiseLevel <= 1) {
+			return nil, fmt.Errorf("must have 0 <= NoiseLevel [%v] <= 1 "+
+				"for %s noise", e.NoiseLevel, e.Noise)
+		}
+		if e.Noise == "error" {
+			return ErrorRate(e.NoiseLevel, rng), nil
+		}
+		return Lazy(e.NoiseLevel, rng), nil
+	case "random":
+		return Random(rng), nil
+	}
+	return nil, fmt.Errorf("unknown noise %q", e.Noise)
+}
+
+func (e Experiment) options(rng *rand.Rand) ([]collaborativepermute.Option,
+	error) {
+	opts := []collaborativepermute.Option{collaborativepermute.WithRNG(rng)}
+	switch e.Strategy {
+	case "", "uncertainty":
+	case "uniform":
+		opts = append(opts,
+			collaborativepermute.WithStrategy(collaborativepermute.Uniform))
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", e.Strategy)
+	}
+	if e.Lambda > 0 {
+		opts = append(opts, collaborativepermute.WithLambda(e.Lambda))
+	}
+	return opts, nil
+}
+
+func stat(xs []float64) Stat {
+	mean := 0.0
+	for _, x := range xs {
+		mean += x / float64(len(xs))
+	}
+	if len(xs) < 2 {
+		return Stat{Mean: mean}
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return Stat{Mean: mean, Variance: sum / float64(len(xs)-1)}
+}
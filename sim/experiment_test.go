@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestRunExperiment(t *testing.T) {
+	e := Experiment{
+		Users: 5, Choices: 5, Rank: 1,
+		Noise: "error", NoiseLevel: 0.05,
+		MaxQuestions: 200, Target: 0.9,
+		Seed: 23, Repetitions: 3,
+	}
+	first, err := RunExperiment(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := RunExperiment(e)
+	if first != second {
+		t.Fatalf("expected a reproducible summary, got %+v and %+v",
+			first, second)
+	}
+	if first.Converged != 3 || first.Accuracy.Mean < 0.9 {
+		t.Fatalf("expected every repetition to converge, got %+v", first)
+	}
+	if first.Questions.Variance == 0 {
+		t.Fatalf("expected repetitions to differ, got %+v", first.Questions)
+	}
+
+	for _, noise := range []struct {
+		name  string
+		level float64
+	}{{"logistic", 0}, {"error", -0.1}, {"lazy", 1.5}} {
+		bad := e
+		bad.Noise, bad.NoiseLevel = noise.name, noise.level
+		if _, err := RunExperiment(bad); err == nil {
+			t.Fatalf("expected an error for %s noise of %v", noise.name,
+				noise.level)
+		}
+	}
+
+	e.Strategy = "clairvoyant"
+	if _, err := RunExperiment(e); err == nil {
+		t.Fatalf("expected an error for an unknown strategy")
+	}
+}
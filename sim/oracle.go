@@ -0,0 +1,170 @@
+package sim
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Type Oracle answers queries on behalf of simulated users.
+type Oracle interface {
+	// Answer returns the query with its Choices reordered from most to least
+	// preferred, as the query's user would answer it given the Truth.
+	Answer(q collaborativepermute.Query, truth Truth) collaborativepermute.Query
+}
+
+type perfect struct{}
+
+func (perfect) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	return order(q, truth[q.User])
+}
+
+// Perfect is an Oracle that always answers according to the Truth.
+var Perfect Oracle = perfect{}
+
+type logistic struct {
+	scale float64
+	rng   *rand.Rand
+}
+
+func (o logistic) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	// Sample a Plackett-Luce ranking by repeatedly picking the next choice
+	// with probability proportional to e^(utility/scale).
+	remaining := append([]int(nil), q.Choices...)
+	q.Choices = q.Choices[:0:0]
+	for len(remaining) > 0 {
+		weights := make([]float64, len(remaining))
+		max := math.Inf(-1)
+		for _, c := range remaining {
+			max = math.Max(max, truth[q.User][c]/o.scale)
+		}
+		sum := 0.0
+		for i, c := range remaining {
+			weights[i] = math.Exp(truth[q.User][c]/o.scale - max)
+			sum += weights[i]
+		}
+		pick, offset := len(remaining)-1, o.rng.Float64()*sum
+		for i, w := range weights {
+			if offset < w {
+				pick = i
+				break
+			}
+			offset -= w
+		}
+		q.Choices = append(q.Choices, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return q
+}
+
+// Logistic returns an Oracle with Bradley-Terry-Luce noise: a user prefers a
+// over b with probability σ((utility(a) - utility(b))/scale), and longer
+// rankings are sampled from the Plackett-Luce model. Close calls are
+// therefore answered almost at random, and clear ones almost never wrong. As
+// the scale shrinks to zero the noise vanishes, so a scale that is not
+// positive gives Perfect.
+func Logistic(scale float64, rng *rand.Rand) Oracle {
+	if !(scale > 0) {
+		return Perfect
+	}
+	return logistic{scale, rng}
+}
+
+type errorRate struct {
+	rate float64
+	rng  *rand.Rand
+}
+
+func (o errorRate) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	q = order(q, truth[q.User])
+	if o.rng.Float64() < o.rate {
+		for i, j := 0, len(q.Choices)-1; i < j; i, j = i+1, j-1 {
+			q.Choices[i], q.Choices[j] = q.Choices[j], q.Choices[i]
+		}
+	}
+	return q
+}
+
+// ErrorRate returns an Oracle that answers according to the Truth, except
+// that with the given probability it reverses its answer, regardless of how
+// close the call is.
+func ErrorRate(rate float64, rng *rand.Rand) Oracle {
+	return errorRate{rate, rng}
+}
+
+type lazy struct {
+	rate float64
+	rng  *rand.Rand
+}
+
+func (o lazy) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	if o.rng.Float64() < o.rate {
+		q.Choices = append([]int(nil), q.Choices...)
+		return q
+	}
+	return order(q, truth[q.User])
+}
+
+// Lazy returns an Oracle that, with the given probability, accepts the
+// choices in the order they were displayed rather than answering. Since
+// Generate lists the choices in the order the learner already believes, lazy
+// answers confirm the learner's mistakes.
+func Lazy(rate float64, rng *rand.Rand) Oracle {
+	return lazy{rate, rng}
+}
+
+type random struct {
+	rng *rand.Rand
+}
+
+func (o random) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	choices := make([]int, len(q.Choices))
+	for i, j := range o.rng.Perm(len(choices)) {
+		choices[i] = q.Choices[j]
+	}
+	q.Choices = choices
+	return q
+}
+
+// Random returns an Oracle that ignores the Truth and answers uniformly at
+// random, like a respondent clicking through.
+func Random(rng *rand.Rand) Oracle {
+	return random{rng}
+}
+
+type perUser struct {
+	fallback  Oracle
+	overrides map[int]Oracle
+}
+
+func (o perUser) Answer(q collaborativepermute.Query,
+	truth Truth) collaborativepermute.Query {
+	if oracle, ok := o.overrides[q.User]; ok {
+		return oracle.Answer(q, truth)
+	}
+	return o.fallback.Answer(q, truth)
+}
+
+// PerUser returns an Oracle that answers for the users in overrides with
+// their own Oracles, and for everyone else with fallback, so that a
+// population can mix careful, noisy, and random respondents.
+func PerUser(fallback Oracle, overrides map[int]Oracle) Oracle {
+	return perUser{fallback, overrides}
+}
+
+// order returns the query with a copy of its Choices sorted by descending
+// utility.
+func order(q collaborativepermute.Query,
+	utility []float64) collaborativepermute.Query {
+	q.Choices = append([]int(nil), q.Choices...)
+	sort.SliceStable(q.Choices, func(i, j int) bool {
+		return utility[q.Choices[i]] > utility[q.Choices[j]]
+	})
+	return q
+}
@@ -0,0 +1,74 @@
+package sim
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"testing"
+)
+
+func TestOracles(t *testing.T) {
+	rng := rand.New(rand.NewSource(23))
+	truth := Truth{{0, 1, 2, 10}}
+	near := collaborativepermute.Query{Choices: []int{0, 1}}
+	far := collaborativepermute.Query{Choices: []int{0, 3}}
+
+	agreement := func(o Oracle, q collaborativepermute.Query) float64 {
+		agree := 0
+		for i := 0; i < 1000; i++ {
+			want := Perfect.Answer(q, truth).Choices[0]
+			if o.Answer(q, truth).Choices[0] == want {
+				agree++
+			}
+		}
+		return float64(agree) / 1000
+	}
+
+	if a := agreement(Perfect, near); a != 1 {
+		t.Fatalf("expected the perfect oracle to always agree, got %v", a)
+	}
+	logistic := Logistic(1, rng)
+	c, d := agreement(logistic, near), agreement(logistic, far)
+	if !(c < 0.8 && d > 0.99) {
+		t.Fatalf("expected noise to depend on the gap, got %v and %v", c, d)
+	}
+	for _, scale := range []float64{0, -1} {
+		if a := agreement(Logistic(scale, rng), near); a != 1 {
+			t.Fatalf("expected a scale of %v to always agree, got %v", scale, a)
+		}
+	}
+	if a := agreement(ErrorRate(0.2, rng), far); a < 0.75 || a > 0.85 {
+		t.Fatalf("expected to agree 80%% of the time, got %v", a)
+	}
+	if a := agreement(Lazy(1, rng), near); a != 0 {
+		t.Fatalf("expected a lazy oracle to keep the displayed order, got %v", a)
+	}
+	if a := agreement(Random(rng), far); a < 0.45 || a > 0.55 {
+		t.Fatalf("expected a random oracle to agree half the time, got %v", a)
+	}
+
+	mixed := PerUser(Perfect, map[int]Oracle{0: Lazy(1, rng)})
+	if a := agreement(mixed, near); a != 0 {
+		t.Fatalf("expected user 0 to be answered lazily, got %v", a)
+	}
+
+	ranking := Logistic(1, rng).Answer(
+		collaborativepermute.Query{Choices: []int{0, 1, 2, 3}}, truth)
+	if len(ranking.Choices) != 4 || ranking.Choices[0] != 3 {
+		t.Fatalf("expected a full ranking led by 3, got %v", ranking.Choices)
+	}
+}
+
+func TestRunWithNoise(t *testing.T) {
+	rand.Seed(23)
+	rng := rand.New(rand.NewSource(23))
+	truth := RandomTruth(6, 5, 1, rng)
+	eng := collaborativepermute.NewEngine(6, 5)
+
+	result, err := Run(eng, truth, ErrorRate(0.1, rng), Config{MaxQuestions: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Accuracy < 0.8 {
+		t.Fatalf("expected to learn despite noise, got %+v", result)
+	}
+}
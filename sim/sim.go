@@ -0,0 +1,181 @@
+// Package sim runs collaborativepermute learners against synthetic users
+// whose preferences are known, so that strategies and hyperparameters can be
+// compared by how many questions they need.
+//
+// A simulation has three parts: a Truth matrix of each user's utility for
+// each choice, an Oracle that answers generated queries on the users' behalf,
+// and Run, which alternates between the two until the learner has recovered
+// the Truth.
+package sim
+
+import (
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"sort"
+)
+
+// Type Truth holds each user's (row's) ground-truth utility for each choice
+// (column). Higher utilities are preferred.
+type Truth [][]float64
+
+// Function RandomTruth returns a users × choices Truth of the given rank, the
+// product of two matrices with standard normal entries, so that users share
+// preferences the way the engine assumes.
+func RandomTruth(users, choices, rank int, rng *rand.Rand) Truth {
+	u := normals(users, rank, rng)
+	v := normals(choices, rank, rng)
+	truth := make(Truth, users)
+	for i := range truth {
+		truth[i] = make([]float64, choices)
+		for j := range truth[i] {
+			for k := 0; k < rank; k++ {
+				truth[i][j] += u[i][k] * v[j][k]
+			}
+		}
+	}
+	return truth
+}
+
+// Method Rank returns the choices ordered from most to least preferred by the
+// given user.
+func (t Truth) Rank(user int) []int {
+	order := make([]int, len(t[user]))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return t[user][order[i]] > t[user][order[j]]
+	})
+	return order
+}
+
+// Function Accuracy returns the fraction of pairs of choices, over all users,
+// that the learner's rankings order the same way as the Truth. Pairs the
+// Truth considers equal are skipped.
+func Accuracy(l collaborativepermute.Learner, truth Truth) (float64, error) {
+	wrong, total, err := mistakes(l, truth)
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 1, nil
+	}
+	return float64(total-wrong) / float64(total), nil
+}
+
+// mistakes returns the number of pairs of choices, over all users, that the
+// learner's rankings order differently from the Truth, and the number of
+// pairs the Truth orders at all.
+func mistakes(l collaborativepermute.Learner,
+	truth Truth) (wrong, total int, err error) {
+	for u := range truth {
+		ranking, err := l.Rank(u)
+		if err != nil {
+			return 0, 0, err
+		}
+		for i, a := range ranking {
+			for _, b := range ranking[i+1:] {
+				if truth[u][a] == truth[u][b] {
+					continue
+				}
+				if truth[u][a] < truth[u][b] {
+					wrong++
+				}
+				total++
+			}
+		}
+	}
+	return wrong, total, nil
+}
+
+// Struct Config controls a simulation run.
+type Config struct {
+	// MaxQuestions bounds the number of questions asked.
+	MaxQuestions int
+
+	// Target is the Accuracy at which the learner is considered to have
+	// converged; zero means 1, that is, every ranking is exactly right.
+	Target float64
+
+	// If Restrict is set, every question is asked of User; by default, the
+	// learner picks whom to ask.
+	Restrict bool
+	User     int
+}
+
+// Struct Result reports the outcome of a simulation run.
+type Result struct {
+	// Questions is the number of questions asked, which is the number needed
+	// to converge if Converged is set.
+	Questions int
+	Converged bool
+
+	// Accuracy is the learner's Accuracy at the end of the run.
+	Accuracy float64
+
+	// Mistakes[i] is the number of pairs the learner ordered differently
+	// from the Truth after i+1 questions, and Regret is their sum, the
+	// cumulative regret of the run.
+	Mistakes []int
+	Regret   int
+}
+
+// Function Run asks the learner questions, has the oracle answer them, and
+// feeds the answers back, until the learner reaches the target accuracy or
+// runs out of questions.
+func Run(l collaborativepermute.Learner, truth Truth, oracle Oracle,
+	cfg Config) (Result, error) {
+	if cfg.MaxQuestions <= 0 {
+		return Result{}, fmt.Errorf("must have MaxQuestions [%d] > 0",
+			cfg.MaxQuestions)
+	}
+	target := cfg.Target
+	if target == 0 {
+		target = 1
+	}
+
+	var result Result
+	for {
+		wrong, total, err := mistakes(l, truth)
+		if err != nil {
+			return result, err
+		}
+		if result.Questions > 0 {
+			result.Mistakes = append(result.Mistakes, wrong)
+			result.Regret += wrong
+		}
+		result.Accuracy = 1
+		if total > 0 {
+			result.Accuracy = float64(total-wrong) / float64(total)
+		}
+		if result.Accuracy >= target {
+			result.Converged = true
+			return result, nil
+		}
+		if result.Questions == cfg.MaxQuestions {
+			return result, nil
+		}
+
+		user := -1
+		if cfg.Restrict {
+			user = cfg.User
+		}
+		q := l.Generate(user)
+		if err := l.Respond(oracle.Answer(q, truth)); err != nil {
+			return result, err
+		}
+		result.Questions++
+	}
+}
+
+func normals(rows, cols int, rng *rand.Rand) [][]float64 {
+	result := make([][]float64, rows)
+	for i := range result {
+		result[i] = make([]float64, cols)
+		for j := range result[i] {
+			result[i][j] = rng.NormFloat64()
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package sim
+
+import (
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	rand.Seed(23)
+	truth := RandomTruth(6, 5, 1, rand.New(rand.NewSource(23)))
+	eng := collaborativepermute.NewEngine(6, 5)
+
+	result, err := Run(eng, truth, Perfect, Config{MaxQuestions: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Converged || result.Accuracy != 1 {
+		t.Fatalf("expected to converge, got %+v", result)
+	}
+	if result.Questions == 0 || result.Questions != len(eng.History) {
+		t.Fatalf("expected to count questions, got %+v", result)
+	}
+	if len(result.Mistakes) != result.Questions ||
+		result.Mistakes[result.Questions-1] != 0 {
+		t.Fatalf("expected a mistake count per question, got %v",
+			result.Mistakes)
+	}
+	regret := 0
+	for _, m := range result.Mistakes {
+		regret += m
+	}
+	if regret != result.Regret || regret == 0 {
+		t.Fatalf("expected regret %d, got %d", regret, result.Regret)
+	}
+
+	for u := range truth {
+		want := truth.Rank(u)
+		got, _ := eng.Rank(u)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("user %d: expected %v, got %v", u, want, got)
+			}
+		}
+	}
+
+	if _, err := Run(eng, truth, Perfect, Config{}); err == nil {
+		t.Fatalf("expected an error without a question budget")
+	}
+}
@@ -0,0 +1,140 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct Consistency summarizes how consistent a user's responses are, for
+// spotting careless or adversarial respondents.
+type Consistency struct {
+	// CycleRate is the fraction of the triples of choices the user has
+	// compared pairwise whose preferences form a cycle (a > b > c > a).
+	// Honest users are rarely intransitive; random answers are cyclic a
+	// quarter of the time.
+	CycleRate float64
+
+	// Disagreement is the fraction of the user's pairs on which the majority
+	// of other users preferred the other choice, among the pairs that other
+	// users have answered.
+	Disagreement float64
+}
+
+// Method Consistency measures how consistent the user's responses are with
+// themselves and with the other users, from the History.
+func (p *Engine) Consistency(user int) (Consistency, error) {
+	if user < 0 || user >= p.X.Shape[0] {
+		return Consistency{}, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	mine := make(map[[2]int]int)
+	others := make(map[[2]int]int)
+	for _, q := range p.History {
+		votes := others
+		if q.User == user {
+			votes = mine
+		}
+		for _, pair := range p.preferences(q) {
+			votes[pair]++
+			votes[[2]int{pair[1], pair[0]}]--
+		}
+	}
+	return Consistency{
+		CycleRate:    cycleRate(mine, p.X.Shape[1]),
+		Disagreement: disagreement(mine, others),
+	}, nil
+}
+
+// Method DetectSpam returns the users whose CycleRate exceeds maxCycleRate or
+// whose Disagreement exceeds maxDisagreement. Flagged users can be excluded
+// from training with Quarantine.
+func (p *Engine) DetectSpam(maxCycleRate, maxDisagreement float64) []int {
+	var flagged []int
+	for u := 0; u < p.X.Shape[0]; u++ {
+		c, _ := p.Consistency(u)
+		if c.CycleRate > maxCycleRate || c.Disagreement > maxDisagreement {
+			flagged = append(flagged, u)
+		}
+	}
+	return flagged
+}
+
+// Method Quarantine excludes the user's responses from training and refits
+// the engine without them. The responses stay in History, so that Release
+// can restore them, and the user still receives scores from the other users'
+// responses.
+func (p *Engine) Quarantine(user int) error {
+	return p.setQuarantined(user, true)
+}
+
+// Method Release undoes Quarantine, refitting the engine with the user's
+// responses.
+func (p *Engine) Release(user int) error {
+	return p.setQuarantined(user, false)
+}
+
+func (p *Engine) setQuarantined(user int, quarantined bool) error {
+	if user < 0 || user >= p.X.Shape[0] {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, p.X.Shape[0])
+	}
+	if p.Quarantined == nil {
+		p.Quarantined = make([]bool, p.X.Shape[0])
+	}
+	if p.Quarantined[user] != quarantined {
+		p.Quarantined[user] = quarantined
+		p.Refit()
+	}
+	return nil
+}
+
+// cycleRate returns the fraction of fully compared triples of choices whose
+// net pairwise votes form a cycle.
+func cycleRate(votes map[[2]int]int, n int) float64 {
+	triples, cycles := 0, 0
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			ab := votes[[2]int{a, b}]
+			if ab == 0 {
+				continue
+			}
+			for c := b + 1; c < n; c++ {
+				bc, ca := votes[[2]int{b, c}], votes[[2]int{c, a}]
+				if bc == 0 || ca == 0 {
+					continue
+				}
+				triples++
+				if (ab > 0) == (bc > 0) && (bc > 0) == (ca > 0) {
+					cycles++
+				}
+			}
+		}
+	}
+	if triples == 0 {
+		return 0
+	}
+	return float64(cycles) / float64(triples)
+}
+
+// disagreement returns the fraction of the pairs with net votes in mine
+// whose net votes in others point the opposite way, among those that others
+// have answered.
+func disagreement(mine, others map[[2]int]int) float64 {
+	compared, opposed := 0, 0
+	for pair, vote := range mine {
+		if vote <= 0 {
+			continue
+		}
+		other, ok := others[pair]
+		if !ok {
+			continue
+		}
+		compared++
+		if other < 0 {
+			opposed++
+		}
+	}
+	if compared == 0 {
+		return 0
+	}
+	return float64(opposed) / float64(compared)
+}
@@ -0,0 +1,50 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDetectSpam(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(4, 6)
+	for i := 0; i < 120; i++ {
+		user := i % 4
+		a, b := rand.Intn(6), rand.Intn(5)
+		if b >= a {
+			a, b = b+1, a
+		}
+		// Users 0-2 consistently prefer lower choices; user 3 answers at
+		// random.
+		if user == 3 && rand.Intn(2) == 0 {
+			a, b = b, a
+		}
+		eng.Respond(Query{User: user, Choices: []int{b, a}})
+	}
+
+	honest, _ := eng.Consistency(0)
+	spammer, _ := eng.Consistency(3)
+	if honest.CycleRate != 0 || honest.Disagreement != 0 {
+		t.Fatalf("expected user 0 to be consistent, got %+v", honest)
+	}
+	if spammer.Disagreement < 0.2 {
+		t.Fatalf("expected user 3 to disagree, got %+v", spammer)
+	}
+	if flagged := eng.DetectSpam(0.1, 0.2); len(flagged) != 1 || flagged[0] != 3 {
+		t.Fatalf("expected only user 3 to be flagged, got %v", flagged)
+	}
+
+	if err := eng.Quarantine(3); err != nil {
+		t.Fatal(err)
+	}
+	ranking, _ := eng.Rank(3)
+	if ranking[0] != 0 {
+		t.Fatalf("expected user 3 to follow the others, got %v", ranking)
+	}
+	if err := eng.Release(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.Quarantine(4); err == nil {
+		t.Fatalf("expected an error for an unknown user")
+	}
+}
@@ -0,0 +1,86 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct Fold is one train/test split of a History.
+type Fold struct {
+	Train, Test []Query
+}
+
+// Function KFold splits the history into k folds, stratified by the given
+// key: the responses sharing a key are dealt round-robin across the folds, so
+// every fold's Test holds about 1/k of them. Stratifying ByUser ensures that
+// every user with at least k responses is represented in every fold, rather
+// than some users being tested on responses they have never been trained on.
+// Responses keep their relative order within Train and Test.
+func KFold[K comparable](history []Query, k int,
+	key func(Query) K) ([]Fold, error) {
+	if k < 2 || k > len(history) {
+		return nil, fmt.Errorf("must have 2 <= k [%d] <= %d", k, len(history))
+	}
+	start := make(map[K]int)
+	count := make(map[K]int)
+	assignments := make([]int, len(history))
+	for i, q := range history {
+		key := key(q)
+		if _, ok := start[key]; !ok {
+			start[key] = len(start) % k
+		}
+		assignments[i] = (start[key] + count[key]) % k
+		count[key]++
+	}
+	return folds(history, k, func(i int) int { return assignments[i] }), nil
+}
+
+// Function LeaveOneUserOut returns one Fold for each user with any responses,
+// in order of user, whose Test holds all of that user's responses. It
+// measures how well the engine serves users it knows nothing about.
+func LeaveOneUserOut(history []Query) []Fold {
+	users := 0
+	for _, q := range history {
+		if q.User >= users {
+			users = q.User + 1
+		}
+	}
+	all := folds(history, users, func(i int) int { return history[i].User })
+	result := make([]Fold, 0, len(all))
+	for _, fold := range all {
+		if len(fold.Test) > 0 {
+			result = append(result, fold)
+		}
+	}
+	return result
+}
+
+// Function ByUser stratifies KFold by user.
+func ByUser(q Query) int {
+	return q.User
+}
+
+// Function ByUserAndPair stratifies KFold by user and by the (unordered) pair
+// of choices compared, so that every fold also tests a similar mix of
+// choices.
+func ByUserAndPair(q Query) [3]int {
+	a, b := q.Choices[0], q.Choices[1]
+	if a > b {
+		a, b = b, a
+	}
+	return [3]int{q.User, a, b}
+}
+
+// folds returns k folds in which response i is tested in fold assign(i).
+func folds(history []Query, k int, assign func(i int) int) []Fold {
+	result := make([]Fold, k)
+	for i, q := range history {
+		for f := range result {
+			if f == assign(i) {
+				result[f].Test = append(result[f].Test, q)
+			} else {
+				result[f].Train = append(result[f].Train, q)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package collaborativepermute
+
+import (
+	"testing"
+)
+
+func TestKFold(t *testing.T) {
+	var history []Query
+	for i := 0; i < 30; i++ {
+		// User 2 answers far more often than the others.
+		user := i % 5
+		if user > 2 {
+			user = 2
+		}
+		history = append(history, Query{User: user, Choices: []int{i % 4, 4}})
+	}
+
+	folds, err := KFold(history, 3, ByUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, fold := range folds {
+		if len(fold.Train)+len(fold.Test) != 30 {
+			t.Fatalf("fold %d: expected every response, got %d",
+				f, len(fold.Train)+len(fold.Test))
+		}
+		perUser := make([]int, 3)
+		for _, q := range fold.Test {
+			perUser[q.User]++
+		}
+		if perUser[0] != 2 || perUser[1] != 2 || perUser[2] != 6 {
+			t.Fatalf("fold %d: expected stratified users, got %v", f, perUser)
+		}
+	}
+
+	if _, err := KFold(history, 3, ByUserAndPair); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := KFold(history[:2], 3, ByUser); err == nil {
+		t.Fatalf("expected an error for too few responses")
+	}
+}
+
+func TestLeaveOneUserOut(t *testing.T) {
+	history := []Query{
+		{User: 0, Choices: []int{0, 1}},
+		{User: 2, Choices: []int{1, 0}},
+		{User: 0, Choices: []int{1, 2}},
+	}
+	folds := LeaveOneUserOut(history)
+	if len(folds) != 2 || len(folds[0].Test) != 2 || len(folds[1].Train) != 2 {
+		t.Fatalf("expected folds for users 0 and 2, got %+v", folds)
+	}
+	for _, q := range folds[1].Train {
+		if q.User == 2 {
+			t.Fatalf("expected user 2 to be left out of training")
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package collaborativepermute
+
+import (
+	"github.com/fatlotus/gauss"
+	"time"
+)
+
+// Struct Stats summarizes the state of an engine for dashboards.
+type Stats struct {
+	// Responses is the number of responses in History, and PerUser counts
+	// them by user.
+	Responses int
+	PerUser   []int
+
+	// Rank is the effective rank of X, that is, the number of its singular
+	// values that are not negligible. It is roughly the number of independent
+	// tastes the engine has found among its users.
+	Rank int
+
+	// LastUpdate is how long the most recent update took.
+	LastUpdate time.Duration
+
+	// Loss is the mean loss over History.
+	Loss float64
+}
+
+// Method Stats returns a summary of the engine's state. Computing the rank
+// and loss costs about as much as an update.
+func (p *Engine) Stats() Stats {
+	s := Stats{
+		Responses:  len(p.History),
+		PerUser:    make([]int, p.X.Shape[0]),
+		Rank:       effectiveRank(p.backend(), p.X),
+		LastUpdate: p.lastUpdate,
+	}
+	for _, q := range p.History {
+		s.PerUser[q.User]++
+	}
+	if len(p.History) > 0 {
+		s.Loss = p.loss(p.History)
+	}
+	return s
+}
+
+// effectiveRank counts the singular values of x above a tolerance relative to
+// the largest.
+func effectiveRank(b Backend, x gauss.Array) int {
+	if len(x.Data) == 0 {
+		return 0
+	}
+	_, S, _ := b.SVD(x)
+	largest := 0.0
+	for _, s := range S.Data {
+		if s > largest {
+			largest = s
+		}
+	}
+	rank := 0
+	for _, s := range S.Data {
+		if s > 1e-9*largest && s > 1e-12 {
+			rank++
+		}
+	}
+	return rank
+}
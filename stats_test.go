@@ -0,0 +1,30 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(3, 3)
+	if s := eng.Stats(); s.Responses != 0 || s.Rank != 0 || s.Loss != 0 {
+		t.Fatalf("expected empty stats, got %+v", s)
+	}
+
+	for i := 0; i < 5; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+		eng.Respond(Query{User: 2, Choices: []int{0, 1}})
+	}
+	s := eng.Stats()
+	if s.Responses != 10 || s.PerUser[0] != 5 || s.PerUser[1] != 0 ||
+		s.PerUser[2] != 5 {
+		t.Fatalf("unexpected counts %+v", s)
+	}
+	if s.Rank != 1 {
+		t.Fatalf("expected shared preferences to have rank 1, got %d", s.Rank)
+	}
+	if s.LastUpdate <= 0 || s.Loss < 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,99 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"github.com/fatlotus/gauss"
+)
+
+// Type StepSize chooses the step size of each update.
+type StepSize interface {
+	// Step returns the step size for the t'th update since the engine was
+	// created or last refit, counting from 1, given the base step size Nu.
+	// Line searches may call loss to evaluate the loss that would result from
+	// a step of a given size, and compare it against the current loss.
+	Step(nu float64, t int, current float64,
+		loss func(step float64) float64) float64
+}
+
+type constantStep struct{}
+
+func (constantStep) Step(nu float64, t int, current float64,
+	loss func(float64) float64) float64 {
+	return nu
+}
+
+type decayingStep struct{}
+
+func (decayingStep) Step(nu float64, t int, current float64,
+	loss func(float64) float64) float64 {
+	return nu / float64(t)
+}
+
+// Struct Backtracking is a line search that starts from Nu and multiplies the
+// step size by Shrink until the loss no longer increases, giving up after
+// Tries attempts.
+type Backtracking struct {
+	Shrink float64
+	Tries  int
+}
+
+// Method Step returns the first step size that does not increase the loss.
+func (b Backtracking) Step(nu float64, t int, current float64,
+	loss func(float64) float64) float64 {
+	step := nu
+	for i := 1; i < b.Tries && loss(step) > current; i++ {
+		step *= b.Shrink
+	}
+	return step
+}
+
+var (
+	// ConstantStep always takes steps of size Nu. This is the default.
+	ConstantStep StepSize = constantStep{}
+
+	// DecayingStep takes steps of size Nu/t on the t'th update, which damps
+	// the effect of noisy responses as data accumulates.
+	DecayingStep StepSize = decayingStep{}
+)
+
+// WithStepSize sets how the step size of each update is chosen.
+func WithStepSize(s StepSize) Option {
+	return func(p *Engine) error {
+		if b, ok := s.(Backtracking); ok {
+			if !(b.Shrink > 0 && b.Shrink < 1) || b.Tries < 1 {
+				return fmt.Errorf("must have 0 < Shrink [%v] < 1 and "+
+					"Tries [%d] >= 1", b.Shrink, b.Tries)
+			}
+		}
+		p.StepSize = s
+		return nil
+	}
+}
+
+// stepSize returns the step size to use for the current update.
+func (p *Engine) stepSize(samps []Query, gradient gauss.Array,
+	lambda float64) float64 {
+	if p.StepSize == nil {
+		return p.Nu
+	}
+	loss := func(step float64) float64 {
+		x := p.X
+		p.X = p.proximalStep(gradient, step, lambda)
+		defer func() { p.X = x }()
+		return p.loss(samps)
+	}
+	return p.StepSize.Step(p.Nu, p.updates, p.loss(samps), loss)
+}
+
+// proximalStep returns the result of a gradient step of the given size from
+// Z, followed by the regularizer's proximal operator with strength lambda and,
+// if the engine is NonNegative, projection onto the non-negative orthant.
+func (p *Engine) proximalStep(gradient gauss.Array,
+	step, lambda float64) gauss.Array {
+	y := gauss.Sum(p.Z, clone(gradient).Scale(-step))
+	x := p.regularizer().Prox(y, lambda)
+	if p.NonNegative {
+		project(x)
+	}
+	return x
+}
@@ -0,0 +1,43 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStepSizes(t *testing.T) {
+	for _, s := range []StepSize{
+		ConstantStep, DecayingStep, Backtracking{Shrink: 0.5, Tries: 5},
+	} {
+		rand.Seed(23)
+		eng := NewEngine(3, 4, WithStepSize(s))
+		for i := 0; i < 20; i++ {
+			eng.Respond(Query{User: i % 3, Choices: []int{3, 1}})
+		}
+		for u := 0; u < 3; u++ {
+			if eng.Score(u, 3) <= eng.Score(u, 1) {
+				t.Errorf("%T: user %d did not learn the preference", s, u)
+			}
+		}
+	}
+}
+
+func TestBacktrackingShrinks(t *testing.T) {
+	calls := 0
+	step := Backtracking{Shrink: 0.5, Tries: 4}.Step(1, 1, 0.75,
+		func(step float64) float64 {
+			calls++
+			return step
+		})
+	if step != 0.5 || calls != 2 {
+		t.Fatalf("expected to settle on 0.5 after two tries, got %v after %d",
+			step, calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected an invalid line search to be rejected")
+		}
+	}()
+	NewEngine(1, 2, WithStepSize(Backtracking{Shrink: 2, Tries: 3}))
+}
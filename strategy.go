@@ -0,0 +1,56 @@
+package collaborativepermute
+
+import (
+	"math"
+)
+
+// Type Strategy decides which queries Generate prefers to ask.
+type Strategy interface {
+	// Weight returns a non-negative number proportional to the probability
+	// that the user is asked to compare choices a and b.
+	Weight(p *Engine, user, a, b int) float64
+}
+
+// Type StrategyFunc adapts an ordinary function to the Strategy interface.
+type StrategyFunc func(p *Engine, user, a, b int) float64
+
+// Method Weight calls f(p, user, a, b).
+func (f StrategyFunc) Weight(p *Engine, user, a, b int) float64 {
+	return f(p, user, a, b)
+}
+
+var (
+	// Uncertainty prefers pairs whose scores are close together, which are
+	// the pairs the engine is least sure about. The engine's temperature T
+	// controls how sharply it focuses on them. This is the default.
+	Uncertainty Strategy = named("uncertainty", func(p *Engine, user, a, b int) float64 {
+		diff := math.Abs(p.Score(user, a) - p.Score(user, b))
+		return math.Exp(-diff / p.T)
+	})
+
+	// Uniform asks about every pair equally often, as a passive baseline.
+	Uniform Strategy = named("uniform", func(p *Engine, user, a, b int) float64 {
+		return 1
+	})
+)
+
+// namedStrategy is a StrategyFunc with a name, for audit records.
+type namedStrategy struct {
+	StrategyFunc
+	name string
+}
+
+func named(name string, f StrategyFunc) Strategy {
+	return namedStrategy{f, name}
+}
+
+func (s namedStrategy) String() string {
+	return s.name
+}
+
+func (p *Engine) strategy() Strategy {
+	if p.Strategy == nil {
+		return Uncertainty
+	}
+	return p.Strategy
+}
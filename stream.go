@@ -0,0 +1,57 @@
+package collaborativepermute
+
+import (
+	"context"
+	"log/slog"
+)
+
+// streamBuffer is the capacity of the channels returned by Stream.
+const streamBuffer = 16
+
+// Method Stream hands the engine to a new goroutine, which sends questions
+// for any user on the first channel and learns from the answers sent on the
+// second. Both channels hold up to streamBuffer values, so a slow consumer of
+// questions pauses question generation, and a slow engine blocks senders of
+// answers once the buffer is full. Questions are generated ahead of time, and
+// so may not reflect the most recent answers.
+//
+// The goroutine stops, closing the questions channel, when ctx is done, or
+// once it has learned from every answer sent before the answers channel was
+// closed; until then, the engine must not otherwise be used. If there is no
+// question to ask, as when there are fewer than two choices, none are sent.
+// Invalid answers are skipped, and reported to the Logger if one is set.
+func (p *Engine) Stream(ctx context.Context) (<-chan Query, chan<- Query) {
+	questions := make(chan Query, streamBuffer)
+	answers := make(chan Query, streamBuffer)
+	go p.stream(ctx, questions, answers)
+	return questions, answers
+}
+
+func (p *Engine) stream(ctx context.Context, questions chan<- Query,
+	answers <-chan Query) {
+	defer close(questions)
+	out := questions
+	next, err := p.GenerateSafe(-1)
+	if err != nil {
+		out = nil
+	}
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case out <- next:
+			if next, err = p.GenerateSafe(-1); err != nil {
+				out = nil
+			}
+		case answer, ok := <-answers:
+			if !ok {
+				return
+			}
+			if err := p.RespondContext(ctx, answer); err != nil &&
+				p.Logger != nil {
+				p.Logger.Log(ctx, slog.LevelWarn, "invalid answer",
+					"user", answer.User, "choices", answer.Choices,
+					"error", err)
+			}
+		}
+	}
+}
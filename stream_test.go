@@ -0,0 +1,42 @@
+package collaborativepermute
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 4)
+	questions, answers := eng.Stream(context.Background())
+
+	for i := 0; i < 40; i++ {
+		q := <-questions
+		if q.Choices[0] > q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+		}
+		answers <- q
+	}
+	answers <- Query{User: 5, Choices: []int{0, 1}}
+	close(answers)
+	for range questions {
+	}
+
+	if len(eng.History) != 40 {
+		t.Fatalf("expected 40 valid answers to be learned, got %d",
+			len(eng.History))
+	}
+	if rank, _ := eng.Rank(1); rank[0] != 0 {
+		t.Fatalf("expected choice 0 to rank first, got %v", rank)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	questions, _ := NewEngine(1, 1).Stream(ctx)
+	cancel()
+	if _, ok := <-questions; ok {
+		t.Fatalf("expected no questions from a single choice")
+	}
+}
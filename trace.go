@@ -0,0 +1,42 @@
+package collaborativepermute
+
+import (
+	"context"
+	"fmt"
+)
+
+// Type Tracer starts spans around the phases of RespondContext and
+// GenerateContext, for distributed tracing. The tracing subpackage adapts
+// OpenTelemetry to this interface.
+type Tracer interface {
+	// Start begins a span with the given name as a child of ctx, returning
+	// the context of the new span and a function that ends it.
+	Start(ctx context.Context, name string) (context.Context, func())
+}
+
+// WithTracer traces RespondContext and GenerateContext with t. The update
+// within Respond is split into the gradient computation and the SVD, and
+// Generate traces its weighing of the candidate queries.
+func WithTracer(t Tracer) Option {
+	return func(p *Engine) error {
+		if t == nil {
+			return fmt.Errorf("must have a non-nil tracer")
+		}
+		p.Tracer = t
+		return nil
+	}
+}
+
+// startSpan begins a span named name if the engine has a Tracer.
+func (p *Engine) startSpan(ctx context.Context, name string) (context.Context, func()) {
+	if p.Tracer == nil {
+		return ctx, func() {}
+	}
+	return p.Tracer.Start(ctx, name)
+}
+
+// span begins a span with no children of its own.
+func (p *Engine) span(ctx context.Context, name string) func() {
+	_, end := p.startSpan(ctx, name)
+	return end
+}
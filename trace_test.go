@@ -0,0 +1,45 @@
+package collaborativepermute
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+type spanKey struct{}
+
+// spanRecorder records the path of each span that ends.
+type spanRecorder []string
+
+func (r *spanRecorder) Start(ctx context.Context, name string) (context.Context, func()) {
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		name = parent + "/" + name
+	}
+	return context.WithValue(ctx, spanKey{}, name), func() {
+		*r = append(*r, name)
+	}
+}
+
+func TestWithTracer(t *testing.T) {
+	rand.Seed(23)
+	var spans spanRecorder
+	eng := NewEngine(2, 3, WithTracer(&spans))
+	ctx := context.WithValue(context.Background(), spanKey{}, "request")
+	q := eng.GenerateContext(ctx, 0)
+	eng.RespondContext(ctx, q)
+	eng.Refit()
+
+	expected := []string{
+		"request/collaborativepermute.Generate/collaborativepermute.candidates",
+		"request/collaborativepermute.Generate",
+		"request/collaborativepermute.Respond/collaborativepermute.gradient",
+		"request/collaborativepermute.Respond/collaborativepermute.svd",
+		"request/collaborativepermute.Respond",
+		"collaborativepermute.gradient",
+		"collaborativepermute.svd",
+	}
+	if strings.Join(spans, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected spans:\n%s", strings.Join(spans, "\n"))
+	}
+}
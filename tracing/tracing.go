@@ -0,0 +1,33 @@
+// Package tracing adapts OpenTelemetry to the collaborativepermute Tracer
+// interface, so that slow responses show up in distributed traces.
+//
+//	eng := collaborativepermute.NewEngine(users, choices,
+//		collaborativepermute.WithTracer(tracing.New(otel.Tracer("permute"))))
+//	err := eng.RespondContext(ctx, q)
+package tracing
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Struct Tracer is a collaborativepermute.Tracer that starts OpenTelemetry
+// spans.
+type Tracer struct {
+	Tracer trace.Tracer
+}
+
+var _ collaborativepermute.Tracer = Tracer{}
+
+// New creates a Tracer that starts spans with t.
+func New(t trace.Tracer) Tracer {
+	return Tracer{Tracer: t}
+}
+
+// Method Start begins an OpenTelemetry span with the given name as a child of
+// ctx.
+func (t Tracer) Start(ctx context.Context, name string) (context.Context, func()) {
+	ctx, span := t.Tracer.Start(ctx, name)
+	return ctx, func() { span.End() }
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"github.com/fatlotus/collaborativepermute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"math/rand"
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	rand.Seed(23)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	eng := collaborativepermute.NewEngine(2, 3,
+		collaborativepermute.WithTracer(New(provider.Tracer("test"))))
+	eng.RespondContext(context.Background(),
+		collaborativepermute.Query{User: 0, Choices: []int{0, 1}})
+
+	spans := recorder.Ended()
+	if len(spans) != 3 || spans[2].Name() != "collaborativepermute.Respond" {
+		t.Fatalf("unexpected spans %v", spans)
+	}
+}
@@ -0,0 +1,42 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Method Train replaces the History with responses and fits the engine to
+// them offline: it replays them from scratch, as Refit does, and then takes
+// passes further update steps, each over every response. The online replay
+// alone leaves early responses underfit; the extra passes bring the engine
+// closer to the best fit of the whole History, as for nightly retraining on
+// a log. Momentum is restarted before the passes, so the loss trends down
+// but need not fall on every pass. Train returns the mean loss after the
+// replay and after each pass. If any response is invalid, the engine is left
+// unchanged. To keep the responses already learned, pass them along with the
+// new ones, as in append(p.History, responses...).
+func (p *Engine) Train(responses []Query, passes int) ([]float64, error) {
+	if passes < 0 {
+		return nil, fmt.Errorf("must have passes [%d] >= 0", passes)
+	}
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("must have at least one response")
+	}
+	for i, q := range responses {
+		if err := p.validate(q); err != nil {
+			return nil, fmt.Errorf("response %d: %v", i, err)
+		}
+	}
+
+	p.History = append([]Query(nil), responses...)
+	p.Refit()
+	losses := []float64{p.loss(p.History)}
+	p.resetMomentum()
+	for i := 0; i < passes; i++ {
+		p.update(p.History)
+		losses = append(losses, p.loss(p.History))
+	}
+	if passes > 0 {
+		p.notifyUpdate()
+	}
+	return losses, nil
+}
@@ -0,0 +1,46 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTrain(t *testing.T) {
+	rand.Seed(23)
+	var responses []Query
+	for i := 0; i < 40; i++ {
+		a, b := rand.Intn(4), rand.Intn(4)
+		if a == b {
+			continue
+		}
+		if a > b && rand.Float64() < 0.8 {
+			a, b = b, a
+		}
+		responses = append(responses, Query{User: i % 3, Choices: []int{a, b}})
+	}
+	eng := NewEngine(3, 4)
+	losses, err := eng.Train(responses, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(losses) != 21 || losses[20] >= losses[0] {
+		t.Fatalf("expected the passes to reduce the loss, got %v", losses)
+	}
+	if len(eng.History) != len(responses) {
+		t.Fatalf("expected the History to be replaced")
+	}
+
+	online := NewEngine(3, 4)
+	for _, q := range responses {
+		online.Respond(q)
+	}
+	if loss, _ := online.LossOn(responses); loss <= losses[20] {
+		t.Fatalf("expected training to fit better than online learning, "+
+			"%v vs %v", losses[20], loss)
+	}
+
+	if _, err := eng.Train([]Query{{User: 5, Choices: []int{0, 1}}},
+		1); err == nil || len(eng.History) != len(responses) {
+		t.Fatalf("expected an invalid response to leave the engine alone")
+	}
+}
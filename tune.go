@@ -0,0 +1,148 @@
+package collaborativepermute
+
+// Struct Params holds the engine's tunable hyperparameters.
+type Params struct {
+	Nu, Lambda, T, Margin float64
+}
+
+// Method Params returns the engine's current hyperparameters.
+func (p *Engine) Params() Params {
+	return Params{Nu: p.Nu, Lambda: p.Lambda, T: p.T, Margin: p.Margin}
+}
+
+// Struct ParamGrid lists the candidate values for each hyperparameter that
+// Tune should try; an empty list means only the default. Tune evaluates every
+// combination with Folds-fold cross-validation (5 if zero).
+type ParamGrid struct {
+	Nu, Lambda, Margin []float64
+	Folds              int
+}
+
+// Struct BestParams reports the winning hyperparameters from Tune, along with
+// their mean cross-validated pairwise accuracy.
+type BestParams struct {
+	Params
+	Accuracy float64
+}
+
+// Function Tune refits an engine on recorded responses for every combination
+// of hyperparameters in the grid, and returns whichever best predicts the
+// held-out responses under cross-validation. The engine size is inferred from
+// the largest user and choice in the history. Ties go to the combination
+// listed first, and invalid combinations are skipped.
+func Tune(history []Query, grid ParamGrid) BestParams {
+	defaults := NewEngine(0, 0).Params()
+	users, choices := 0, 0
+	for _, q := range history {
+		if q.User >= users {
+			users = q.User + 1
+		}
+		for _, c := range q.Choices {
+			if c >= choices {
+				choices = c + 1
+			}
+		}
+	}
+	folds := grid.Folds
+	if folds == 0 {
+		folds = 5
+	}
+	if folds > len(history) {
+		folds = len(history)
+	}
+
+	best := BestParams{Params: defaults, Accuracy: -1}
+	for _, nu := range orDefault(grid.Nu, defaults.Nu) {
+		for _, lambda := range orDefault(grid.Lambda, defaults.Lambda) {
+			for _, margin := range orDefault(grid.Margin, defaults.Margin) {
+				params := defaults
+				params.Nu, params.Lambda, params.Margin = nu, lambda, margin
+
+				sum, fitted := 0.0, 0
+				for fold := 0; fold < folds; fold++ {
+					train, test := make([]Query, 0), make([]Query, 0)
+					for i, q := range history {
+						if i%folds == fold {
+							test = append(test, q)
+						} else {
+							train = append(train, q)
+						}
+					}
+					eng, err := NewEngineSafe(users, choices, WithNu(nu),
+						WithLambda(lambda), WithMargin(margin))
+					if err != nil {
+						break
+					}
+					eng.History = train
+					eng.Refit()
+					sum += eng.accuracy(test)
+					fitted++
+				}
+				if fitted < folds {
+					continue
+				}
+				accuracy := 0.0
+				if folds > 0 {
+					accuracy = sum / float64(folds)
+				}
+				if accuracy > best.Accuracy {
+					best = BestParams{Params: params, Accuracy: accuracy}
+				}
+			}
+		}
+	}
+	return best
+}
+
+func orDefault(values []float64, def float64) []float64 {
+	if len(values) == 0 {
+		return []float64{def}
+	}
+	return values
+}
+
+// Method SetParams validates and adopts new hyperparameters. Because the
+// accelerated scheme's momentum was built up under the old values, it is
+// reset, with the current beliefs kept as the starting point. If the change
+// affects what the engine learns from its History (anything but T), the
+// engine is also refit so that it reflects the new values throughout; use
+// AdoptParams to skip the refit.
+func (p *Engine) SetParams(params Params) error {
+	return p.setParams(params, true)
+}
+
+// Method AdoptParams is like SetParams, but never refits: the new values
+// apply only to later updates, which start from the current beliefs. It
+// suits frequent adjustments to an engine whose History is too long to
+// replay each time.
+func (p *Engine) AdoptParams(params Params) error {
+	return p.setParams(params, false)
+}
+
+func (p *Engine) setParams(params Params, refit bool) error {
+	c := *p
+	c.Nu, c.Lambda, c.T, c.Margin =
+		params.Nu, params.Lambda, params.T, params.Margin
+	if err := c.validateParams(); err != nil {
+		return err
+	}
+
+	old := p.Params()
+	p.Nu, p.Lambda, p.T, p.Margin =
+		params.Nu, params.Lambda, params.T, params.Margin
+
+	old.T = params.T
+	if refit && old != params && len(p.History) > 0 {
+		p.Refit()
+		return nil
+	}
+	p.resetMomentum()
+	return nil
+}
+
+// resetMomentum restarts the accelerated scheme from the current beliefs.
+func (p *Engine) resetMomentum() {
+	p.Xp = assign(p.Xp, p.X)
+	p.Z = assign(p.Z, p.X)
+	p.Alpha = 1
+}
@@ -0,0 +1,89 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTune(t *testing.T) {
+	rand.Seed(23)
+	history := make([]Query, 0)
+	for i := 0; i < 40; i++ {
+		a, b := rand.Intn(4), rand.Intn(4)
+		if a == b {
+			continue
+		}
+		if a < b {
+			a, b = b, a
+		}
+		history = append(history, Query{User: i % 3, Choices: []int{a, b}})
+	}
+
+	best := Tune(history, ParamGrid{Lambda: []float64{100, -1, 0.04}, Folds: 4})
+	if best.Lambda != 0.04 {
+		t.Fatalf("expected the weaker regularization to win, got %v",
+			best.Lambda)
+	}
+	if best.Nu != 1 || best.Margin != 1 {
+		t.Fatalf("expected defaults for untuned parameters, got %+v", best)
+	}
+	if best.Accuracy <= 0.5 {
+		t.Fatalf("expected better than chance accuracy, got %v", best.Accuracy)
+	}
+}
+
+func TestSetParams(t *testing.T) {
+	rand.Seed(23)
+	eng := NewEngine(2, 3)
+	ref := NewEngine(2, 3, WithLambda(0.2))
+	for i := 0; i < 6; i++ {
+		q := Query{User: i % 2, Choices: []int{i % 3, (i + 1) % 3}}
+		eng.Respond(q)
+		ref.Respond(q)
+	}
+
+	params := eng.Params()
+	params.Lambda = 0.2
+	if err := eng.SetParams(params); err != nil {
+		t.Fatal(err)
+	}
+	for i := range ref.X.Data {
+		if eng.X.Data[i] != ref.X.Data[i] {
+			t.Fatalf("expected SetParams to refit under the new Lambda")
+		}
+	}
+
+	params.T = 5
+	if err := eng.SetParams(params); err != nil {
+		t.Fatal(err)
+	}
+	if eng.Alpha != 1 || eng.Z.Data[0] != eng.X.Data[0] {
+		t.Fatalf("expected momentum to be reset")
+	}
+
+	params.Nu = -1
+	if err := eng.SetParams(params); err == nil {
+		t.Fatalf("expected an error for an invalid Nu")
+	}
+	if eng.Nu != 1 {
+		t.Fatalf("expected invalid parameters not to be adopted")
+	}
+	if err := eng.AdoptParams(params); err == nil {
+		t.Fatalf("expected an error for an invalid Nu")
+	}
+
+	before := clone(eng.X)
+	params = eng.Params()
+	params.Lambda = 0.1
+	if err := eng.AdoptParams(params); err != nil {
+		t.Fatal(err)
+	}
+	for i := range before.Data {
+		if eng.X.Data[i] != before.Data[i] {
+			t.Fatalf("expected AdoptParams to keep the current beliefs")
+		}
+	}
+	if eng.Lambda != 0.1 || eng.Alpha != 1 {
+		t.Fatalf("expected AdoptParams to adopt Lambda and reset momentum")
+	}
+}
@@ -0,0 +1,183 @@
+package collaborativepermute
+
+import (
+	"fmt"
+)
+
+// Struct TypedEngine wraps an Engine so that users and choices are referred to
+// by caller-supplied keys rather than by matrix index.
+type TypedEngine[U, I comparable] struct {
+	Engine *Engine
+
+	users       []U
+	choices     []I
+	userIndex   map[U]int
+	choiceIndex map[I]int
+}
+
+// Struct TypedQuery is a Query whose user and choices are keys.
+type TypedQuery[U, I comparable] struct {
+	User    U
+	Choices []I
+}
+
+// NewTypedEngine allocates an empty learning engine. Users and choices are
+// registered with AddUser and AddItem.
+func NewTypedEngine[U, I comparable]() *TypedEngine[U, I] {
+	return &TypedEngine[U, I]{
+		Engine:      NewEngine(0, 0),
+		userIndex:   make(map[U]int),
+		choiceIndex: make(map[I]int),
+	}
+}
+
+// Method AddUser registers a new user key.
+func (n *TypedEngine[U, I]) AddUser(id U) error {
+	if _, ok := n.userIndex[id]; ok {
+		return fmt.Errorf("duplicate user %v", id)
+	}
+	n.userIndex[id] = n.Engine.AddUser()
+	n.users = append(n.users, id)
+	return nil
+}
+
+// Method AddItem registers a new choice key.
+func (n *TypedEngine[U, I]) AddItem(id I) error {
+	if _, ok := n.choiceIndex[id]; ok {
+		return fmt.Errorf("duplicate choice %v", id)
+	}
+	n.choiceIndex[id] = n.Engine.AddItem()
+	n.choices = append(n.choices, id)
+	return nil
+}
+
+// Method RemoveUser deletes a user and all of their responses.
+func (n *TypedEngine[U, I]) RemoveUser(id U) error {
+	u, err := n.UserIndex(id)
+	if err != nil {
+		return err
+	}
+	if err := n.Engine.RemoveUser(u); err != nil {
+		return err
+	}
+	n.users = append(n.users[:u], n.users[u+1:]...)
+	n.userIndex = reindex(n.users)
+	return nil
+}
+
+// Method RemoveItem deletes a choice and every response that mentions it.
+func (n *TypedEngine[U, I]) RemoveItem(id I) error {
+	c, err := n.ChoiceIndex(id)
+	if err != nil {
+		return err
+	}
+	if err := n.Engine.RemoveItem(c); err != nil {
+		return err
+	}
+	n.choices = append(n.choices[:c], n.choices[c+1:]...)
+	n.choiceIndex = reindex(n.choices)
+	return nil
+}
+
+// Method UserIndex returns the engine index of the given user.
+func (n *TypedEngine[U, I]) UserIndex(id U) (int, error) {
+	u, ok := n.userIndex[id]
+	if !ok {
+		return 0, fmt.Errorf("unknown user %v", id)
+	}
+	return u, nil
+}
+
+// Method ChoiceIndex returns the engine index of the given choice.
+func (n *TypedEngine[U, I]) ChoiceIndex(id I) (int, error) {
+	c, ok := n.choiceIndex[id]
+	if !ok {
+		return 0, fmt.Errorf("unknown choice %v", id)
+	}
+	return c, nil
+}
+
+// Method Users returns the user keys in index order.
+func (n *TypedEngine[U, I]) Users() []U {
+	return append([]U(nil), n.users...)
+}
+
+// Method Choices returns the choice keys in index order.
+func (n *TypedEngine[U, I]) Choices() []I {
+	return append([]I(nil), n.choices...)
+}
+
+// Method Generate creates a new TypedQuery for whichever user would be the most
+// helpful to ask, or returns ErrNoQuestion if there is nothing left to ask.
+func (n *TypedEngine[U, I]) Generate() (TypedQuery[U, I], error) {
+	q, err := n.Engine.GenerateSafe(-1)
+	if err != nil {
+		return TypedQuery[U, I]{}, err
+	}
+	return n.name(q), nil
+}
+
+// Method GenerateFor creates a new TypedQuery for the given user.
+func (n *TypedEngine[U, I]) GenerateFor(user U) (TypedQuery[U, I], error) {
+	u, err := n.UserIndex(user)
+	if err != nil {
+		return TypedQuery[U, I]{}, err
+	}
+	q, err := n.Engine.GenerateSafe(u)
+	if err != nil {
+		return TypedQuery[U, I]{}, err
+	}
+	return n.name(q), nil
+}
+
+// Method Respond takes a completed TypedQuery and updates the engine.
+func (n *TypedEngine[U, I]) Respond(prompt TypedQuery[U, I]) error {
+	u, err := n.UserIndex(prompt.User)
+	if err != nil {
+		return err
+	}
+	choices := make([]int, len(prompt.Choices))
+	for i, id := range prompt.Choices {
+		if choices[i], err = n.ChoiceIndex(id); err != nil {
+			return err
+		}
+	}
+	return n.Engine.Respond(Query{User: u, Choices: choices})
+}
+
+// Method Rank returns the choice keys ordered from most to least preferred by
+// the given user.
+func (n *TypedEngine[U, I]) Rank(user U) ([]I, error) {
+	u, err := n.UserIndex(user)
+	if err != nil {
+		return nil, err
+	}
+	order, err := n.Engine.Rank(u)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]I, len(order))
+	for i, c := range order {
+		result[i] = n.choices[c]
+	}
+	return result, nil
+}
+
+func (n *TypedEngine[U, I]) name(q Query) TypedQuery[U, I] {
+	result := TypedQuery[U, I]{
+		User:    n.users[q.User],
+		Choices: make([]I, len(q.Choices)),
+	}
+	for i, c := range q.Choices {
+		result.Choices[i] = n.choices[c]
+	}
+	return result
+}
+
+func reindex[K comparable](ids []K) map[K]int {
+	index := make(map[K]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	return index
+}
@@ -0,0 +1,58 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+type movie struct {
+	Title string
+	Year  int
+}
+
+func TestTypedEngine(t *testing.T) {
+	rand.Seed(23)
+	eng := NewTypedEngine[int64, movie]()
+	brazil, metropolis := movie{"Brazil", 1985}, movie{"Metropolis", 1927}
+	eng.AddUser(1001)
+	eng.AddItem(brazil)
+	eng.AddItem(metropolis)
+
+	for i := 0; i < 5; i++ {
+		q, err := eng.GenerateFor(1001)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Choices = []movie{metropolis, brazil}
+		if err := eng.Respond(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ranking, err := eng.Rank(1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranking[0] != metropolis {
+		t.Fatalf("expected Metropolis first, got %v", ranking)
+	}
+	if err := eng.Respond(TypedQuery[int64, movie]{
+		User:    1001,
+		Choices: []movie{brazil, {"Alphaville", 1965}},
+	}); err == nil {
+		t.Fatalf("expected an error for an unknown choice")
+	}
+}
+
+func TestTypedEngineOneItem(t *testing.T) {
+	eng := NewTypedEngine[int64, movie]()
+	eng.AddUser(1001)
+	eng.AddItem(movie{"Brazil", 1985})
+
+	if _, err := eng.GenerateFor(1001); err != ErrNoQuestion {
+		t.Fatalf("expected ErrNoQuestion for the user, got %v", err)
+	}
+	if _, err := eng.Generate(); err != ErrNoQuestion {
+		t.Fatalf("expected ErrNoQuestion, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Struct VariationalEngine maintains an approximate posterior over the
+// preference matrix, rather than a point estimate. Scores are modeled as a
+// low-rank product U·V' of user and choice factors, each with an independent
+// (mean-field) Gaussian posterior, and a user prefers a over b with
+// probability Φ((score(a) - score(b)) / Beta). Each response is incorporated
+// by assumed-density filtering, linearizing the product around the current
+// means.
+//
+// The posterior supports credible intervals on every score, and selecting
+// queries by their expected information gain.
+type VariationalEngine struct {
+	UMean, UVar, VMean, VVar [][]float64
+	Beta, T                  float64
+	History                  []Query
+}
+
+// NewVariationalEngine allocates a variational learner for the given corpus
+// size, with factors of the given rank and standard normal priors.
+func NewVariationalEngine(users, choices, rank int) (*VariationalEngine, error) {
+	if users < 0 || choices < 0 || rank < 1 {
+		return nil, fmt.Errorf("must have users [%d] >= 0, choices [%d] >= 0 "+
+			"and rank [%d] >= 1", users, choices, rank)
+	}
+
+	// The means start out small and random, since a product of factors with
+	// zero means carries no information in either direction.
+	init := rand.New(rand.NewSource(1))
+	factors := func(n int) (mean, variance [][]float64) {
+		mean, variance = make([][]float64, n), make([][]float64, n)
+		for i := range mean {
+			mean[i], variance[i] = make([]float64, rank), make([]float64, rank)
+			for k := range mean[i] {
+				mean[i][k] = 0.1 * init.NormFloat64()
+				variance[i][k] = 1
+			}
+		}
+		return mean, variance
+	}
+	p := &VariationalEngine{Beta: 1, T: 1, History: make([]Query, 0)}
+	p.UMean, p.UVar = factors(users)
+	p.VMean, p.VVar = factors(choices)
+	return p, nil
+}
+
+var _ Learner = (*VariationalEngine)(nil)
+
+// Method Score returns the posterior mean of the user's score for the choice.
+func (p *VariationalEngine) Score(user, choice int) float64 {
+	sum := 0.0
+	for k, m := range p.UMean[user] {
+		sum += m * p.VMean[choice][k]
+	}
+	return sum
+}
+
+// Method ScoreVariance returns the posterior variance of the user's score for
+// the choice.
+func (p *VariationalEngine) ScoreVariance(user, choice int) float64 {
+	sum := 0.0
+	for k, m := range p.UMean[user] {
+		s, n, t := p.UVar[user][k], p.VMean[choice][k], p.VVar[choice][k]
+		sum += s*n*n + m*m*t + s*t
+	}
+	return sum
+}
+
+// Method Interval returns a credible interval of z posterior standard
+// deviations around the user's mean score for the choice.
+func (p *VariationalEngine) Interval(user, choice int, z float64) (lo, hi float64) {
+	mean, sd := p.Score(user, choice), math.Sqrt(p.ScoreVariance(user, choice))
+	return mean - z*sd, mean + z*sd
+}
+
+// difference returns the posterior mean and variance of the difference
+// between the user's scores for choices a and b.
+func (p *VariationalEngine) difference(user, a, b int) (mean, variance float64) {
+	for k, m := range p.UMean[user] {
+		s := p.UVar[user][k]
+		dn := p.VMean[a][k] - p.VMean[b][k]
+		mean += m * dn
+		variance += s*dn*dn + (m*m+s)*(p.VVar[a][k]+p.VVar[b][k])
+	}
+	return mean, variance
+}
+
+// Method Probability returns the posterior predictive probability that the
+// user prefers choice a over choice b.
+func (p *VariationalEngine) Probability(user, a, b int) float64 {
+	mean, variance := p.difference(user, a, b)
+	return normalCDF(mean / math.Sqrt(p.Beta*p.Beta+variance))
+}
+
+// Method InformationGain returns the expected reduction in entropy of the
+// posterior, in bits, from asking the user to compare choices a and b. This
+// is the BALD criterion of (Houlsby et al. '11) for a probit likelihood: the
+// entropy of the predicted answer, less the entropy the answer would still
+// have if the scores were known.
+func (p *VariationalEngine) InformationGain(user, a, b int) float64 {
+	mean, variance := p.difference(user, a, b)
+	mean, variance = mean/p.Beta, variance/(p.Beta*p.Beta)
+	c2 := math.Pi * math.Ln2 / 2
+	remaining := math.Sqrt(c2/(variance+c2)) *
+		math.Exp(-mean*mean/(2*(variance+c2)))
+	return math.Max(0, binaryEntropy(normalCDF(mean/math.Sqrt(1+variance)))-
+		remaining)
+}
+
+// Method Respond takes a completed Query and updates the posterior over the
+// user's factors and both choices' factors.
+func (p *VariationalEngine) Respond(prompt Query) error {
+	if len(prompt.Choices) != 2 {
+		return fmt.Errorf("can only handle binary rankings")
+	}
+	if prompt.User < 0 || prompt.User >= len(p.UMean) {
+		return fmt.Errorf("must have 0 <= user [%d] < %d",
+			prompt.User, len(p.UMean))
+	}
+	for _, choice := range prompt.Choices {
+		if choice < 0 || choice >= len(p.VMean) {
+			return fmt.Errorf("must have 0 <= choice [%d] < %d",
+				choice, len(p.VMean))
+		}
+	}
+	if prompt.Choices[0] == prompt.Choices[1] {
+		return fmt.Errorf("choice %d is ranked twice", prompt.Choices[0])
+	}
+
+	u, a, b := prompt.User, prompt.Choices[0], prompt.Choices[1]
+	mean, variance := p.difference(u, a, b)
+	c := math.Sqrt(p.Beta*p.Beta + variance)
+	t := mean / c
+	v := millsRatio(t)
+	w := v * (v + t)
+
+	m, s := p.UMean[u], p.UVar[u]
+	na, ta, nb, tb := p.VMean[a], p.VVar[a], p.VMean[b], p.VVar[b]
+	for k := range m {
+		dn, mk := na[k]-nb[k], m[k]
+		m[k] += s[k] * dn * v / c
+		s[k] *= 1 - s[k]*dn*dn*w/(c*c)
+		na[k] += ta[k] * mk * v / c
+		ta[k] *= 1 - ta[k]*mk*mk*w/(c*c)
+		nb[k] -= tb[k] * mk * v / c
+		tb[k] *= 1 - tb[k]*mk*mk*w/(c*c)
+	}
+
+	p.History = append(p.History, prompt)
+	return nil
+}
+
+// Method Generate creates a new Query to display to the user, sampling pairs
+// in proportion to their InformationGain raised to the power 1/T. If user is
+// non-negative, only return queries for that user. Generate panics if there
+// is no question to ask; GenerateSafe returns an error instead.
+func (p *VariationalEngine) Generate(user int) Query {
+	q, err := p.GenerateSafe(user)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// Method GenerateSafe is like Generate, but returns an error if user is out
+// of range, or ErrNoQuestion if there is no question to ask.
+func (p *VariationalEngine) GenerateSafe(user int) (Query, error) {
+	if user >= len(p.UMean) {
+		return Query{}, fmt.Errorf("must have user [%d] < %d",
+			user, len(p.UMean))
+	}
+	q, ok := sampleQuery(len(p.UMean), len(p.VMean), user, rand.Float64,
+		func(u, a, b int) float64 {
+			return math.Pow(p.InformationGain(u, a, b), 1/p.T)
+		}, p.Score)
+	if !ok {
+		return Query{}, ErrNoQuestion
+	}
+	return q, nil
+}
+
+// Method Rank returns the choices ordered from highest to lowest posterior
+// mean score for the given user.
+func (p *VariationalEngine) Rank(user int) ([]int, error) {
+	if user < 0 || user >= len(p.UMean) {
+		return nil, fmt.Errorf("must have 0 <= user [%d] < %d",
+			user, len(p.UMean))
+	}
+	return rankBy(len(p.VMean), func(choice int) float64 {
+		return p.Score(user, choice)
+	}), nil
+}
+
+func binaryEntropy(p float64) float64 {
+	if p <= 0 || p >= 1 {
+		return 0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
@@ -0,0 +1,53 @@
+package collaborativepermute
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestVariationalConvergence(t *testing.T) {
+	rand.Seed(23)
+	eng, err := NewVariationalEngine(10, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	incorrect := 0
+
+	for i := 0; i < 300; i++ {
+		q := eng.Generate(-1)
+		if q.Choices[0] >= q.Choices[1] {
+			q.Choices[0], q.Choices[1] = q.Choices[1], q.Choices[0]
+			incorrect += 1
+		}
+		eng.Respond(q)
+	}
+
+	if incorrect > 40 {
+		t.Fatalf("needed %v mistakes for a 10x10 matrix", incorrect)
+	}
+}
+
+func TestVariationalPosterior(t *testing.T) {
+	eng, _ := NewVariationalEngine(2, 3, 2)
+	gain := eng.InformationGain(0, 0, 1)
+	width := eng.UVar[0][0] + eng.UVar[0][1]
+	for i := 0; i < 10; i++ {
+		eng.Respond(Query{User: 0, Choices: []int{0, 1}})
+	}
+
+	if eng.Probability(0, 0, 1) <= 0.5 {
+		t.Fatalf("expected choice 0 to be favored")
+	}
+	if eng.InformationGain(0, 0, 1) >= gain {
+		t.Fatalf("expected a settled pair to be less informative")
+	}
+	if eng.UVar[0][0]+eng.UVar[0][1] >= width {
+		t.Fatalf("expected the posterior to narrow")
+	}
+	if lo, hi := eng.Interval(0, 0, 2); lo >= hi {
+		t.Fatalf("expected a non-empty interval, got [%v, %v]", lo, hi)
+	}
+	if err := eng.Respond(Query{Choices: []int{2, 2}}); err == nil {
+		t.Fatalf("expected an error for a choice compared with itself")
+	}
+}
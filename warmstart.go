@@ -0,0 +1,95 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// NewEngineFrom allocates a learning engine of the given size whose beliefs
+// are warm-started from a previously trained engine. Users and choices that
+// exist in both engines keep their learned scores, features, and weights (by
+// index); new ones start out neutral. The configuration of old, from Nu and
+// Lambda to the Loss, Strategy, and Backend, is copied, but its History is
+// not: the transferred beliefs act as a starting point that new responses
+// refine. As with a fresh engine, callbacks such as OnUpdate, Logger, and
+// Audit are not copied, nor is the Convergence detector, and any privacy
+// budget starts unspent.
+func NewEngineFrom(old *Engine, users, choices int) (*Engine, error) {
+	if users < 0 {
+		return nil, fmt.Errorf("must have users [%d] >= 0", users)
+	}
+	if choices < 0 {
+		return nil, fmt.Errorf("must have choices [%d] >= 0", choices)
+	}
+	p := old.clone()
+	p.History = make([]Query, 0)
+	p.LossHistory = nil
+	p.Convergence, p.AuditErr = nil, nil
+	if old.Privacy != nil {
+		p.Privacy = &Privacy{Budget: old.Privacy.Budget}
+	}
+	p.Alpha = 1
+	p.updates, p.lastRank = 0, 0
+	p.lastUpdate, p.lastProx = 0, 0
+	p.newest = time.Time{}
+
+	p.X = resize(p.X, users, choices)
+	p.Xp, p.Z = clone(p.X), clone(p.X)
+	if p.A.Shape != nil {
+		p.A = resize(p.A, users, p.A.Shape[1])
+	}
+	if p.B.Shape != nil {
+		p.B = resize(p.B, p.B.Shape[0], choices)
+	}
+	if p.C.Shape != nil {
+		p.C = resize(p.C, p.C.Shape[0], choices)
+	}
+	if p.CategoryOffsets.Shape != nil {
+		p.CategoryOffsets = resize(p.CategoryOffsets, users,
+			p.CategoryOffsets.Shape[1])
+	}
+	if p.ItemFeatures != nil {
+		p.ItemFeatures = resizeSlice(p.ItemFeatures, choices, nil)
+	}
+	if p.UserFeatures != nil {
+		p.UserFeatures = resizeSlice(p.UserFeatures, users, nil)
+	}
+	if p.ItemCategories != nil {
+		p.ItemCategories = resizeSlice(p.ItemCategories, choices, -1)
+	}
+	if p.Bias != nil {
+		p.Bias = resizeSlice(p.Bias, choices, 0)
+	}
+	if p.Reliability != nil {
+		p.Reliability = resizeSlice(p.Reliability, users, 1)
+	}
+	if p.UserScales != nil {
+		p.UserOffsets = resizeSlice(p.UserOffsets, users, 0)
+		p.UserScales = resizeSlice(p.UserScales, users, 1)
+	}
+	if p.Quarantined != nil {
+		p.Quarantined = resizeSlice(p.Quarantined, users, false)
+	}
+	if p.Prior != nil {
+		p.Prior = resizeSlice(p.Prior, users, nil)
+		for u, row := range p.Prior {
+			if row == nil {
+				row = unknownRow(choices)
+			}
+			p.Prior[u] = resizeSlice(row, choices, math.NaN())
+		}
+	}
+	return p, nil
+}
+
+// resizeSlice truncates s to n elements, or pads it to n with fill.
+func resizeSlice[T any](s []T, n int, fill T) []T {
+	if len(s) >= n {
+		return s[:n:n]
+	}
+	for len(s) < n {
+		s = append(s, fill)
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package collaborativepermute
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestNewEngineFrom(t *testing.T) {
+	rand.Seed(23)
+	old := NewEngine(2, 3, WithCapacity(4, 4), WithLoss(Logistic),
+		WithStrategy(Uniform), WithItemBias())
+	old.Margin = 2
+	for i := 0; i < 10; i++ {
+		old.Respond(Query{User: 1, Choices: []int{2, 0}})
+	}
+	before := append([]float64(nil), old.X.Data...)
+
+	eng, err := NewEngineFrom(old, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng.X.Shape[0] != 3 || eng.X.Shape[1] != 4 || len(eng.Bias) != 4 {
+		t.Fatalf("expected a 3x4 engine, got %v", eng.X.Shape)
+	}
+	if eng.Margin != 2 || eng.Loss != Logistic ||
+		fmt.Sprint(eng.Strategy) != "uniform" {
+		t.Fatalf("expected the configuration to be copied")
+	}
+	if eng.Score(1, 2) != old.Score(1, 2) {
+		t.Fatalf("expected learned score to be transferred")
+	}
+	if eng.Score(2, 3) != 0 {
+		t.Fatalf("expected new user and choice to start out neutral")
+	}
+	if len(eng.History) != 0 {
+		t.Fatalf("expected no history to be transferred")
+	}
+	for i, v := range before {
+		if old.X.Data[i] != v {
+			t.Fatalf("warm start modified the old engine")
+		}
+	}
+
+	eng.Respond(Query{User: 2, Choices: []int{3, 1}})
+	ranking, _ := eng.Rank(1)
+	if ranking[0] != 2 {
+		t.Fatalf("expected user 1 to still prefer choice 2, got %v", ranking)
+	}
+
+	if _, err := NewEngineFrom(old, -1, 2); err == nil {
+		t.Fatalf("expected an error for a negative size")
+	}
+}
@@ -0,0 +1,108 @@
+// Package webhook POSTs collaborativepermute ranking changes to an HTTP
+// endpoint, so that downstream caches and editors learn when a top-k ranking
+// moves:
+//
+//	h := webhook.New("https://example.com/rankings", nil)
+//	defer h.Close()
+//	eng.OnRankingChange(10, 2, h.Notify)
+//
+// Each change is sent as a JSON collaborativepermute.RankingChange:
+//
+//	{"user": 3, "before": [4, 1, 0], "after": [1, 4, 2],
+//	 "changed": 3, "responses": 120}
+//
+// where a user of -1 denotes the consensus ranking.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fatlotus/collaborativepermute"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// queueSize is the number of changes a Hook holds while waiting to send them.
+const queueSize = 64
+
+// Struct Hook sends ranking changes from a background goroutine, so that a
+// slow endpoint does not delay the engine's updates.
+type Hook struct {
+	// OnError, if set, is called from the background goroutine with every
+	// change that could not be delivered and why. Set it before the first
+	// call to Notify.
+	OnError func(collaborativepermute.RankingChange, error)
+
+	url    string
+	client *http.Client
+	queue  chan collaborativepermute.RankingChange
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New creates a Hook posting to url with client, or if client is nil, an
+// http.Client with a ten-second timeout.
+func New(url string, client *http.Client) *Hook {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	h := &Hook{
+		url:    url,
+		client: client,
+		queue:  make(chan collaborativepermute.RankingChange, queueSize),
+		done:   make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// Method Notify queues c to be sent. If queueSize changes are already
+// waiting, c is dropped and reported to OnError.
+func (h *Hook) Notify(c collaborativepermute.RankingChange) {
+	select {
+	case h.queue <- c:
+	default:
+		h.fail(c, fmt.Errorf("webhook queue is full"))
+	}
+}
+
+// Method Close sends the changes already queued, then stops the Hook. Notify
+// must not be called afterwards.
+func (h *Hook) Close() {
+	h.once.Do(func() { close(h.queue) })
+	<-h.done
+}
+
+func (h *Hook) run() {
+	defer close(h.done)
+	for c := range h.queue {
+		if err := h.send(c); err != nil {
+			h.fail(c, err)
+		}
+	}
+}
+
+func (h *Hook) send(c collaborativepermute.RankingChange) error {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Post(h.url, "application/json",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (h *Hook) fail(c collaborativepermute.RankingChange, err error) {
+	if h.OnError != nil {
+		h.OnError(c, err)
+	}
+}
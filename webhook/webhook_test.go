@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/fatlotus/collaborativepermute"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	rand.Seed(23)
+	received := make(chan collaborativepermute.RankingChange, 10)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var c collaborativepermute.RankingChange
+			if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+				t.Errorf("malformed body: %v", err)
+			}
+			received <- c
+		}))
+	defer srv.Close()
+
+	h := New(srv.URL, nil)
+	h.OnError = func(c collaborativepermute.RankingChange, err error) {
+		t.Errorf("failed to deliver %+v: %v", c, err)
+	}
+	eng := collaborativepermute.NewEngine(1, 3)
+	if err := eng.OnRankingChange(1, 0, h.Notify); err != nil {
+		t.Fatal(err)
+	}
+	eng.Respond(collaborativepermute.Query{Choices: []int{2, 0}})
+	h.Close()
+
+	close(received)
+	var changes []collaborativepermute.RankingChange
+	for c := range received {
+		changes = append(changes, c)
+	}
+	if len(changes) != 2 || changes[0].After[0] != 2 ||
+		changes[1].User != collaborativepermute.Consensus {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+}
+
+func TestHookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+	defer srv.Close()
+
+	h := New(srv.URL, nil)
+	failed := 0
+	h.OnError = func(collaborativepermute.RankingChange, error) { failed++ }
+	h.Notify(collaborativepermute.RankingChange{})
+	h.Close()
+	if failed != 1 {
+		t.Fatalf("expected the failed delivery to be reported")
+	}
+}